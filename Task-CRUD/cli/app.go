@@ -0,0 +1,304 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"Task-CRUD/config"
+	"Task-CRUD/internal/cache"
+	"Task-CRUD/internal/cbreaker"
+	"Task-CRUD/internal/entity"
+	"Task-CRUD/internal/events"
+	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/repository/changefeed"
+	repoRepo "Task-CRUD/internal/repository/repo"
+	userRepo "Task-CRUD/internal/repository/user"
+	"Task-CRUD/internal/schemaregistry"
+	"Task-CRUD/internal/usecase"
+	appKafka "Task-CRUD/kafka"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+)
+
+// app menyimpan dependency yang dipakai bersama oleh command CLI.
+// Setiap dependency diinisialisasi hanya jika command membutuhkannya.
+type app struct {
+	cfg        *config.Config
+	gormDB     *gorm.DB
+	sqlDB      *sql.DB
+	redis      redis.UniversalClient
+	caches     *usecase.Caches
+	stopCaches func()
+	kafka      *kafka.Writer
+}
+
+func newApp() *app {
+	cfg := config.LoadConfig()
+	applyEventConfig(cfg)
+	return &app{cfg: cfg}
+}
+
+// applyEventConfig mengisi atribut source, routing topic dan encoding event dari konfigurasi
+func applyEventConfig(cfg *config.Config) {
+	events.Source = cfg.EventSource
+
+	topics := make(map[string]string, len(cfg.KafkaTopics)+1)
+	if cfg.KafkaTopicMode == "aggregate" && cfg.KafkaTopic != "" {
+		// KAFKA_TOPIC (opsional) mengganti topic aggregate repository; default "repository-topic"
+		topics["repository"] = cfg.KafkaTopic
+	}
+	for k, v := range cfg.KafkaTopics {
+		topics[k] = v
+	}
+	events.Routes = events.Routing{
+		PerAggregate:    cfg.KafkaTopicMode == "aggregate",
+		Topics:          topics,
+		Partitions:      cfg.KafkaPartitions,
+		TopicPartitions: cfg.KafkaTopicPartitions,
+	}
+
+	// Registry juga dipakai untuk men-decode pesan Avro meskipun semua topic memakai JSON
+	var registry schemaregistry.Registry
+	if cfg.SchemaRegistryURL != "" {
+		registry = schemaregistry.NewClient(schemaregistry.ClientConfig{
+			URL:      cfg.SchemaRegistryURL,
+			Username: cfg.SchemaRegistryUsername,
+			Password: cfg.SchemaRegistryPassword,
+			Timeout:  cfg.SchemaRegistryTimeout,
+		})
+	}
+	appKafka.Encodings = appKafka.NewCodec(cfg.KafkaEncoding, cfg.KafkaTopicEncodings, registry)
+}
+
+// ensureTopics membuat topic yang belum ada dengan partisi, replikasi dan
+// retensi dari konfigurasi
+func ensureTopics(ctx context.Context, cfg *config.Config, topics ...string) error {
+	sec, err := kafkaSecurity(cfg)
+	if err != nil {
+		return err
+	}
+	client, err := sec.Client([]string{cfg.KafkaBroker})
+	if err != nil {
+		return err
+	}
+
+	specs := make([]appKafka.TopicSpec, len(topics))
+	for i, t := range topics {
+		specs[i] = appKafka.TopicSpec{
+			Name:              t,
+			Partitions:        events.Routes.PartitionsFor(t),
+			ReplicationFactor: cfg.KafkaReplication,
+			Retention:         cfg.KafkaRetention,
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	return appKafka.EnsureTopics(ctx, client, specs...)
+}
+
+// initDB membuka koneksi PostgreSQL (GORM + *sql.DB). Aman dipanggil lebih dari sekali.
+func (a *app) initDB() error {
+	if a.gormDB != nil {
+		return nil
+	}
+	gormDB, err := config.InitPostgres(a.cfg)
+	if err != nil {
+		return fmt.Errorf("gagal inisialisasi PostgreSQL: %w", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return fmt.Errorf("gagal mengambil koneksi *sql.DB dari GORM: %w", err)
+	}
+	a.gormDB = gormDB
+	a.sqlDB = sqlDB
+	return nil
+}
+
+// initRedis menghubungkan Redis lalu menyiapkan cache usecase sesuai CACHE_BACKEND.
+// Jika required false, kegagalan hanya dicatat dan usecase berjalan tanpa cache.
+func (a *app) initRedis(required bool) error {
+	if a.cfg.CacheBackend == "redis" || required {
+		if err := config.InitRedis(a.cfg); err != nil {
+			if required {
+				return fmt.Errorf("gagal menginisialisasi Redis: %w", err)
+			}
+			log.Printf("⚠️ Redis tidak tersedia, cache dilewati: %v", err)
+		} else {
+			a.redis = config.RedisClient
+		}
+	}
+	a.caches, a.stopCaches = newCaches(a.cfg, a.redis)
+	return nil
+}
+
+// newCaches memilih backend cache sesuai CACHE_BACKEND dan menerapkan TTL per namespace.
+// Dengan Redis, cache L1 in-process diaktifkan dan invalidasi disebarkan lewat pub/sub;
+// stop menghentikan subscriber tersebut.
+func newCaches(cfg *config.Config, rdb redis.UniversalClient) (caches *usecase.Caches, stop func()) {
+	stop = func() {}
+
+	cacheCfg := cache.Config{
+		Prefix:  cfg.CacheKeyPrefix,
+		Version: cfg.CacheVersion,
+		TTLs: map[string]time.Duration{
+			usecase.NamespaceRepositories: cfg.CacheTTLRepositories,
+			usecase.NamespaceRepository:   cfg.CacheTTLRepository,
+			usecase.NamespaceUsers:        cfg.CacheTTLUsers,
+			usecase.NamespaceUser:         cfg.CacheTTLUser,
+		},
+		StaleTTL:          cfg.CacheStaleTTL,
+		EarlyRefreshBeta:  cfg.CacheEarlyBeta,
+		LockTTL:           cfg.CacheLockTTL,
+		NegativeTTL:       cfg.CacheNegativeTTL,
+		Codec:             cfg.CacheCodec,
+		CompressThreshold: cfg.CacheCompressMin,
+	}
+
+	var store cache.Store
+	var deps cache.DependencyIndex
+	switch cfg.CacheBackend {
+	case "memory":
+		store = cache.NewLRUStore(cfg.CacheMemorySize)
+		deps = cache.NewMemoryDependencyIndex()
+	case "redis":
+		if rdb != nil {
+			store = cache.NewRedisStore(rdb)
+			cacheCfg.Locker = cache.NewRedisLocker(rdb)
+
+			if cfg.CacheL1Size > 0 && cfg.CacheL1TTL > 0 {
+				bus := cache.NewRedisBus(rdb, cfg.CacheKeyPrefix+":invalidate")
+				bus.Start()
+				stop = func() { safeClose("Subscriber invalidasi cache", bus.Close) }
+				cacheCfg.L1Size = cfg.CacheL1Size
+				cacheCfg.L1TTL = cfg.CacheL1TTL
+				cacheCfg.Bus = bus
+			}
+			// Index hidup selama entry repository yang dicatatnya
+			deps = cache.NewRedisDependencyIndex(rdb, cacheCfg.KeyPrefix(usecase.NamespaceDeps), cacheCfg.TTL(usecase.NamespaceRepository)+cacheCfg.StaleTTL)
+		}
+	}
+
+	return usecase.NewCaches(store, deps, cacheCfg), stop
+}
+
+// initKafka membuat Kafka writer dengan setting yang sama seperti server
+func (a *app) initKafka() error {
+	writer, err := newKafkaWriter(a.cfg, a.cfg.KafkaAsync)
+	if err != nil {
+		return fmt.Errorf("gagal membuat Kafka writer: %w", err)
+	}
+	a.kafka = writer
+	return nil
+}
+
+// newKafkaWriter membuat writer sesuai KAFKA_*. async false dipakai penulis yang
+// harus tahu pesan sudah tersimpan (outbox relay, retry/DLQ consumer).
+func newKafkaWriter(cfg *config.Config, async bool) (*kafka.Writer, error) {
+	sec, err := kafkaSecurity(cfg)
+	if err != nil {
+		return nil, err
+	}
+	wc := appKafka.WriterConfig{
+		BatchSize:    cfg.KafkaBatchSize,
+		BatchTimeout: cfg.KafkaBatchTimeout,
+		RequiredAcks: cfg.KafkaRequiredAcks,
+		Compression:  cfg.KafkaCompression,
+		MaxAttempts:  cfg.KafkaMaxAttempts,
+		Async:        async,
+	}
+	if async {
+		wc.Completion = logKafkaCompletion
+	}
+	return appKafka.NewWriter([]string{cfg.KafkaBroker}, wc, sec)
+}
+
+// logKafkaCompletion mencatat hasil pengiriman writer async
+func logKafkaCompletion(messages []kafka.Message, err error) {
+	if err != nil {
+		log.Printf("❌ Kafka async: %d pesan gagal dikirim: %v", len(messages), err)
+	}
+}
+
+// kafkaSecurity menyusun SASL/TLS broker dari konfigurasi
+func kafkaSecurity(cfg *config.Config) (appKafka.Security, error) {
+	tlsConfig, err := config.KafkaTLSConfig(cfg)
+	if err != nil {
+		return appKafka.Security{}, err
+	}
+	sec := appKafka.Security{
+		Username: cfg.KafkaSASLUsername,
+		Password: cfg.KafkaSASLPassword,
+		TLS:      tlsConfig,
+	}
+	if cfg.KafkaSASLMechanism != "none" {
+		sec.Mechanism = cfg.KafkaSASLMechanism
+	}
+	return sec, nil
+}
+
+// initBreaker memastikan circuit breaker global sudah ada sebelum usecase dibuat
+func initBreaker() {
+	if cbreaker.Breaker == nil {
+		cbreaker.Breaker = cbreaker.NewDefaultBreaker("UserBreaker")
+	}
+}
+
+// userUseCase membangun usecase user dengan wiring yang sama seperti router
+func (a *app) userUseCase() interfaces.UserUseCaseInterface {
+	initBreaker()
+	return usecase.NewUserUseCaseWithEvents(userRepo.NewUserRepositoryPostgres(a.sqlDB), a.caches, usecase.NewEventPipeline(a.publisher()))
+}
+
+// repoUseCase membangun usecase repository dengan wiring yang sama seperti router
+func (a *app) repoUseCase() interfaces.RepoUseCaseInterface {
+	initBreaker()
+	return usecase.NewRepoUseCaseWithEvents(repoRepo.NewRepoRepositoryGorm(a.gormDB), a.caches, a.publisher())
+}
+
+// publisher mengembalikan EventPublisher Kafka, nil jika command tidak memakai Kafka
+func (a *app) publisher() interfaces.EventPublisher {
+	if a.kafka == nil {
+		return nil
+	}
+	return appKafka.NewPublisher(a.kafka)
+}
+
+func (a *app) close() {
+	if a.stopCaches != nil {
+		a.stopCaches()
+	}
+	if a.kafka != nil {
+		safeClose("Kafka writer", a.kafka.Close)
+	}
+	if a.redis != nil {
+		safeClose("Redis", config.CloseRedis)
+	}
+	if a.sqlDB != nil {
+		safeClose("PostgreSQL", config.ClosePostgres)
+	}
+}
+
+// migrate menjalankan AutoMigrate untuk semua entity (termasuk tabel outbox, dedup consumer, webhook
+// dan change feed), lalu memasang trigger change feed pada users dan repositories
+func migrate(gormDB *gorm.DB, cfg *config.Config) error {
+	err := gormDB.AutoMigrate(&entity.User{}, &entity.Repository{}, &entity.OutboxEvent{}, &entity.ProcessedEvent{},
+		&entity.Webhook{}, &entity.WebhookDelivery{}, &entity.DBChange{})
+	if err != nil {
+		return err
+	}
+	return changefeed.InstallTriggers(context.Background(), gormDB, cfg.DbApplicationName)
+}
+
+// Helper untuk menutup resource dengan log
+func safeClose(name string, closer func() error) {
+	if err := closer(); err != nil {
+		log.Printf("⚠️ Gagal menutup %s: %v", name, err)
+	} else {
+		log.Printf("✅ %s berhasil ditutup", name)
+	}
+}
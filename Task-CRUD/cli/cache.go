@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"Task-CRUD/config"
+	"Task-CRUD/internal/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// runCache menghapus key Redis yang cocok dengan pattern memakai SCAN
+func runCache(ctx context.Context, args []string) error {
+	_, rest, err := subcommand("cache", args, "flush")
+	if err != nil {
+		return err
+	}
+
+	a := newApp()
+	defer a.close()
+	if err := a.initRedis(true); err != nil {
+		return err
+	}
+
+	// Default: hanya key milik aplikasi ini (CACHE_KEY_PREFIX)
+	pattern := cachePattern(a.cfg)
+	if len(rest) > 0 {
+		pattern = rest[0]
+	}
+
+	deleted, err := flushCache(ctx, a.redis, pattern)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🧹 %d key dengan pattern %q dihapus\n", deleted, pattern)
+	return nil
+}
+
+// flushCache menghapus semua key yang cocok dengan pattern (SCAN per batch, bukan KEYS)
+func flushCache(ctx context.Context, rdb redis.UniversalClient, pattern string) (int, error) {
+	store := cache.NewRedisStore(rdb)
+	deleted := 0
+	err := store.Scan(ctx, pattern, func(keys []string) error {
+		if err := store.Delete(ctx, keys...); err != nil {
+			return fmt.Errorf("gagal menghapus key: %w", err)
+		}
+		deleted += len(keys)
+		return nil
+	})
+	if err != nil {
+		return deleted, fmt.Errorf("gagal flush cache: %w", err)
+	}
+	return deleted, nil
+}
+
+// cachePattern mengembalikan pattern semua key milik aplikasi ini (CACHE_KEY_PREFIX)
+func cachePattern(cfg *config.Config) string {
+	if cfg.CacheKeyPrefix == "" {
+		return "*"
+	}
+	return cfg.CacheKeyPrefix + ":*"
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// command adalah satu subcommand yang bisa dijalankan dari binary
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "serve", "Menjalankan HTTP server (default)", runServe},
+		{"migrate", "migrate", "Menjalankan AutoMigrate untuk semua entity", runMigrate},
+		{"seed", "seed [-users N] [-repos M]", "Mengisi database dengan data palsu yang deterministik", runSeed},
+		{"user", "user create|list|delete", "Mengelola data user", runUser},
+		{"repo", "repo import|export", "Import/export repository dalam format JSON", runRepo},
+		{"cache", "cache flush [pattern]", "Menghapus key cache di Redis (default: CACHE_KEY_PREFIX:*)", runCache},
+		{"events", "events replay", "Mengirim ulang event repository ke Kafka", runEvents},
+		{"worker", "worker", "Menjalankan consumer Kafka tanpa HTTP server", runWorker},
+		{"dlq", "dlq list|replay -topic T", "Melihat dan mengirim ulang pesan dead-letter Kafka", runDLQ},
+		{"config", "config print", "Menampilkan konfigurasi yang sedang aktif", runConfig},
+	}
+}
+
+// Run menjalankan subcommand sesuai argumen. Tanpa argumen, server dijalankan
+// seperti sebelumnya supaya `./main` di Dockerfile tetap berfungsi.
+// ctx dibatalkan saat SIGINT/SIGTERM; setiap command berhenti dengan rapi setelahnya.
+func Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return runServe(ctx, nil)
+	}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return nil
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(ctx, args[1:])
+		}
+	}
+
+	printUsage()
+	return fmt.Errorf("command tidak dikenal: %s", name)
+}
+
+func printUsage() {
+	var b strings.Builder
+	b.WriteString("Penggunaan: main <command> [argumen]\n\nCommand:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-28s %s\n", cmd.usage, cmd.summary)
+	}
+	fmt.Fprint(os.Stderr, b.String())
+}
+
+// subcommand memisahkan nama sub-subcommand (mis. "create" pada "user create")
+// dari argumen sisanya.
+func subcommand(group string, args []string, valid ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("%s membutuhkan subcommand: %s", group, strings.Join(valid, "|"))
+	}
+	for _, v := range valid {
+		if args[0] == v {
+			return v, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("subcommand %s tidak dikenal: %s (pilihan: %s)", group, args[0], strings.Join(valid, "|"))
+}
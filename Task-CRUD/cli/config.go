@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// runConfig menampilkan konfigurasi hasil config.LoadConfig dengan nilai rahasia disamarkan
+func runConfig(ctx context.Context, args []string) error {
+	if _, _, err := subcommand("config", args, "print"); err != nil {
+		return err
+	}
+
+	cfg := newApp().cfg
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		value := fmt.Sprint(v.Field(i).Interface())
+		if isSecretField(name) && value != "" {
+			value = "********"
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", name, value)
+	}
+	return tw.Flush()
+}
+
+func isSecretField(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.Contains(lower, "password") || strings.Contains(lower, "secret")
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"log"
+
+	"Task-CRUD/config"
+	appEvents "Task-CRUD/internal/events"
+	"Task-CRUD/internal/health"
+	"Task-CRUD/internal/usecase"
+	appKafka "Task-CRUD/kafka"
+)
+
+// superviseRedis menjalankan cache Redis dalam mode yang boleh degraded: selama
+// Redis mati, caches berisi noop (semua miss) dan Redis dicoba ulang dengan backoff.
+// stop menghentikan pemantauan beserta subscriber invalidasi cache.
+func superviseRedis(cfg *config.Config, caches *usecase.Caches, status *health.Registry) (stop func(), err error) {
+	client, err := config.NewRedisClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	config.RedisClient = client
+
+	// Semua callback dipanggil berurutan dari goroutine supervisor
+	stopBus := func() {}
+	degraded := false
+	sup := &health.Supervisor{
+		Name:     "redis",
+		Registry: status,
+		Check: func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		},
+		OnUp: func() error {
+			if degraded {
+				// Selama degraded, perubahan data tidak meng-invalidasi Redis:
+				// buang entry lama sebelum cache dipakai lagi
+				n, err := flushCache(context.Background(), client, cachePattern(cfg))
+				if err != nil {
+					return err
+				}
+				log.Printf("🧹 %d key cache lama dibuang setelah Redis kembali", n)
+				degraded = false
+			}
+			next, stop := newCaches(cfg, client)
+			caches.Swap(next)
+			stopBus = stop
+			return nil
+		},
+		OnDown: func(err error) {
+			degraded = true
+			caches.Swap(usecase.NoCaches())
+			stopBus()
+			stopBus = func() {}
+		},
+	}
+	sup.Start()
+
+	return func() {
+		sup.Stop()
+		stopBus()
+	}, nil
+}
+
+// superviseKafka menonaktifkan event selama broker Kafka tidak bisa dihubungi
+// dan mengaktifkannya lagi saat broker kembali. events memakai writer sesuai
+// KAFKA_ASYNC; relay selalu sinkron karena harus tahu event mana yang tersimpan.
+func superviseKafka(cfg *config.Config, events, relay *appKafka.Publisher, status *health.Registry) (stop func(), err error) {
+	writer, err := newKafkaWriter(cfg, cfg.KafkaAsync)
+	if err != nil {
+		return nil, err
+	}
+	relayWriter := writer
+	if cfg.KafkaAsync {
+		if relayWriter, err = newKafkaWriter(cfg, false); err != nil {
+			return nil, err
+		}
+	}
+	sec, err := kafkaSecurity(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dialer, err := sec.Dialer()
+	if err != nil {
+		return nil, err
+	}
+
+	sup := &health.Supervisor{
+		Name:     "kafka",
+		Registry: status,
+		Check: func(ctx context.Context) error {
+			conn, err := dialer.DialContext(ctx, "tcp", cfg.KafkaBroker)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			_, err = conn.Brokers()
+			return err
+		},
+		OnUp: func() error {
+			// Topic dibuat sebelum event dikirim supaya tidak bergantung pada auto-create broker
+			if err := ensureTopics(context.Background(), cfg, eventTopics()...); err != nil {
+				return err
+			}
+			events.Use(writer)
+			relay.Use(relayWriter)
+			return nil
+		},
+		OnDown: func(err error) {
+			events.Use(nil)
+			relay.Use(nil)
+		},
+	}
+	sup.Start()
+
+	return func() {
+		sup.Stop()
+		events.Use(nil)
+		relay.Use(nil)
+		safeClose("Kafka writer", writer.Close)
+		if relayWriter != writer {
+			safeClose("Kafka writer relay", relayWriter.Close)
+		}
+	}, nil
+}
+
+// eventTopics mengembalikan topic untuk semua event terdaftar sesuai routing aktif
+func eventTopics() []string {
+	return appEvents.Routes.AllTopics()
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	appKafka "Task-CRUD/kafka"
+)
+
+// runDLQ menangani `dlq list|replay` untuk topic dead-letter (<topic>.dlq).
+// -topic boleh berisi topic asal ("repository_created") atau nama DLQ-nya.
+func runDLQ(ctx context.Context, args []string) error {
+	sub, rest, err := subcommand("dlq", args, "list", "replay")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("dlq "+sub, flag.ContinueOnError)
+	topic := fs.String("topic", "", "topic asal atau topic DLQ (wajib)")
+	limit := fs.Int("limit", 50, "jumlah maksimum pesan yang ditampilkan (list)")
+	positions := fs.String("messages", "", "posisi pesan dipisah koma, mis. 0:12,1:3 (replay)")
+	all := fs.Bool("all", false, "kirim ulang semua pesan di DLQ (replay)")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	if *topic == "" {
+		return fmt.Errorf("-topic wajib diisi")
+	}
+	dlqTopic := *topic
+	if !strings.HasSuffix(dlqTopic, ".dlq") {
+		dlqTopic = appKafka.DeadLetterTopic(dlqTopic)
+	}
+
+	a := newApp()
+	defer a.close()
+	sec, err := kafkaSecurity(a.cfg)
+	if err != nil {
+		return err
+	}
+	dialer, err := sec.Dialer()
+	if err != nil {
+		return err
+	}
+
+	if sub == "list" {
+		dlq := appKafka.NewDeadLetters([]string{a.cfg.KafkaBroker}, dialer, nil)
+		letters, err := dlq.List(ctx, dlqTopic, *limit)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "POSITION\tEVENT_TYPE\tKEY\tATTEMPTS\tORIGIN\tFAILED_AT\tERROR")
+		for _, dl := range letters {
+			fmt.Fprintf(tw, "%d:%d\t%s\t%s\t%d\t%s/%d@%d\t%s\t%s\n",
+				dl.Partition, dl.Offset, dl.EventType, dl.Key, dl.Attempts,
+				dl.OriginalTopic, dl.OriginalPartition, dl.OriginalOffset,
+				dl.FailedAt.Format("2006-01-02 15:04:05"), dl.Error)
+		}
+		return tw.Flush()
+	}
+
+	var selected []appKafka.Position
+	for _, s := range splitNonEmpty(*positions) {
+		p, err := appKafka.ParsePosition(s)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, p)
+	}
+	if len(selected) == 0 && !*all {
+		return fmt.Errorf("pilih pesan dengan -messages atau kirim ulang semuanya dengan -all")
+	}
+
+	// Replay harus tahu pesan sudah tersimpan, jadi writer selalu sinkron
+	if a.kafka, err = newKafkaWriter(a.cfg, false); err != nil {
+		return err
+	}
+	sent, err := appKafka.NewDeadLetters([]string{a.cfg.KafkaBroker}, dialer, a.kafka).Replay(ctx, dlqTopic, selected...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📤 %d pesan dari %s dikirim ulang ke topic asal\n", sent, dlqTopic)
+	return nil
+}
+
+func splitNonEmpty(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"Task-CRUD/internal/events"
+)
+
+// runEvents menangani `events replay`: mengirim ulang kondisi repository
+// saat ini ke Kafka, misalnya untuk mengisi ulang consumer baru.
+func runEvents(ctx context.Context, args []string) error {
+	_, rest, err := subcommand("events", args, "replay")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("events replay", flag.ContinueOnError)
+	ids := fs.String("ids", "", "daftar ID repository dipisah koma (default: semua)")
+	topic := fs.String("topic", "repository_created", "jenis event (repository_created|repository_updated); topic tujuan mengikuti KAFKA_TOPIC_MODE")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+
+	var repoIDs []uint
+	if *ids != "" {
+		for _, s := range strings.Split(*ids, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+			if err != nil || id == 0 {
+				return fmt.Errorf("ID tidak valid: %s", s)
+			}
+			repoIDs = append(repoIDs, uint(id))
+		}
+	}
+
+	a := newApp()
+	defer a.close()
+	if err := a.initDB(); err != nil {
+		return err
+	}
+	if err := a.initKafka(); err != nil {
+		return err
+	}
+
+	sent, err := a.repoUseCase().ReplayRepoEvents(ctx, *topic, repoIDs...)
+	log.Printf("📤 %d event dikirim ulang ke %s", sent, events.Topic(strings.ReplaceAll(*topic, "_", ".")))
+	return err
+}
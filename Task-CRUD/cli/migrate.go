@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"context"
+	"log"
+)
+
+// runMigrate menjalankan AutoMigrate tanpa menyalakan server
+func runMigrate(ctx context.Context, args []string) error {
+	a := newApp()
+	defer a.close()
+
+	if err := a.initDB(); err != nil {
+		return err
+	}
+	if err := migrate(a.gormDB, a.cfg); err != nil {
+		return err
+	}
+	log.Println("✅ AutoMigrate berhasil")
+	return nil
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"Task-CRUD/internal/entity"
+)
+
+// runRepo menangani `repo import|export` dalam format JSON array entity.Repository
+func runRepo(ctx context.Context, args []string) error {
+	sub, rest, err := subcommand("repo", args, "import", "export")
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "import":
+		return repoImport(ctx, rest)
+	default:
+		return repoExport(ctx, rest)
+	}
+}
+
+// repoExport menulis semua repository ke file atau stdout
+func repoExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("repo export", flag.ContinueOnError)
+	out := fs.String("out", "", "file tujuan (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	a := newApp()
+	defer a.close()
+	if err := a.initDB(); err != nil {
+		return err
+	}
+	if err := a.initRedis(false); err != nil {
+		return err
+	}
+
+	repos, err := a.repoUseCase().GetAllRepos(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil daftar repository: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("gagal membuat file %s: %w", *out, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(repos); err != nil {
+		return fmt.Errorf("gagal menulis JSON: %w", err)
+	}
+	log.Printf("📤 %d repository diekspor", len(repos))
+	return nil
+}
+
+// repoImport membuat repository dari file JSON melalui RepoUseCase.CreateRepo,
+// sehingga validasi, invalidasi cache dan event Kafka tetap berjalan.
+func repoImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("repo import", flag.ContinueOnError)
+	in := fs.String("in", "", "file sumber (default: stdin)")
+	noEvents := fs.Bool("no-events", false, "jangan kirim event Kafka")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return fmt.Errorf("gagal membuka file %s: %w", *in, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var repos []entity.Repository
+	if err := json.NewDecoder(r).Decode(&repos); err != nil {
+		return fmt.Errorf("format JSON tidak valid: %w", err)
+	}
+
+	a := newApp()
+	defer a.close()
+	if err := a.initDB(); err != nil {
+		return err
+	}
+	if err := a.initRedis(false); err != nil {
+		return err
+	}
+	if !*noEvents {
+		if err := a.initKafka(); err != nil {
+			return err
+		}
+	}
+	uc := a.repoUseCase()
+
+	failed := 0
+	for i := range repos {
+		repo := repos[i]
+		// ID dan relasi user dibuat ulang oleh database
+		repo.ID = 0
+		repo.User = entity.User{}
+		if err := uc.CreateRepo(ctx, &repo); err != nil {
+			log.Printf("⚠️ Gagal import repository #%d (%s): %v", i+1, repos[i].Name, err)
+			failed++
+			continue
+		}
+	}
+
+	log.Printf("📥 %d repository diimpor, %d gagal", len(repos)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d repository gagal diimpor", failed)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	repoRepo "Task-CRUD/internal/repository/repo"
+	userRepo "Task-CRUD/internal/repository/user"
+	"Task-CRUD/internal/seed"
+)
+
+// runSeed mengisi database dengan data palsu yang deterministik.
+// Mode "repository" memakai batch insert langsung ke database, mode "usecase"
+// menulis lewat usecase sehingga cache dan event Kafka ikut terisi.
+func runSeed(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	users := fs.Int("users", 10, "jumlah user")
+	repos := fs.Int("repos", 3, "jumlah repository per user")
+	seedValue := fs.Int64("seed", 1, "nilai seed (nilai sama menghasilkan data sama)")
+	batch := fs.Int("batch", 100, "ukuran batch insert")
+	mode := fs.String("mode", "repository", "cara menulis data: repository|usecase")
+	events := fs.Bool("events", false, "kirim event Kafka (hanya mode usecase)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *mode != "repository" && *mode != "usecase" {
+		return fmt.Errorf("mode seed tidak dikenal: %s", *mode)
+	}
+
+	a := newApp()
+	defer a.close()
+	if err := a.initDB(); err != nil {
+		return err
+	}
+
+	userBatchRepo := userRepo.NewUserBatchRepositoryGorm(a.gormDB)
+	repoBatchRepo := repoRepo.NewRepoBatchRepositoryGorm(a.gormDB)
+
+	var seeder *seed.Seeder
+	if *mode == "usecase" {
+		if err := a.initRedis(false); err != nil {
+			return err
+		}
+		if *events {
+			if err := a.initKafka(); err != nil {
+				return err
+			}
+		}
+		seeder = seed.NewSeederWithUseCase(userBatchRepo, repoBatchRepo, a.userUseCase(), a.repoUseCase())
+	} else {
+		seeder = seed.NewSeeder(userBatchRepo, repoBatchRepo)
+	}
+
+	res, err := seeder.Run(ctx, seed.Options{
+		Users:        *users,
+		ReposPerUser: *repos,
+		Seed:         *seedValue,
+		BatchSize:    *batch,
+	})
+	log.Printf("🌱 Seed selesai: user %d baru/%d dilewati, repository %d baru/%d dilewati",
+		res.UsersCreated, res.UsersSkipped, res.ReposCreated, res.ReposSkipped)
+	return err
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"Task-CRUD/config"
+	"Task-CRUD/delivery"
+	"Task-CRUD/internal/cbreaker"
+	"Task-CRUD/internal/health"
+	"Task-CRUD/internal/repository/changefeed"
+	"Task-CRUD/internal/repository/outbox"
+	"Task-CRUD/internal/repository/tx"
+	"Task-CRUD/internal/usecase"
+	appKafka "Task-CRUD/kafka"
+	"Task-CRUD/tracing"
+
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// runServe menjalankan HTTP server sampai ctx dibatalkan (SIGINT/SIGTERM)
+func runServe(ctx context.Context, args []string) error {
+	log.Println("📦 Memulai inisialisasi server...")
+
+	// Load konfigurasi dari .env
+	cfg := config.LoadConfig()
+	log.Println("🔧 Konfigurasi berhasil dimuat")
+	applyEventConfig(cfg)
+
+	// Validasi konfigurasi penting
+	if cfg.ServerPort == "" || cfg.DbName == "" || cfg.DbHost == "" || cfg.HttpReadTimeout == 0 {
+		log.Fatal("❌ Konfigurasi tidak lengkap atau nilai timeout tidak di-set. Mohon cek file .env kamu")
+	}
+
+	// Inisialisasi Jaeger Tracing
+	tracer, closer, err := tracing.InitJaeger("task-crud-service")
+	if err != nil {
+		log.Fatalf("❌ Gagal inisialisasi Jaeger: %v", err)
+	}
+	defer closer.Close()
+	opentracing.SetGlobalTracer(tracer)
+	log.Println("🛰️ Jaeger tracing aktif")
+
+	// Inisialisasi GORM (PostgreSQL)
+	gormDB, err := config.InitPostgres(cfg)
+	if err != nil {
+		log.Fatalf("❌ Gagal inisialisasi PostgreSQL (GORM): %v", err)
+	}
+	log.Println("✅ Koneksi ke PostgreSQL (GORM) berhasil")
+
+	// Ambil *sql.DB dari GORM
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		log.Fatalf("❌ Gagal mengambil koneksi *sql.DB dari GORM: %v", err)
+	}
+	log.Println("✅ Koneksi SQL Native berhasil")
+
+	// AutoMigrate untuk entity
+	if err := migrate(gormDB, cfg); err != nil {
+		log.Fatalf("❌ Gagal AutoMigrate: %v", err)
+	}
+	log.Println("✅ AutoMigrate berhasil")
+
+	// Status dependency untuk readiness
+	status := health.NewRegistry()
+	status.Set("postgres", true, health.StateUp, nil)
+
+	// Inisialisasi cache. Redis boleh mati: server tetap jalan tanpa cache
+	// dan cache aktif otomatis saat Redis kembali.
+	caches := usecase.NewSwitchableCaches(usecase.NoCaches())
+	var stopCaches func()
+	if cfg.CacheBackend == "redis" {
+		stopCaches, err = superviseRedis(cfg, caches, status)
+		if err != nil {
+			log.Fatalf("❌ Konfigurasi Redis tidak valid: %v", err)
+		}
+	} else {
+		next, stop := newCaches(cfg, nil)
+		caches.Swap(next)
+		stopCaches = stop
+	}
+	log.Printf("🗃️ Cache memakai backend %s", cfg.CacheBackend)
+
+	// ✅ Inisialisasi Circuit Breaker secara global
+	cbreaker.Breaker = cbreaker.NewDefaultBreaker("UserBreaker")
+	log.Println("🔌 Circuit Breaker siap digunakan")
+
+	// ✅ Inisialisasi Kafka Writer. Selama broker mati, event dilewati dan
+	// writer diaktifkan lagi saat broker kembali.
+	publisher := appKafka.NewPublisher(nil)
+	relayPublisher := appKafka.NewPublisher(nil)
+	stopKafka, err := superviseKafka(cfg, publisher, relayPublisher, status)
+	if err != nil {
+		log.Fatalf("❌ Konfigurasi Kafka tidak valid: %v", err)
+	}
+
+	// Relay outbox mengirim event yang tertunda setiap kali Kafka tersedia
+	relay := &usecase.OutboxRelay{
+		Outbox:    outbox.NewOutboxRepositoryGorm(gormDB),
+		Publisher: relayPublisher,
+		Interval:  cfg.OutboxPollInterval,
+		BatchSize: cfg.OutboxBatchSize,
+		Retention: cfg.OutboxRetention,
+	}
+	relay.Start()
+	log.Println("📮 Outbox relay berjalan")
+
+	// Change feed: perubahan users/repositories yang ditulis langsung ke database
+	// ikut meng-invalidate cache dan menjadi event lewat outbox
+	var feed *usecase.ChangeFeed
+	if cfg.ChangeFeedEnabled {
+		feed = &usecase.ChangeFeed{
+			Changes:      changefeed.NewChangeFeedRepositoryGorm(gormDB),
+			Listener:     changefeed.NewListenerPgx(config.PostgresDSN(cfg)),
+			Caches:       caches,
+			Pipeline:     usecase.NewOutboxPipeline(tx.NewTransactorGorm(gormDB), outbox.NewOutboxRepositoryGorm(gormDB)),
+			PollInterval: cfg.ChangeFeedPollInterval,
+			Retention:    cfg.ChangeFeedRetention,
+		}
+		feed.Start()
+		log.Println("🔔 Change feed database berjalan")
+	}
+
+	// Event stream SSE/WebSocket dibaca dari Kafka oleh setiap replica
+	stream, stopStream, err := startEventStream(cfg)
+	if err != nil {
+		log.Fatalf("❌ Konfigurasi Kafka tidak valid: %v", err)
+	}
+	log.Println("📡 Event stream berjalan")
+
+	// Setup router dengan GORM + SQL + Cache + Kafka + status dependency + event stream
+	if cfg.AdminToken == "" {
+		log.Println("⚠️ ADMIN_TOKEN kosong: endpoint /webhooks dan /admin/cache ditutup")
+	}
+	router := delivery.NewRouter(gormDB, sqlDB, caches, publisher, status, stream, cfg.AdminToken)
+
+	// Setup HTTP server
+	server := &http.Server{
+		Addr:         ":" + cfg.ServerPort,
+		Handler:      router,
+		ReadTimeout:  cfg.HttpReadTimeout,
+		WriteTimeout: cfg.HttpWriteTimeout,
+		IdleTimeout:  cfg.HttpIdleTimeout,
+	}
+	// Klien stream diputus saat shutdown supaya Shutdown tidak menunggu koneksi SSE
+	server.RegisterOnShutdown(stream.Close)
+
+	// Jalankan server dalam goroutine
+	go func() {
+		log.Printf("🚀 Server berjalan di port %s...", cfg.ServerPort)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("❌ Server error: %v", err)
+		}
+	}()
+
+	// Graceful shutdown
+	<-ctx.Done()
+	log.Println("🛑 Mematikan server...")
+
+	ctxShutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctxShutdown); err != nil {
+		log.Fatalf("❌ Gagal shutdown server dengan baik: %v", err)
+	}
+
+	relay.Stop()
+	if feed != nil {
+		feed.Stop()
+	}
+	stopStream()
+	stopKafka()
+	stopCaches()
+	safeClose("PostgreSQL", config.ClosePostgres)
+	safeClose("Redis", config.CloseRedis)
+
+	log.Println("👋 Server dimatikan dengan aman")
+	return nil
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"log"
+
+	"Task-CRUD/config"
+	"Task-CRUD/internal/usecase"
+	appKafka "Task-CRUD/kafka"
+)
+
+// startEventStream membaca semua topic event tanpa consumer group dan
+// menyebarkannya ke klien SSE/WebSocket replica ini. Selama Kafka mati, stream
+// tetap melayani klien dan mulai mengalir lagi saat broker kembali.
+func startEventStream(cfg *config.Config) (stream *usecase.EventStream, stop func(), err error) {
+	sec, err := kafkaSecurity(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	dialer, err := sec.Dialer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tail := appKafka.NewTail(appKafka.TailConfig{
+		Brokers: []string{cfg.KafkaBroker},
+		Topics:  eventTopics(),
+		Dialer:  dialer,
+	})
+	stream = usecase.NewEventStream(tail, cfg.StreamBufferSize, cfg.StreamMaxReplay)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := tail.Run(ctx, stream); err != nil {
+			log.Printf("⚠️ Event stream berhenti: %v", err)
+		}
+	}()
+
+	return stream, func() {
+		cancel()
+		<-done
+		stream.Close()
+	}, nil
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"Task-CRUD/internal/entity"
+)
+
+// runUser menangani `user create|list|delete` melalui UserUseCase
+// sehingga validasi (validateUser), invalidasi cache dan event Kafka tetap berlaku.
+func runUser(ctx context.Context, args []string) error {
+	sub, rest, err := subcommand("user", args, "create", "list", "delete")
+	if err != nil {
+		return err
+	}
+
+	a := newApp()
+	defer a.close()
+	if err := a.initDB(); err != nil {
+		return err
+	}
+	if err := a.initRedis(false); err != nil {
+		return err
+	}
+	// create dan delete mengirim event user seperti lewat API
+	if sub != "list" {
+		if err := a.initKafka(); err != nil {
+			return err
+		}
+	}
+	uc := a.userUseCase()
+
+	switch sub {
+	case "create":
+		fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+		name := fs.String("name", "", "nama user")
+		email := fs.String("email", "", "email user")
+		if err := fs.Parse(rest); err != nil {
+			return err
+		}
+
+		user := entity.User{Name: *name, Email: *email}
+		if err := uc.CreateUser(ctx, &user); err != nil {
+			return fmt.Errorf("gagal membuat user: %w", err)
+		}
+		fmt.Printf("✅ User dibuat dengan ID %d\n", user.ID)
+
+	case "list":
+		users, err := uc.GetUsers(ctx)
+		if err != nil {
+			return fmt.Errorf("gagal mengambil data user: %w", err)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tEMAIL\tCREATED_AT")
+		for _, u := range users {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", u.ID, u.Name, u.Email, u.CreatedAt.Format("2006-01-02 15:04:05"))
+		}
+		return tw.Flush()
+
+	case "delete":
+		if len(rest) == 0 {
+			return fmt.Errorf("penggunaan: user delete <id>")
+		}
+		id, err := strconv.ParseUint(rest[0], 10, 32)
+		if err != nil || id == 0 {
+			return fmt.Errorf("ID tidak valid: %s", rest[0])
+		}
+		if err := uc.DeleteUser(ctx, uint(id)); err != nil {
+			return fmt.Errorf("gagal menghapus user: %w", err)
+		}
+		fmt.Printf("🗑️ User %d dihapus\n", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"Task-CRUD/internal/events"
+	"Task-CRUD/internal/repository/dedup"
+	"Task-CRUD/internal/repository/tx"
+	"Task-CRUD/internal/repository/webhook"
+	"Task-CRUD/internal/usecase"
+	appKafka "Task-CRUD/kafka"
+)
+
+// runWorker menjalankan consumer Kafka tanpa HTTP server. Worker membuang cache
+// yang terdampak event repository/user sehingga instance lain tetap konsisten,
+// mengantrekan delivery webhook dan mengirimkannya. Berhenti dengan rapi saat
+// SIGINT/SIGTERM.
+func runWorker(ctx context.Context, _ []string) error {
+	a := newApp()
+	defer a.close()
+	if err := a.initRedis(false); err != nil {
+		return err
+	}
+	if err := a.initDB(); err != nil {
+		return err
+	}
+
+	registry := appKafka.NewRegistry()
+	invalidate := func(ctx context.Context, m appKafka.Message) error {
+		return a.caches.InvalidateForEvent(ctx, m.Event)
+	}
+	registry.Register(invalidate,
+		events.RepositoryCreated, events.RepositoryUpdated, events.RepositoryDeleted,
+		events.UserCreated, events.UserUpdated, events.UserDeleted,
+	)
+
+	// Delivery webhook ditulis lewat transaksi dedup (jika ada), jadi event yang
+	// dikirim ulang Kafka tidak menghasilkan delivery ganda
+	webhookRepository := webhook.NewWebhookRepositoryGorm(a.gormDB)
+	webhookUseCase := usecase.NewWebhookUseCase(webhookRepository)
+	enqueueWebhooks := func(ctx context.Context, m appKafka.Message) error {
+		_, err := webhookUseCase.EnqueueEvent(ctx, m.Event)
+		return err
+	}
+	registry.Register(enqueueWebhooks, events.Names()...)
+
+	cfg := appKafka.ConsumerConfig{
+		Brokers:             []string{a.cfg.KafkaBroker},
+		GroupID:             a.cfg.KafkaGroupID,
+		WorkersPerPartition: a.cfg.KafkaWorkersPerPartition,
+		QueueSize:           a.cfg.KafkaWorkerQueueSize,
+		ShutdownTimeout:     a.cfg.KafkaShutdownTimeout,
+		RetryDelays:         a.cfg.KafkaRetryDelays,
+		DedupRetention:      a.cfg.KafkaDedupTTL,
+	}
+	if err := a.initDedup(&cfg); err != nil {
+		return err
+	}
+	if err := a.initConsumerKafka(&cfg); err != nil {
+		return err
+	}
+
+	consumer := appKafka.NewConsumer(cfg, registry)
+	if err := ensureTopics(ctx, a.cfg, consumer.Topics()...); err != nil {
+		// Consumer tetap jalan dan mencoba lagi; topic bisa dibuat oleh server
+		log.Printf("⚠️ Gagal menyiapkan topic Kafka: %v", err)
+	}
+
+	dispatcher := &usecase.WebhookDispatcher{
+		Webhooks:     webhookRepository,
+		Timeout:      a.cfg.WebhookTimeout,
+		MaxAttempts:  a.cfg.WebhookMaxAttempts,
+		DisableAfter: a.cfg.WebhookDisableAfter,
+		Retention:    a.cfg.WebhookRetention,
+
+		AllowPrivateTargets: a.cfg.WebhookAllowPrivate,
+	}
+	dispatcher.Start()
+	defer dispatcher.Stop()
+
+	statsCtx, stopStats := context.WithCancel(ctx)
+	defer stopStats()
+	go logConsumerStats(statsCtx, consumer, a.cfg.KafkaStatsInterval)
+
+	err := consumer.Run(ctx)
+	stats := consumer.Stats()
+	log.Printf("✅ Worker berhenti (diproses: %d, duplikat: %d, retry: %d, DLQ: %d)",
+		stats.Processed, stats.Duplicates, stats.Retried, stats.DeadLettered)
+	return err
+}
+
+// logConsumerStats mencatat statistik kumulatif consumer setiap interval selama
+// worker berjalan (worker tidak punya HTTP server). Log dilewati jika tidak ada
+// perubahan sejak log terakhir.
+func logConsumerStats(ctx context.Context, consumer *appKafka.Consumer, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last appKafka.ConsumerStats
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stats := consumer.Stats()
+		if stats == last {
+			continue
+		}
+		last = stats
+		log.Printf("📊 Statistik consumer (diproses: %d, duplikat: %d, retry: %d, DLQ: %d, tanpa handler: %d)",
+			stats.Processed, stats.Duplicates, stats.Retried, stats.DeadLettered, stats.Unhandled)
+	}
+}
+
+// initDedup memilih penyimpanan dedup sesuai KAFKA_DEDUP_STORE. Dengan Postgres,
+// tanda dedup dan perubahan handler ditulis dalam satu transaksi.
+func (a *app) initDedup(cfg *appKafka.ConsumerConfig) error {
+	switch a.cfg.KafkaDedupStore {
+	case "postgres":
+		if err := a.initDB(); err != nil {
+			return err
+		}
+		cfg.Dedup = dedup.NewDedupRepositoryGorm(a.gormDB)
+		cfg.Transactor = tx.NewTransactorGorm(a.gormDB)
+	case "redis":
+		if a.redis == nil {
+			return fmt.Errorf("KAFKA_DEDUP_STORE=redis membutuhkan Redis")
+		}
+		cfg.Dedup = dedup.NewDedupRepositoryRedis(a.redis, a.cfg.KafkaDedupPrefix, a.cfg.KafkaDedupTTL)
+	}
+	return nil
+}
+
+// initConsumerKafka memasang SASL/TLS untuk reader dan writer sinkron untuk
+// topic retry/DLQ (offset baru di-commit setelah pesan gagal tersimpan)
+func (a *app) initConsumerKafka(cfg *appKafka.ConsumerConfig) error {
+	sec, err := kafkaSecurity(a.cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.Dialer, err = sec.Dialer(); err != nil {
+		return err
+	}
+	writer, err := newKafkaWriter(a.cfg, false)
+	if err != nil {
+		return err
+	}
+	a.kafka = writer
+	cfg.Writer = writer
+	return nil
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -22,8 +24,106 @@ type Config struct {
 	RedisPort     string
 	RedisPassword string
 
+	RedisMode             string   // standalone | sentinel | cluster
+	RedisAddrs            []string // alamat sentinel/node cluster; kosong = RedisHost:RedisPort
+	RedisMasterName       string   // nama master untuk mode sentinel
+	RedisUsername         string   // username ACL (Redis 6+)
+	RedisSentinelPassword string
+	RedisDB               int // hanya standalone/sentinel
+	RedisPoolSize         int // 0 = default go-redis (10 per CPU)
+	RedisMinIdleConns     int
+	RedisDialTimeout      time.Duration
+	RedisReadTimeout      time.Duration
+	RedisWriteTimeout     time.Duration
+	RedisTLSEnabled       bool
+	RedisTLSCAFile        string
+	RedisTLSCertFile      string
+	RedisTLSKeyFile       string
+	RedisTLSServerName    string
+	RedisTLSSkipVerify    bool
+
+	CacheBackend         string // redis | memory | none
+	CacheKeyPrefix       string
+	CacheVersion         int
+	CacheMemorySize      int
+	CacheTTLRepositories time.Duration
+	CacheTTLRepository   time.Duration
+	CacheTTLUsers        time.Duration
+	CacheTTLUser         time.Duration
+	CacheStaleTTL        time.Duration
+	CacheEarlyBeta       float64
+	CacheLockTTL         time.Duration
+	CacheL1Size          int
+	CacheL1TTL           time.Duration
+	CacheNegativeTTL     time.Duration
+	CacheCodec           string
+	CacheCompressMin     int
+
 	KafkaBroker string
-	KafkaTopic  string
+	KafkaTopic  string // opsional, hanya untuk KAFKA_TOPIC_MODE=aggregate: topic aggregate repository
+	EventSource string
+
+	KafkaTopicMode       string            // event | aggregate
+	KafkaTopics          map[string]string // nama event/aggregate -> topic
+	KafkaPartitions      int
+	KafkaTopicPartitions map[string]int
+	KafkaReplication     int           // <= 0 = default broker
+	KafkaRetention       time.Duration // 0 = default broker
+
+	KafkaEncoding       string            // json | avro
+	KafkaTopicEncodings map[string]string // topic -> json | avro
+
+	SchemaRegistryURL      string
+	SchemaRegistryUsername string
+	SchemaRegistryPassword string
+	SchemaRegistryTimeout  time.Duration
+
+	KafkaBatchSize    int
+	KafkaBatchTimeout time.Duration
+	KafkaRequiredAcks string // all | one | none
+	KafkaCompression  string // none | gzip | snappy | lz4 | zstd
+	KafkaAsync        bool
+	KafkaMaxAttempts  int
+
+	KafkaSASLMechanism string // none | plain | scram-sha-256 | scram-sha-512
+	KafkaSASLUsername  string
+	KafkaSASLPassword  string
+	KafkaTLSEnabled    bool
+	KafkaTLSCAFile     string
+	KafkaTLSCertFile   string
+	KafkaTLSKeyFile    string
+	KafkaTLSServerName string
+	KafkaTLSSkipVerify bool
+
+	KafkaGroupID             string
+	KafkaWorkersPerPartition int
+	KafkaWorkerQueueSize     int
+	KafkaShutdownTimeout     time.Duration
+	KafkaRetryDelays         []time.Duration // jeda tiap retry topic; kosong = langsung ke DLQ
+	KafkaDedupStore          string          // postgres | redis | none
+	KafkaDedupTTL            time.Duration
+	KafkaDedupPrefix         string        // prefix key dedup Redis, di luar namespace cache supaya tidak ikut di-flush
+	KafkaStatsInterval       time.Duration // jeda log statistik consumer di worker; 0 = nonaktif
+
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	OutboxRetention    time.Duration
+
+	DbApplicationName      string // application_name koneksi service; perubahannya tidak dicatat trigger change feed
+	ChangeFeedEnabled      bool
+	ChangeFeedPollInterval time.Duration
+	ChangeFeedRetention    time.Duration
+
+	WebhookTimeout      time.Duration
+	WebhookMaxAttempts  int
+	WebhookDisableAfter int // kegagalan berturut-turut sebelum webhook dinonaktifkan
+	WebhookRetention    time.Duration
+	WebhookAllowPrivate bool // izinkan URL webhook ke alamat loopback/privat (pengembangan lokal)
+
+	AdminToken string // bearer token untuk /webhooks dan /admin/cache; kosong = endpoint admin ditutup
+
+	StreamBufferSize int // pesan terbaru yang disimpan untuk resume klien stream
+	StreamMaxReplay  int // batas pesan yang dibaca ulang dari Kafka saat resume
 
 	HttpReadTimeout  time.Duration
 	HttpWriteTimeout time.Duration
@@ -56,9 +156,71 @@ func LoadConfig() *Config {
 	viper.SetDefault("REDIS_HOST", "localhost")
 	viper.SetDefault("REDIS_PORT", "6379")
 	viper.SetDefault("REDIS_PASSWORD", "secret123")
+	viper.SetDefault("REDIS_MODE", "standalone")
+	viper.SetDefault("REDIS_DB", 0)
+	viper.SetDefault("REDIS_POOL_SIZE", 0)
+	viper.SetDefault("REDIS_MIN_IDLE_CONNS", 0)
+	viper.SetDefault("REDIS_DIAL_TIMEOUT_MS", 5000)
+	viper.SetDefault("REDIS_READ_TIMEOUT_MS", 3000)
+	viper.SetDefault("REDIS_WRITE_TIMEOUT_MS", 3000)
+	viper.SetDefault("REDIS_TLS_ENABLED", false)
+	viper.SetDefault("REDIS_TLS_SKIP_VERIFY", false)
+
+	viper.SetDefault("CACHE_BACKEND", "redis")
+	viper.SetDefault("CACHE_KEY_PREFIX", "task-crud")
+	viper.SetDefault("CACHE_VERSION", 1)
+	viper.SetDefault("CACHE_MEMORY_SIZE", 10000)
+	viper.SetDefault("CACHE_TTL_REPOSITORIES", 600)
+	viper.SetDefault("CACHE_TTL_REPOSITORY", 600)
+	viper.SetDefault("CACHE_TTL_USERS", 600)
+	viper.SetDefault("CACHE_TTL_USER", 600)
+	viper.SetDefault("CACHE_STALE_TTL", 60)
+	viper.SetDefault("CACHE_EARLY_REFRESH_BETA", 1.0)
+	viper.SetDefault("CACHE_LOCK_TTL_MS", 5000)
+	viper.SetDefault("CACHE_L1_SIZE", 1000)
+	viper.SetDefault("CACHE_L1_TTL_MS", 5000)
+	viper.SetDefault("CACHE_NEGATIVE_TTL", 30)
+	viper.SetDefault("CACHE_CODEC", "json")
+	viper.SetDefault("CACHE_COMPRESS_MIN_BYTES", 1024)
 
 	viper.SetDefault("KAFKA_BROKER", "kafka:9092")
-	viper.SetDefault("KAFKA_TOPIC", "repository-topic")
+	viper.SetDefault("EVENT_SOURCE", "/task-crud")
+	viper.SetDefault("KAFKA_TOPIC_MODE", "event")
+	viper.SetDefault("KAFKA_PARTITIONS", 3)
+	viper.SetDefault("KAFKA_REPLICATION_FACTOR", 0)
+	viper.SetDefault("KAFKA_RETENTION", 0)
+	viper.SetDefault("KAFKA_ENCODING", "json")
+	viper.SetDefault("SCHEMA_REGISTRY_TIMEOUT_MS", 10000)
+	viper.SetDefault("KAFKA_BATCH_SIZE", 100)
+	viper.SetDefault("KAFKA_BATCH_TIMEOUT_MS", 10)
+	viper.SetDefault("KAFKA_REQUIRED_ACKS", "all")
+	viper.SetDefault("KAFKA_COMPRESSION", "none")
+	viper.SetDefault("KAFKA_ASYNC", false)
+	viper.SetDefault("KAFKA_MAX_ATTEMPTS", 10)
+	viper.SetDefault("KAFKA_SASL_MECHANISM", "none")
+	viper.SetDefault("KAFKA_GROUP_ID", "task-crud-group")
+	viper.SetDefault("KAFKA_WORKERS_PER_PARTITION", 4)
+	viper.SetDefault("KAFKA_WORKER_QUEUE_SIZE", 16)
+	viper.SetDefault("KAFKA_SHUTDOWN_TIMEOUT", 10)
+	viper.SetDefault("KAFKA_RETRY_DELAYS", "10s,1m,10m")
+	viper.SetDefault("KAFKA_DEDUP_STORE", "postgres")
+	viper.SetDefault("KAFKA_DEDUP_TTL", 604800)
+	viper.SetDefault("KAFKA_DEDUP_PREFIX", "task-crud-dedup")
+	viper.SetDefault("KAFKA_STATS_INTERVAL", 60)
+	viper.SetDefault("OUTBOX_POLL_INTERVAL_MS", 1000)
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("OUTBOX_RETENTION", 86400)
+	viper.SetDefault("DB_APPLICATION_NAME", "task-crud")
+	viper.SetDefault("CHANGE_FEED_ENABLED", true)
+	viper.SetDefault("CHANGE_FEED_POLL_INTERVAL", 30)
+	viper.SetDefault("CHANGE_FEED_RETENTION", 86400)
+	viper.SetDefault("WEBHOOK_TIMEOUT_MS", 10000)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_DISABLE_AFTER", 20)
+	viper.SetDefault("WEBHOOK_RETENTION", 604800)
+	viper.SetDefault("WEBHOOK_ALLOW_PRIVATE_TARGETS", false)
+	viper.SetDefault("STREAM_BUFFER_SIZE", 1000)
+	viper.SetDefault("STREAM_MAX_REPLAY", 10000)
 
 	viper.SetDefault("HTTP_READ_TIMEOUT", 15)
 	viper.SetDefault("HTTP_WRITE_TIMEOUT", 15)
@@ -77,8 +239,104 @@ func LoadConfig() *Config {
 		RedisHost:        viper.GetString("REDIS_HOST"),
 		RedisPort:        viper.GetString("REDIS_PORT"),
 		RedisPassword:    viper.GetString("REDIS_PASSWORD"),
-		KafkaBroker:      viper.GetString("KAFKA_BROKER"),
-		KafkaTopic:       viper.GetString("KAFKA_TOPIC"),
+
+		RedisMode:             viper.GetString("REDIS_MODE"),
+		RedisAddrs:            splitList(viper.GetString("REDIS_ADDRS")),
+		RedisMasterName:       viper.GetString("REDIS_MASTER_NAME"),
+		RedisUsername:         viper.GetString("REDIS_USERNAME"),
+		RedisSentinelPassword: viper.GetString("REDIS_SENTINEL_PASSWORD"),
+		RedisDB:               viper.GetInt("REDIS_DB"),
+		RedisPoolSize:         viper.GetInt("REDIS_POOL_SIZE"),
+		RedisMinIdleConns:     viper.GetInt("REDIS_MIN_IDLE_CONNS"),
+		RedisDialTimeout:      time.Duration(viper.GetInt("REDIS_DIAL_TIMEOUT_MS")) * time.Millisecond,
+		RedisReadTimeout:      time.Duration(viper.GetInt("REDIS_READ_TIMEOUT_MS")) * time.Millisecond,
+		RedisWriteTimeout:     time.Duration(viper.GetInt("REDIS_WRITE_TIMEOUT_MS")) * time.Millisecond,
+		RedisTLSEnabled:       viper.GetBool("REDIS_TLS_ENABLED"),
+		RedisTLSCAFile:        viper.GetString("REDIS_TLS_CA_FILE"),
+		RedisTLSCertFile:      viper.GetString("REDIS_TLS_CERT_FILE"),
+		RedisTLSKeyFile:       viper.GetString("REDIS_TLS_KEY_FILE"),
+		RedisTLSServerName:    viper.GetString("REDIS_TLS_SERVER_NAME"),
+		RedisTLSSkipVerify:    viper.GetBool("REDIS_TLS_SKIP_VERIFY"),
+
+		CacheBackend:         viper.GetString("CACHE_BACKEND"),
+		CacheKeyPrefix:       viper.GetString("CACHE_KEY_PREFIX"),
+		CacheVersion:         viper.GetInt("CACHE_VERSION"),
+		CacheMemorySize:      viper.GetInt("CACHE_MEMORY_SIZE"),
+		CacheTTLRepositories: time.Duration(viper.GetInt("CACHE_TTL_REPOSITORIES")) * time.Second,
+		CacheTTLRepository:   time.Duration(viper.GetInt("CACHE_TTL_REPOSITORY")) * time.Second,
+		CacheTTLUsers:        time.Duration(viper.GetInt("CACHE_TTL_USERS")) * time.Second,
+		CacheTTLUser:         time.Duration(viper.GetInt("CACHE_TTL_USER")) * time.Second,
+		CacheStaleTTL:        time.Duration(viper.GetInt("CACHE_STALE_TTL")) * time.Second,
+		CacheEarlyBeta:       viper.GetFloat64("CACHE_EARLY_REFRESH_BETA"),
+		CacheLockTTL:         time.Duration(viper.GetInt("CACHE_LOCK_TTL_MS")) * time.Millisecond,
+		CacheL1Size:          viper.GetInt("CACHE_L1_SIZE"),
+		CacheL1TTL:           time.Duration(viper.GetInt("CACHE_L1_TTL_MS")) * time.Millisecond,
+		CacheNegativeTTL:     time.Duration(viper.GetInt("CACHE_NEGATIVE_TTL")) * time.Second,
+		CacheCodec:           viper.GetString("CACHE_CODEC"),
+		CacheCompressMin:     viper.GetInt("CACHE_COMPRESS_MIN_BYTES"),
+
+		KafkaBroker: viper.GetString("KAFKA_BROKER"),
+		KafkaTopic:  viper.GetString("KAFKA_TOPIC"),
+		EventSource: viper.GetString("EVENT_SOURCE"),
+
+		KafkaTopicMode:  viper.GetString("KAFKA_TOPIC_MODE"),
+		KafkaTopics:     splitPairs(viper.GetString("KAFKA_TOPICS")),
+		KafkaPartitions: viper.GetInt("KAFKA_PARTITIONS"),
+
+		KafkaReplication:   viper.GetInt("KAFKA_REPLICATION_FACTOR"),
+		KafkaRetention:     time.Duration(viper.GetInt("KAFKA_RETENTION")) * time.Second,
+		KafkaBatchSize:     viper.GetInt("KAFKA_BATCH_SIZE"),
+		KafkaBatchTimeout:  time.Duration(viper.GetInt("KAFKA_BATCH_TIMEOUT_MS")) * time.Millisecond,
+		KafkaRequiredAcks:  viper.GetString("KAFKA_REQUIRED_ACKS"),
+		KafkaCompression:   viper.GetString("KAFKA_COMPRESSION"),
+		KafkaAsync:         viper.GetBool("KAFKA_ASYNC"),
+		KafkaMaxAttempts:   viper.GetInt("KAFKA_MAX_ATTEMPTS"),
+		KafkaSASLMechanism: viper.GetString("KAFKA_SASL_MECHANISM"),
+		KafkaSASLUsername:  viper.GetString("KAFKA_SASL_USERNAME"),
+		KafkaSASLPassword:  viper.GetString("KAFKA_SASL_PASSWORD"),
+		KafkaTLSEnabled:    viper.GetBool("KAFKA_TLS_ENABLED"),
+		KafkaTLSCAFile:     viper.GetString("KAFKA_TLS_CA_FILE"),
+		KafkaTLSCertFile:   viper.GetString("KAFKA_TLS_CERT_FILE"),
+		KafkaTLSKeyFile:    viper.GetString("KAFKA_TLS_KEY_FILE"),
+		KafkaTLSServerName: viper.GetString("KAFKA_TLS_SERVER_NAME"),
+		KafkaTLSSkipVerify: viper.GetBool("KAFKA_TLS_SKIP_VERIFY"),
+
+		KafkaEncoding:       viper.GetString("KAFKA_ENCODING"),
+		KafkaTopicEncodings: splitPairs(viper.GetString("KAFKA_TOPIC_ENCODINGS")),
+
+		SchemaRegistryURL:      viper.GetString("SCHEMA_REGISTRY_URL"),
+		SchemaRegistryUsername: viper.GetString("SCHEMA_REGISTRY_USERNAME"),
+		SchemaRegistryPassword: viper.GetString("SCHEMA_REGISTRY_PASSWORD"),
+		SchemaRegistryTimeout:  time.Duration(viper.GetInt("SCHEMA_REGISTRY_TIMEOUT_MS")) * time.Millisecond,
+
+		KafkaGroupID:             viper.GetString("KAFKA_GROUP_ID"),
+		KafkaWorkersPerPartition: viper.GetInt("KAFKA_WORKERS_PER_PARTITION"),
+		KafkaWorkerQueueSize:     viper.GetInt("KAFKA_WORKER_QUEUE_SIZE"),
+		KafkaShutdownTimeout:     time.Duration(viper.GetInt("KAFKA_SHUTDOWN_TIMEOUT")) * time.Second,
+		KafkaDedupStore:          viper.GetString("KAFKA_DEDUP_STORE"),
+		KafkaDedupTTL:            time.Duration(viper.GetInt("KAFKA_DEDUP_TTL")) * time.Second,
+		KafkaDedupPrefix:         viper.GetString("KAFKA_DEDUP_PREFIX"),
+		KafkaStatsInterval:       time.Duration(viper.GetInt("KAFKA_STATS_INTERVAL")) * time.Second,
+
+		OutboxPollInterval: time.Duration(viper.GetInt("OUTBOX_POLL_INTERVAL_MS")) * time.Millisecond,
+		OutboxBatchSize:    viper.GetInt("OUTBOX_BATCH_SIZE"),
+		OutboxRetention:    time.Duration(viper.GetInt("OUTBOX_RETENTION")) * time.Second,
+
+		DbApplicationName:      viper.GetString("DB_APPLICATION_NAME"),
+		ChangeFeedEnabled:      viper.GetBool("CHANGE_FEED_ENABLED"),
+		ChangeFeedPollInterval: time.Duration(viper.GetInt("CHANGE_FEED_POLL_INTERVAL")) * time.Second,
+		ChangeFeedRetention:    time.Duration(viper.GetInt("CHANGE_FEED_RETENTION")) * time.Second,
+
+		WebhookTimeout:      time.Duration(viper.GetInt("WEBHOOK_TIMEOUT_MS")) * time.Millisecond,
+		WebhookMaxAttempts:  viper.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+		WebhookDisableAfter: viper.GetInt("WEBHOOK_DISABLE_AFTER"),
+		WebhookRetention:    time.Duration(viper.GetInt("WEBHOOK_RETENTION")) * time.Second,
+		WebhookAllowPrivate: viper.GetBool("WEBHOOK_ALLOW_PRIVATE_TARGETS"),
+
+		AdminToken: viper.GetString("ADMIN_TOKEN"),
+
+		StreamBufferSize: viper.GetInt("STREAM_BUFFER_SIZE"),
+		StreamMaxReplay:  viper.GetInt("STREAM_MAX_REPLAY"),
 		HttpReadTimeout:  time.Duration(viper.GetInt("HTTP_READ_TIMEOUT")) * time.Second,
 		HttpWriteTimeout: time.Duration(viper.GetInt("HTTP_WRITE_TIMEOUT")) * time.Second,
 		HttpIdleTimeout:  time.Duration(viper.GetInt("HTTP_IDLE_TIMEOUT")) * time.Second,
@@ -88,13 +346,140 @@ func LoadConfig() *Config {
 	if cfg.ServerPort == "" || cfg.DbHost == "" || cfg.DbUser == "" || cfg.DbName == "" {
 		log.Fatal("❌ Konfigurasi server/PostgreSQL tidak lengkap")
 	}
-	if cfg.RedisHost == "" || cfg.RedisPort == "" {
-		log.Fatal("❌ Konfigurasi Redis tidak lengkap")
+	switch cfg.RedisMode {
+	case "standalone":
+		if len(cfg.RedisAddrs) == 0 && (cfg.RedisHost == "" || cfg.RedisPort == "") {
+			log.Fatal("❌ Konfigurasi Redis tidak lengkap")
+		}
+	case "sentinel":
+		if cfg.RedisMasterName == "" || len(cfg.RedisAddrs) == 0 {
+			log.Fatal("❌ Mode sentinel membutuhkan REDIS_MASTER_NAME dan REDIS_ADDRS")
+		}
+	case "cluster":
+		if len(cfg.RedisAddrs) == 0 {
+			log.Fatal("❌ Mode cluster membutuhkan REDIS_ADDRS")
+		}
+		if cfg.RedisDB != 0 {
+			log.Fatal("❌ Redis Cluster hanya mendukung REDIS_DB=0")
+		}
+	default:
+		log.Fatalf("❌ REDIS_MODE tidak dikenal: %s (pilihan: standalone|sentinel|cluster)", cfg.RedisMode)
+	}
+	if (cfg.RedisTLSCertFile == "") != (cfg.RedisTLSKeyFile == "") {
+		log.Fatal("❌ REDIS_TLS_CERT_FILE dan REDIS_TLS_KEY_FILE harus diisi bersamaan")
+	}
+	if cfg.CacheBackend != "redis" && cfg.CacheBackend != "memory" && cfg.CacheBackend != "none" {
+		log.Fatalf("❌ CACHE_BACKEND tidak dikenal: %s (pilihan: redis|memory|none)", cfg.CacheBackend)
+	}
+	if cfg.CacheCodec != "json" && cfg.CacheCodec != "msgpack" {
+		log.Fatalf("❌ CACHE_CODEC tidak dikenal: %s (pilihan: json|msgpack)", cfg.CacheCodec)
 	}
-	if cfg.KafkaBroker == "" || cfg.KafkaTopic == "" {
+	if cfg.DbApplicationName == "" {
+		// Trigger change feed melewati perubahan dari application_name ini; kosong berarti melewati psql tanpa nama
+		log.Fatal("❌ DB_APPLICATION_NAME tidak boleh kosong")
+	}
+	if cfg.KafkaBroker == "" {
 		log.Fatal("❌ Konfigurasi Kafka tidak lengkap")
 	}
+	if cfg.KafkaTopicMode != "event" && cfg.KafkaTopicMode != "aggregate" {
+		log.Fatalf("❌ KAFKA_TOPIC_MODE tidak dikenal: %s (pilihan: event|aggregate)", cfg.KafkaTopicMode)
+	}
+	if cfg.KafkaTopic != "" && cfg.KafkaTopicMode != "aggregate" {
+		log.Printf("⚠️ KAFKA_TOPIC=%s diabaikan pada KAFKA_TOPIC_MODE=%s; gunakan KAFKA_TOPICS (mis. repository.created=%s)",
+			cfg.KafkaTopic, cfg.KafkaTopicMode, cfg.KafkaTopic)
+	}
+	switch cfg.KafkaRequiredAcks {
+	case "all", "one", "none":
+	default:
+		log.Fatalf("❌ KAFKA_REQUIRED_ACKS tidak dikenal: %s (pilihan: all|one|none)", cfg.KafkaRequiredAcks)
+	}
+	switch cfg.KafkaCompression {
+	case "none", "gzip", "snappy", "lz4", "zstd":
+	default:
+		log.Fatalf("❌ KAFKA_COMPRESSION tidak dikenal: %s (pilihan: none|gzip|snappy|lz4|zstd)", cfg.KafkaCompression)
+	}
+	switch cfg.KafkaSASLMechanism {
+	case "none":
+	case "plain", "scram-sha-256", "scram-sha-512":
+		if cfg.KafkaSASLUsername == "" || cfg.KafkaSASLPassword == "" {
+			log.Fatal("❌ KAFKA_SASL_MECHANISM membutuhkan KAFKA_SASL_USERNAME dan KAFKA_SASL_PASSWORD")
+		}
+	default:
+		log.Fatalf("❌ KAFKA_SASL_MECHANISM tidak dikenal: %s (pilihan: none|plain|scram-sha-256|scram-sha-512)", cfg.KafkaSASLMechanism)
+	}
+	if (cfg.KafkaTLSCertFile == "") != (cfg.KafkaTLSKeyFile == "") {
+		log.Fatal("❌ KAFKA_TLS_CERT_FILE dan KAFKA_TLS_KEY_FILE harus diisi bersamaan")
+	}
+	if cfg.KafkaPartitions <= 0 {
+		log.Fatal("❌ KAFKA_PARTITIONS harus lebih dari 0")
+	}
+	cfg.KafkaTopicPartitions = make(map[string]int)
+	for topic, value := range splitPairs(viper.GetString("KAFKA_TOPIC_PARTITIONS")) {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Fatalf("❌ KAFKA_TOPIC_PARTITIONS tidak valid untuk %s: %s", topic, value)
+		}
+		cfg.KafkaTopicPartitions[topic] = n
+	}
+	usesAvro := false
+	for topic, encoding := range cfg.KafkaTopicEncodings {
+		if encoding != "json" && encoding != "avro" {
+			log.Fatalf("❌ KAFKA_TOPIC_ENCODINGS tidak valid untuk %s: %s (pilihan: json|avro)", topic, encoding)
+		}
+		usesAvro = usesAvro || encoding == "avro"
+	}
+	switch cfg.KafkaEncoding {
+	case "json":
+	case "avro":
+		usesAvro = true
+	default:
+		log.Fatalf("❌ KAFKA_ENCODING tidak dikenal: %s (pilihan: json|avro)", cfg.KafkaEncoding)
+	}
+	if usesAvro && cfg.SchemaRegistryURL == "" {
+		log.Fatal("❌ Encoding avro membutuhkan SCHEMA_REGISTRY_URL")
+	}
+	if cfg.KafkaDedupStore != "postgres" && cfg.KafkaDedupStore != "redis" && cfg.KafkaDedupStore != "none" {
+		log.Fatalf("❌ KAFKA_DEDUP_STORE tidak dikenal: %s (pilihan: postgres|redis|none)", cfg.KafkaDedupStore)
+	}
+	// Key di bawah "<CACHE_KEY_PREFIX>:" ikut terhapus oleh flush cache (cache flush, pemulihan Redis)
+	if cfg.KafkaDedupPrefix == "" || strings.HasPrefix(cfg.KafkaDedupPrefix+":", cfg.CacheKeyPrefix+":") {
+		log.Fatalf("❌ KAFKA_DEDUP_PREFIX harus diisi dan berada di luar namespace cache %s:*", cfg.CacheKeyPrefix)
+	}
+	cfg.KafkaRetryDelays = []time.Duration{}
+	if v := viper.GetString("KAFKA_RETRY_DELAYS"); v != "none" {
+		for _, item := range splitList(v) {
+			d, err := time.ParseDuration(item)
+			if err != nil || d <= 0 {
+				log.Fatalf("❌ KAFKA_RETRY_DELAYS tidak valid: %s (contoh: 10s,1m,10m atau none)", item)
+			}
+			cfg.KafkaRetryDelays = append(cfg.KafkaRetryDelays, d)
+		}
+	}
 
 	log.Println("✅ Konfigurasi berhasil dimuat")
 	return cfg
 }
+
+// splitList memecah nilai dipisah koma menjadi slice tanpa elemen kosong
+func splitList(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// splitPairs membaca daftar "key=value" dipisah koma menjadi map
+func splitPairs(value string) map[string]string {
+	out := make(map[string]string)
+	for _, item := range splitList(value) {
+		k, v, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(k) == "" || strings.TrimSpace(v) == "" {
+			log.Fatalf("❌ Pasangan konfigurasi tidak valid: %q (format: key=value)", item)
+		}
+		out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return out
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,12 +25,7 @@ func InitPostgres(cfg *Config) (*gorm.DB, error) {
 	var err error
 
 	initOnce.Do(func() {
-		dsn := fmt.Sprintf(
-			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta",
-			cfg.DbHost, cfg.DbUser, cfg.DbPassword, cfg.DbName, cfg.DbPort,
-		)
-
-		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+		db, err = gorm.Open(postgres.Open(PostgresDSN(cfg)), &gorm.Config{
 			NamingStrategy: schema.NamingStrategy{
 				TablePrefix:   "public.",
 				SingularTable: false,
@@ -63,6 +59,16 @@ func InitPostgres(cfg *Config) (*gorm.DB, error) {
 	return db, err
 }
 
+// PostgresDSN mengembalikan DSN PostgreSQL; dipakai juga koneksi LISTEN change feed.
+// application_name menandai koneksi service supaya trigger change feed melewatinya.
+func PostgresDSN(cfg *Config) string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta application_name='%s'",
+		cfg.DbHost, cfg.DbUser, cfg.DbPassword, cfg.DbName, cfg.DbPort,
+		strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(cfg.DbApplicationName),
+	)
+}
+
 // GetDB mengembalikan instance *gorm.DB untuk digunakan di seluruh aplikasi.
 func GetDB() *gorm.DB {
 	if db == nil {
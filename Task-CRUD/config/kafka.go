@@ -0,0 +1,13 @@
+package config
+
+import (
+	"crypto/tls"
+)
+
+// KafkaTLSConfig mengembalikan konfigurasi TLS broker Kafka, nil jika TLS tidak aktif
+func KafkaTLSConfig(cfg *Config) (*tls.Config, error) {
+	if !cfg.KafkaTLSEnabled {
+		return nil, nil
+	}
+	return loadTLSConfig("Kafka", cfg.KafkaTLSCAFile, cfg.KafkaTLSCertFile, cfg.KafkaTLSKeyFile, cfg.KafkaTLSServerName, cfg.KafkaTLSSkipVerify)
+}
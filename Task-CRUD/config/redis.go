@@ -2,36 +2,129 @@ package config
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-var RedisClient *redis.Client
+// RedisClient adalah client Redis global; tipenya UniversalClient supaya
+// pemakai tidak perlu tahu apakah di baliknya standalone, sentinel atau cluster.
+var RedisClient redis.UniversalClient
 
 // InitRedis menginisialisasi koneksi Redis dengan konfigurasi dari Config
 func InitRedis(cfg *Config) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	RedisClient = redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
-		Password: cfg.RedisPassword, // gunakan password jika ada
-		DB:       0,                 // default DB
-	})
+	client, err := NewRedisClient(cfg)
+	if err != nil {
+		log.Printf("❌ Konfigurasi Redis tidak valid: %v", err)
+		return err
+	}
+	RedisClient = client
 
 	// Ping untuk cek koneksi
 	if _, err := RedisClient.Ping(ctx).Result(); err != nil {
-		log.Printf("❌ Gagal terhubung ke Redis (%s:%s): %v", cfg.RedisHost, cfg.RedisPort, err)
+		log.Printf("❌ Gagal terhubung ke Redis %s (%s): %v", cfg.RedisMode, redisTarget(cfg), err)
 		return err
 	}
 
-	log.Printf("✅ Berhasil terhubung ke Redis di %s:%s", cfg.RedisHost, cfg.RedisPort)
+	log.Printf("✅ Berhasil terhubung ke Redis %s di %s", cfg.RedisMode, redisTarget(cfg))
 	return nil
 }
 
+// NewRedisClient membuat client sesuai REDIS_MODE tanpa melakukan ping
+func NewRedisClient(cfg *Config) (redis.UniversalClient, error) {
+	opts := &redis.UniversalOptions{
+		Addrs:            redisAddrs(cfg),
+		MasterName:       cfg.RedisMasterName,
+		Username:         cfg.RedisUsername,
+		Password:         cfg.RedisPassword,
+		SentinelPassword: cfg.RedisSentinelPassword,
+		DB:               cfg.RedisDB,
+		PoolSize:         cfg.RedisPoolSize,
+		MinIdleConns:     cfg.RedisMinIdleConns,
+		DialTimeout:      cfg.RedisDialTimeout,
+		ReadTimeout:      cfg.RedisReadTimeout,
+		WriteTimeout:     cfg.RedisWriteTimeout,
+	}
+
+	if cfg.RedisTLSEnabled {
+		tlsConfig, err := redisTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	switch cfg.RedisMode {
+	case "sentinel":
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case "cluster":
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		return redis.NewClient(opts.Simple()), nil
+	}
+}
+
+func redisAddrs(cfg *Config) []string {
+	if len(cfg.RedisAddrs) > 0 {
+		return cfg.RedisAddrs
+	}
+	return []string{fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort)}
+}
+
+func redisTarget(cfg *Config) string {
+	target := strings.Join(redisAddrs(cfg), ",")
+	if cfg.RedisMode == "sentinel" {
+		target = cfg.RedisMasterName + "@" + target
+	}
+	return target
+}
+
+// redisTLSConfig memuat CA dan sertifikat client (mTLS) jika diatur
+func redisTLSConfig(cfg *Config) (*tls.Config, error) {
+	return loadTLSConfig("Redis", cfg.RedisTLSCAFile, cfg.RedisTLSCertFile, cfg.RedisTLSKeyFile, cfg.RedisTLSServerName, cfg.RedisTLSSkipVerify)
+}
+
+// loadTLSConfig membuat tls.Config dengan CA dan sertifikat client opsional;
+// name dipakai di pesan error (mis. "Redis", "Kafka")
+func loadTLSConfig(name, caFile, certFile, keyFile, serverName string, skipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         serverName,
+		InsecureSkipVerify: skipVerify,
+	}
+
+	if caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca CA %s: %w", name, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("CA %s %s tidak berisi sertifikat PEM", name, caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("gagal memuat sertifikat client %s: %w", name, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
 // CloseRedis menutup koneksi Redis secara aman
 func CloseRedis() error {
 	if RedisClient == nil {
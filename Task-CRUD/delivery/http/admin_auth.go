@@ -0,0 +1,29 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// AdminAuthMiddleware membatasi endpoint admin untuk request dengan header
+// "Authorization: Bearer <ADMIN_TOKEN>". Token kosong menutup endpoint sepenuhnya.
+func AdminAuthMiddleware(token string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				writeRepoError(w, http.StatusForbidden, "Endpoint admin nonaktif (ADMIN_TOKEN belum diatur)")
+				return
+			}
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				writeRepoError(w, http.StatusUnauthorized, "Token admin tidak valid")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
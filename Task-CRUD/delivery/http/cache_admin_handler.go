@@ -0,0 +1,186 @@
+package http
+
+import (
+	"Task-CRUD/internal/cache"
+	"Task-CRUD/internal/entity"
+	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/usecase"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/opentracing/opentracing-go"
+)
+
+type CacheAdminHandler struct {
+	adminUC interfaces.CacheAdminUseCaseInterface
+}
+
+func NewCacheAdminHandler(adminUC interfaces.CacheAdminUseCaseInterface) *CacheAdminHandler {
+	return &CacheAdminHandler{adminUC: adminUC}
+}
+
+// namespaceStats menambahkan angka turunan ke statistik mentah
+type namespaceStats struct {
+	cache.NamespaceStats
+	HitRatio     float64 `json:"hit_ratio"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	MaxLatencyMs float64 `json:"max_latency_ms"`
+}
+
+func writeAdminError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrUnknownNamespace):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrCacheAdminUnsupported):
+		status = http.StatusNotImplemented
+	case errors.Is(err, entity.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidCacheRequest):
+		status = http.StatusBadRequest
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func writeAdminBadRequest(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+func writeAdminJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// GET /admin/cache/stats
+func (h *CacheAdminHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	out := make(map[string]namespaceStats)
+	for ns, s := range h.adminUC.Stats() {
+		stats := namespaceStats{
+			NamespaceStats: s,
+			HitRatio:       s.HitRatio(),
+			MaxLatencyMs:   float64(s.MaxLatency.Microseconds()) / 1000,
+		}
+		if s.Calls > 0 {
+			stats.AvgLatencyMs = float64(s.TotalLatency.Microseconds()) / float64(s.Calls) / 1000
+		}
+		out[ns] = stats
+	}
+	writeAdminJSON(w, out)
+}
+
+// GET /admin/cache/{namespace}/keys?pattern=*&limit=100
+func (h *CacheAdminHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.CacheListKeys")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	namespace := mux.Vars(r)["namespace"]
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeAdminBadRequest(w, "limit tidak valid")
+			return
+		}
+		limit = n
+	}
+
+	keys, err := h.adminUC.ListKeys(ctx, namespace, r.URL.Query().Get("pattern"), limit)
+	if err != nil {
+		log.Printf("ERROR | CacheListKeys: %v", err)
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminJSON(w, map[string]interface{}{"namespace": namespace, "count": len(keys), "keys": keys})
+}
+
+// GET /admin/cache/{namespace}/keys/{key}
+func (h *CacheAdminHandler) Inspect(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.CacheInspect")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	vars := mux.Vars(r)
+	info, err := h.adminUC.Inspect(ctx, vars["namespace"], vars["key"])
+	if err != nil {
+		log.Printf("ERROR | CacheInspect: %v", err)
+		writeAdminError(w, err)
+		return
+	}
+	if info == nil {
+		writeAdminError(w, entity.ErrNotFound)
+		return
+	}
+	writeAdminJSON(w, info)
+}
+
+// DELETE /admin/cache/{namespace}/keys/{key}
+func (h *CacheAdminHandler) DeleteKey(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.CacheDeleteKey")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	vars := mux.Vars(r)
+	if err := h.adminUC.DeleteKeys(ctx, vars["namespace"], vars["key"]); err != nil {
+		log.Printf("ERROR | CacheDeleteKey: %v", err)
+		writeAdminError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /admin/cache/{namespace}/keys?pattern=...
+func (h *CacheAdminHandler) DeletePattern(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.CacheDeletePattern")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	namespace := mux.Vars(r)["namespace"]
+	pattern := r.URL.Query().Get("pattern")
+	if pattern == "" {
+		writeAdminBadRequest(w, "query pattern wajib diisi")
+		return
+	}
+
+	deleted, err := h.adminUC.DeletePattern(ctx, namespace, pattern)
+	if err != nil {
+		log.Printf("ERROR | CacheDeletePattern: %v", err)
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminJSON(w, map[string]interface{}{"namespace": namespace, "pattern": pattern, "deleted": deleted})
+}
+
+// POST /admin/cache/{namespace}/warm  body: {"ids": [1, 2]}
+func (h *CacheAdminHandler) Warm(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.CacheWarm")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	var req struct {
+		IDs []uint `json:"ids"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAdminBadRequest(w, "Format JSON tidak valid")
+			return
+		}
+	}
+
+	namespace := mux.Vars(r)["namespace"]
+	warmed, err := h.adminUC.Warm(ctx, namespace, req.IDs...)
+	if err != nil {
+		log.Printf("ERROR | CacheWarm: %v", err)
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminJSON(w, map[string]interface{}{"namespace": namespace, "warmed": warmed})
+}
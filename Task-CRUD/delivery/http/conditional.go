@@ -0,0 +1,64 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// writeConditionalJSON menulis body JSON dengan ETag dan Last-Modified, atau
+// 304 Not Modified jika klien sudah punya versi yang sama.
+// If-None-Match diutamakan; If-Modified-Since hanya dipakai jika tidak ada
+// If-None-Match (RFC 9110). lastModified nol berarti tanpa Last-Modified, dipakai
+// untuk list karena list yang berkurang tidak mengubah UpdatedAt terbaru.
+func writeConditionalJSON(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time, body interface{}) {
+	if etag != "" {
+		w.Header().Set("ETag", `"`+etag+`"`)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	// Klien harus selalu revalidasi, respons 304 murah
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && etagMatches(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified.IsZero() {
+		return false
+	}
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	// Header HTTP hanya punya presisi detik
+	return !lastModified.Truncate(time.Second).After(t)
+}
+
+// etagMatches membandingkan daftar ETag di If-None-Match (weak comparison)
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		candidate = strings.TrimPrefix(candidate, "W/")
+		if strings.Trim(candidate, `"`) == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"Task-CRUD/internal/events"
+
+	"github.com/gorilla/mux"
+)
+
+// EventSchemaHandler melayani JSON Schema event; URL-nya sama dengan atribut dataschema
+type EventSchemaHandler struct{}
+
+func NewEventSchemaHandler() *EventSchemaHandler {
+	return &EventSchemaHandler{}
+}
+
+// List GET /events/schemas
+func (h *EventSchemaHandler) List(w http.ResponseWriter, r *http.Request) {
+	type schemaInfo struct {
+		Name    string `json:"name"`
+		Version int    `json:"version"`
+		Type    string `json:"type"`
+		URI     string `json:"uri"`
+	}
+	var out []schemaInfo
+	for _, s := range events.Schemas() {
+		out = append(out, schemaInfo{Name: s.Name, Version: s.Version, Type: s.Type(), URI: s.URI()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// Get GET /events/schemas/{name}/v{version}
+func (h *EventSchemaHandler) Get(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	version, err := strconv.Atoi(strings.TrimPrefix(vars["version"], "v"))
+	if err != nil {
+		writeRepoError(w, http.StatusBadRequest, "versi schema tidak valid")
+		return
+	}
+	schema, ok := events.LookupVersion(vars["name"], version)
+	if !ok {
+		writeRepoError(w, http.StatusNotFound, "schema tidak ditemukan")
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(schema.Body)
+}
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"Task-CRUD/internal/usecase"
+
+	"github.com/gorilla/websocket"
+	"github.com/opentracing/opentracing-go"
+)
+
+// streamHeartbeat adalah jeda maksimum tanpa data ke klien. Heartbeat SSE
+// membawa cursor terbaru supaya klien dengan filter sempit tidak tertinggal jauh.
+const streamHeartbeat = 15 * time.Second
+
+// EventStreamHandler mengirim event perubahan repository/user secara langsung
+// lewat Server-Sent Events dan WebSocket
+type EventStreamHandler struct {
+	stream   *usecase.EventStream
+	upgrader websocket.Upgrader
+}
+
+func NewEventStreamHandler(stream *usecase.EventStream) *EventStreamHandler {
+	return &EventStreamHandler{stream: stream}
+}
+
+// streamMessage adalah pesan WebSocket: type "event", "checkpoint" (cursor
+// terbaru tanpa event) atau "reset" (cursor lama tidak bisa dilanjutkan)
+type streamMessage struct {
+	Type  string      `json:"type"`
+	ID    string      `json:"id,omitempty"`
+	Event interface{} `json:"event,omitempty"`
+}
+
+// subscribe membaca filter ?type=repository,user&id=12&owner=3 dan cursor dari
+// header Last-Event-ID atau query last_event_id (WebSocket di browser tidak bisa mengirim header)
+func (h *EventStreamHandler) subscribe(w http.ResponseWriter, r *http.Request, name string) (*usecase.StreamSubscription, bool) {
+	span := opentracing.StartSpan(name)
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	q := r.URL.Query()
+	filter := usecase.StreamFilter{ID: q.Get("id"), Owner: q.Get("owner")}
+	for _, t := range strings.Split(q.Get("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			filter.Types = append(filter.Types, t)
+		}
+	}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = q.Get("last_event_id")
+	}
+
+	sub, err := h.stream.Subscribe(ctx, filter, lastEventID)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidStreamCursor):
+		writeRepoError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	case errors.Is(err, usecase.ErrStreamClosed):
+		writeRepoError(w, http.StatusServiceUnavailable, err.Error())
+		return nil, false
+	case err != nil:
+		log.Printf("ERROR | %s: %v", name, err)
+		writeRepoError(w, http.StatusInternalServerError, "Gagal membuka event stream")
+		return nil, false
+	}
+	return sub, true
+}
+
+// Stream GET /events/stream (Server-Sent Events)
+func (h *EventStreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	sub, ok := h.subscribe(w, r, "Handler.StreamEvents")
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	// Koneksi SSE hidup lama; WriteTimeout server tidak berlaku di sini
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: 3000\n\n")
+	if sub.Reset {
+		fmt.Fprintf(w, "id: %s\nevent: reset\ndata: {}\n\n", sub.Cursor())
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	sent := sub.Cursor()
+	for {
+		ctx, cancel := context.WithTimeout(r.Context(), streamHeartbeat)
+		event, err := sub.Next(ctx)
+		cancel()
+
+		switch {
+		case err == nil:
+			data, err := json.Marshal(event.Event)
+			if err != nil {
+				log.Printf("ERROR | StreamEvents: %v", err)
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\ndata: %s\n\n", event.ID, data)
+			sent = event.ID
+		case errors.Is(err, context.DeadlineExceeded) && r.Context().Err() == nil:
+			// Pesan tanpa data tidak memicu event di klien, tetapi memperbarui Last-Event-ID
+			if cursor := sub.Cursor(); cursor != sent {
+				fmt.Fprintf(w, "id: %s\n\n", cursor)
+				sent = cursor
+			} else {
+				fmt.Fprintf(w, ": ping\n\n")
+			}
+		default:
+			// Klien putus, tertinggal, atau server berhenti; klien tersambung lagi dengan Last-Event-ID
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// WebSocket GET /events/ws
+func (h *EventStreamHandler) WebSocket(w http.ResponseWriter, r *http.Request) {
+	sub, ok := h.subscribe(w, r, "Handler.StreamEventsWebSocket")
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade sudah menulis respons error
+		return
+	}
+	defer conn.Close()
+
+	// Pesan dari klien diabaikan; pembacaan diperlukan untuk pong dan close frame
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		conn.SetReadLimit(4096)
+		_ = conn.SetReadDeadline(time.Now().Add(3 * streamHeartbeat))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(3 * streamHeartbeat))
+		})
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(msg streamMessage) error {
+		_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		return conn.WriteJSON(msg)
+	}
+	if sub.Reset {
+		if err := write(streamMessage{Type: "reset", ID: sub.Cursor()}); err != nil {
+			return
+		}
+	}
+
+	sent := sub.Cursor()
+	for {
+		next, cancelNext := context.WithTimeout(ctx, streamHeartbeat)
+		event, err := sub.Next(next)
+		cancelNext()
+
+		switch {
+		case err == nil:
+			err = write(streamMessage{Type: "event", ID: event.ID, Event: event.Event})
+			sent = event.ID
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			if cursor := sub.Cursor(); cursor != sent {
+				err = write(streamMessage{Type: "checkpoint", ID: cursor})
+				sent = cursor
+			} else {
+				_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+				err = conn.WriteMessage(websocket.PingMessage, nil)
+			}
+		default:
+			code, reason := websocket.CloseGoingAway, "server berhenti"
+			if errors.Is(err, usecase.ErrStreamLagging) {
+				code, reason = websocket.CloseTryAgainLater, err.Error()
+			}
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
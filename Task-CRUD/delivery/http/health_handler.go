@@ -10,10 +10,10 @@ import (
 
 type HealthHandler struct {
 	DB    *gorm.DB
-	Redis *redis.Client
+	Redis redis.UniversalClient
 }
 
-func NewHealthHandler(db *gorm.DB, redis *redis.Client) *HealthHandler {
+func NewHealthHandler(db *gorm.DB, redis redis.UniversalClient) *HealthHandler {
 	return &HealthHandler{
 		DB:    db,
 		Redis: redis,
@@ -4,9 +4,11 @@ import (
 	"Task-CRUD/internal/entity"
 	interfaces "Task-CRUD/internal/interfaces"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/opentracing/opentracing-go"
@@ -41,15 +43,15 @@ func (h *RepoHandler) GetAllRepos(w http.ResponseWriter, r *http.Request) {
 	defer span.Finish()
 	ctx := opentracing.ContextWithSpan(r.Context(), span)
 
-	repos, err := h.repoUC.GetAllRepos(ctx)
+	repos, etag, err := h.repoUC.GetAllReposWithETag(ctx)
 	if err != nil {
 		log.Printf("ERROR | GetAllRepos: %v", err)
 		writeRepoError(w, http.StatusInternalServerError, "Gagal mengambil daftar repository")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(repos)
+	// Tanpa Last-Modified: list yang berkurang atau user yang berubah tidak menggeser UpdatedAt terbaru
+	writeConditionalJSON(w, r, etag, time.Time{}, repos)
 }
 
 func (h *RepoHandler) GetRepositoryByID(w http.ResponseWriter, r *http.Request) {
@@ -63,15 +65,23 @@ func (h *RepoHandler) GetRepositoryByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	repo, err := h.repoUC.GetRepositoryByID(ctx, id)
+	repo, etag, err := h.repoUC.GetRepositoryByIDWithETag(ctx, id)
+	if errors.Is(err, entity.ErrNotFound) {
+		writeRepoError(w, http.StatusNotFound, "Repository tidak ditemukan")
+		return
+	}
 	if err != nil {
 		log.Printf("ERROR | GetRepositoryByID: %v", err)
-		writeRepoError(w, http.StatusNotFound, "Repository tidak ditemukan")
+		writeRepoError(w, http.StatusInternalServerError, "Gagal mengambil repository")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(repo)
+	// Body memuat user pemilik, jadi perubahan user juga mengubah representasinya
+	lastModified := repo.UpdatedAt
+	if repo.User.UpdatedAt.After(lastModified) {
+		lastModified = repo.User.UpdatedAt
+	}
+	writeConditionalJSON(w, r, etag, lastModified, repo)
 }
 
 func (h *RepoHandler) CreateRepo(w http.ResponseWriter, r *http.Request) {
@@ -116,6 +126,10 @@ func (h *RepoHandler) UpdateRepo(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.repoUC.UpdateRepo(ctx, id, &updatedRepo); err != nil {
 		log.Printf("ERROR | UpdateRepo: %v", err)
+		if errors.Is(err, entity.ErrNotFound) {
+			writeRepoError(w, http.StatusNotFound, "Repository tidak ditemukan")
+			return
+		}
 		writeRepoError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -137,6 +151,10 @@ func (h *RepoHandler) DeleteRepo(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.repoUC.DeleteRepo(ctx, id); err != nil {
 		log.Printf("ERROR | DeleteRepo: %v", err)
+		if errors.Is(err, entity.ErrNotFound) {
+			writeRepoError(w, http.StatusNotFound, "Repository tidak ditemukan")
+			return
+		}
 		writeRepoError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -0,0 +1,20 @@
+package http
+
+import (
+	"net/http"
+
+	"Task-CRUD/internal/events"
+)
+
+// RequestIDMiddleware memakai header X-Request-ID (atau membuat ID baru) sebagai
+// correlation ID, sehingga semua event dari satu request bisa ditelusuri
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			id = events.NewID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(events.WithCorrelationID(r.Context(), id)))
+	})
+}
@@ -3,10 +3,12 @@ package http
 import (
 	"Task-CRUD/internal/entity"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	interfaces "Task-CRUD/internal/interfaces"
 
@@ -48,15 +50,15 @@ func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 	defer span.Finish()
 	ctx := opentracing.ContextWithSpan(r.Context(), span)
 
-	users, err := h.userUC.GetUsers(ctx)
+	users, etag, err := h.userUC.GetUsersWithETag(ctx)
 	if err != nil {
 		log.Printf("ERROR | GetUsers: %v", err)
 		writeUserError(w, http.StatusInternalServerError, "Gagal mengambil data user")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(users)
+	// Tanpa Last-Modified: user yang dihapus tidak menggeser UpdatedAt terbaru
+	writeConditionalJSON(w, r, etag, time.Time{}, users)
 }
 
 // GET /users/{id}
@@ -71,15 +73,18 @@ func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userUC.GetUserByID(ctx, id)
+	user, etag, err := h.userUC.GetUserByIDWithETag(ctx, id)
+	if errors.Is(err, entity.ErrNotFound) {
+		writeUserError(w, http.StatusNotFound, "User tidak ditemukan")
+		return
+	}
 	if err != nil {
 		log.Printf("ERROR | GetUserByID: %v", err)
-		writeUserError(w, http.StatusNotFound, "User tidak ditemukan")
+		writeUserError(w, http.StatusInternalServerError, "Gagal mengambil data user")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user)
+	writeConditionalJSON(w, r, etag, user.UpdatedAt, user)
 }
 
 // POST /users
@@ -126,6 +131,10 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.userUC.UpdateUser(ctx, id, &user); err != nil {
 		log.Printf("ERROR | UpdateUser: %v", err)
+		if errors.Is(err, entity.ErrNotFound) {
+			writeUserError(w, http.StatusNotFound, "User tidak ditemukan")
+			return
+		}
 		writeUserError(w, http.StatusBadRequest, err.Error()) // ❗Tampilkan pesan validasi ke user
 		return
 	}
@@ -148,6 +157,10 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.userUC.DeleteUser(ctx, id); err != nil {
 		log.Printf("ERROR | DeleteUser: %v", err)
+		if errors.Is(err, entity.ErrNotFound) {
+			writeUserError(w, http.StatusNotFound, "User tidak ditemukan")
+			return
+		}
 		writeUserError(w, http.StatusInternalServerError, "Gagal menghapus user")
 		return
 	}
@@ -0,0 +1,218 @@
+package http
+
+import (
+	"Task-CRUD/internal/entity"
+	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/usecase"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/opentracing/opentracing-go"
+)
+
+type WebhookHandler struct {
+	webhookUC interfaces.WebhookUseCaseInterface
+}
+
+func NewWebhookHandler(webhookUC interfaces.WebhookUseCaseInterface) *WebhookHandler {
+	return &WebhookHandler{webhookUC: webhookUC}
+}
+
+// webhookRequest adalah body POST/PUT /webhooks. Active kosong berarti aktif.
+type webhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+	Active     *bool    `json:"active"`
+}
+
+func (req webhookRequest) toEntity() *entity.Webhook {
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	return &entity.Webhook{URL: req.URL, EventTypes: req.EventTypes, Secret: req.Secret, Active: active}
+}
+
+func writeWebhookError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidWebhook):
+		writeRepoError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrNotFound):
+		writeRepoError(w, http.StatusNotFound, "Webhook tidak ditemukan")
+	default:
+		log.Printf("ERROR | %s: %v", op, err)
+		writeRepoError(w, http.StatusInternalServerError, "Gagal memproses webhook")
+	}
+}
+
+// GET /webhooks
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.ListWebhooks")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	webhooks, err := h.webhookUC.ListWebhooks(ctx)
+	if err != nil {
+		writeWebhookError(w, "ListWebhooks", err)
+		return
+	}
+	// Secret hanya ditampilkan sekali, saat webhook dibuat
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	writeAdminJSON(w, webhooks)
+}
+
+// GET /webhooks/{id}
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.GetWebhook")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	id, err := parseRepoID(r)
+	if err != nil {
+		writeRepoError(w, http.StatusBadRequest, "ID tidak valid")
+		return
+	}
+
+	webhook, err := h.webhookUC.GetWebhook(ctx, id)
+	if err != nil {
+		writeWebhookError(w, "GetWebhook", err)
+		return
+	}
+	webhook.Secret = ""
+	writeAdminJSON(w, webhook)
+}
+
+// POST /webhooks
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.CreateWebhook")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeRepoError(w, http.StatusBadRequest, "Format JSON tidak valid")
+		return
+	}
+
+	webhook := req.toEntity()
+	if err := h.webhookUC.CreateWebhook(ctx, webhook); err != nil {
+		writeWebhookError(w, "CreateWebhook", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// PUT /webhooks/{id}
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.UpdateWebhook")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	id, err := parseRepoID(r)
+	if err != nil {
+		writeRepoError(w, http.StatusBadRequest, "ID tidak valid")
+		return
+	}
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeRepoError(w, http.StatusBadRequest, "Format JSON tidak valid")
+		return
+	}
+
+	webhook := req.toEntity()
+	if err := h.webhookUC.UpdateWebhook(ctx, id, webhook); err != nil {
+		writeWebhookError(w, "UpdateWebhook", err)
+		return
+	}
+	webhook.Secret = ""
+	writeAdminJSON(w, webhook)
+}
+
+// DELETE /webhooks/{id}
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.DeleteWebhook")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	id, err := parseRepoID(r)
+	if err != nil {
+		writeRepoError(w, http.StatusBadRequest, "ID tidak valid")
+		return
+	}
+
+	if err := h.webhookUC.DeleteWebhook(ctx, id); err != nil {
+		writeWebhookError(w, "DeleteWebhook", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /webhooks/{id}/deliveries?limit=50
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.ListWebhookDeliveries")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	id, err := parseRepoID(r)
+	if err != nil {
+		writeRepoError(w, http.StatusBadRequest, "ID tidak valid")
+		return
+	}
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 {
+			writeRepoError(w, http.StatusBadRequest, "limit tidak valid")
+			return
+		}
+	}
+
+	deliveries, err := h.webhookUC.ListDeliveries(ctx, id, limit)
+	if err != nil {
+		writeWebhookError(w, "ListWebhookDeliveries", err)
+		return
+	}
+	writeAdminJSON(w, deliveries)
+}
+
+// POST /webhooks/{id}/deliveries/{deliveryId}/redeliver
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	span := opentracing.StartSpan("Handler.RedeliverWebhook")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(r.Context(), span)
+
+	id, err := parseRepoID(r)
+	if err != nil {
+		writeRepoError(w, http.StatusBadRequest, "ID tidak valid")
+		return
+	}
+	deliveryID, err := strconv.ParseUint(mux.Vars(r)["deliveryId"], 10, 64)
+	if err != nil || deliveryID == 0 {
+		writeRepoError(w, http.StatusBadRequest, "ID delivery tidak valid")
+		return
+	}
+
+	delivery, err := h.webhookUC.Redeliver(ctx, id, deliveryID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			writeRepoError(w, http.StatusNotFound, "Delivery tidak ditemukan")
+			return
+		}
+		writeWebhookError(w, "RedeliverWebhook", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
@@ -2,8 +2,13 @@ package delivery
 
 import (
 	httpDelivery "Task-CRUD/delivery/http"
+	"Task-CRUD/internal/health"
+	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/repository/outbox"
 	repoRepo "Task-CRUD/internal/repository/repo"
+	"Task-CRUD/internal/repository/tx"
 	userRepo "Task-CRUD/internal/repository/user"
+	webhookRepo "Task-CRUD/internal/repository/webhook"
 	"Task-CRUD/internal/usecase"
 	"context"
 	"database/sql"
@@ -12,15 +17,16 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/segmentio/kafka-go"
 	"github.com/gorilla/mux"
-	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
-// NewRouter menerima *gorm.DB, *sql.DB, Redis client, dan Kafka writer
-func NewRouter(gormDB *gorm.DB, sqlDB *sql.DB, rdb *redis.Client, kafkaWriter *kafka.Writer) *mux.Router {
+// NewRouter menerima *gorm.DB, *sql.DB, cache usecase, publisher event, registry
+// status dependency (Redis/Kafka dipantau di luar router dan boleh sedang mati),
+// hub event stream untuk klien SSE/WebSocket, dan token endpoint admin
+func NewRouter(gormDB *gorm.DB, sqlDB *sql.DB, caches *usecase.Caches, publisher interfaces.EventPublisher, status *health.Registry, stream *usecase.EventStream, adminToken string) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(httpDelivery.RequestIDMiddleware)
 
 	// ===== Health Check =====
 	router.HandleFunc("/health/liveness", func(w http.ResponseWriter, r *http.Request) {
@@ -29,37 +35,39 @@ func NewRouter(gormDB *gorm.DB, sqlDB *sql.DB, rdb *redis.Client, kafkaWriter *k
 		json.NewEncoder(w).Encode(map[string]string{"status": "live"})
 	}).Methods("GET")
 
+	// Readiness hanya gagal jika PostgreSQL mati; Redis/Kafka yang mati dilaporkan sebagai "degraded"
 	router.HandleFunc("/health/readiness", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		if err := sqlDB.Ping(); err != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+		if err := sqlDB.PingContext(ctx); err != nil {
 			log.Println("❌ SQL DB not ready:", err)
-			http.Error(w, `{"status":"SQL DB not ready"}`, http.StatusServiceUnavailable)
-			return
+			status.Set("postgres", true, health.StateDown, err)
+		} else {
+			status.Set("postgres", true, health.StateUp, nil)
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-		if err := rdb.Ping(ctx).Err(); err != nil {
-			log.Println("❌ Redis not ready:", err)
-			http.Error(w, `{"status":"Redis not ready"}`, http.StatusServiceUnavailable)
-			return
+		state, ready, components := status.Report()
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
 		}
-
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"status": "ready"})
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": state, "components": components})
 	}).Methods("GET")
 
 	// ===== Dependency Injection =====
 
-	// User (pakai SQL native dan Redis)
+	// Event user dan repository ditulis ke outbox di transaksi yang sama, lalu dikirim oleh relay
+	pipeline := usecase.NewOutboxPipeline(tx.NewTransactorGorm(gormDB), outbox.NewOutboxRepositoryGorm(gormDB))
+
+	// User (pakai SQL native dan cache)
 	userRepository := userRepo.NewUserRepositoryPostgres(sqlDB)
-	userUseCase := usecase.NewUserUseCaseWithCache(userRepository, rdb)
+	userUseCase := usecase.NewUserUseCaseWithEvents(userRepository, caches, pipeline)
 	userHandler := httpDelivery.NewUserHandler(userUseCase)
 
-	// Repository (pakai GORM + Redis + Kafka + Circuit Breaker + Tracing)
+	// Repository (pakai GORM + cache + Kafka + Circuit Breaker + Tracing)
 	repoRepository := repoRepo.NewRepoRepositoryGorm(gormDB)
-	repoUseCase := usecase.NewRepoUseCaseFull(repoRepository, rdb, kafkaWriter)
+	repoUseCase := usecase.NewRepoUseCaseWithOutbox(repoRepository, caches, publisher, pipeline)
 	repoHandler := httpDelivery.NewRepoHandler(repoUseCase)
 
 	// ===== User Routes =====
@@ -78,5 +86,40 @@ func NewRouter(gormDB *gorm.DB, sqlDB *sql.DB, rdb *redis.Client, kafkaWriter *k
 	repoRouter.HandleFunc("/{id}", repoHandler.UpdateRepo).Methods("PUT")
 	repoRouter.HandleFunc("/{id}", repoHandler.DeleteRepo).Methods("DELETE")
 
+	// ===== Event Schema Routes =====
+	schemaHandler := httpDelivery.NewEventSchemaHandler()
+	router.HandleFunc("/events/schemas", schemaHandler.List).Methods("GET")
+	router.HandleFunc("/events/schemas/{name}/{version}", schemaHandler.Get).Methods("GET")
+
+	// ===== Event Stream Routes =====
+	streamHandler := httpDelivery.NewEventStreamHandler(stream)
+	router.HandleFunc("/events/stream", streamHandler.Stream).Methods("GET")
+	router.HandleFunc("/events/ws", streamHandler.WebSocket).Methods("GET")
+
+	// ===== Webhook Routes =====
+	// Delivery dikirim oleh WebhookDispatcher di proses worker. URL webhook menentukan
+	// ke mana server mengirim request, jadi hanya admin yang boleh mengaturnya.
+	webhookHandler := httpDelivery.NewWebhookHandler(usecase.NewWebhookUseCase(webhookRepo.NewWebhookRepositoryGorm(gormDB)))
+	webhookRouter := router.PathPrefix("/webhooks").Subrouter()
+	webhookRouter.Use(httpDelivery.AdminAuthMiddleware(adminToken))
+	webhookRouter.HandleFunc("", webhookHandler.List).Methods("GET")
+	webhookRouter.HandleFunc("", webhookHandler.Create).Methods("POST")
+	webhookRouter.HandleFunc("/{id}", webhookHandler.Get).Methods("GET")
+	webhookRouter.HandleFunc("/{id}", webhookHandler.Update).Methods("PUT")
+	webhookRouter.HandleFunc("/{id}", webhookHandler.Delete).Methods("DELETE")
+	webhookRouter.HandleFunc("/{id}/deliveries", webhookHandler.ListDeliveries).Methods("GET")
+	webhookRouter.HandleFunc("/{id}/deliveries/{deliveryId}/redeliver", webhookHandler.Redeliver).Methods("POST")
+
+	// ===== Admin Cache Routes =====
+	cacheAdminHandler := httpDelivery.NewCacheAdminHandler(usecase.NewCacheAdminUseCase(caches, repoUseCase, userUseCase))
+	cacheRouter := router.PathPrefix("/admin/cache").Subrouter()
+	cacheRouter.Use(httpDelivery.AdminAuthMiddleware(adminToken))
+	cacheRouter.HandleFunc("/stats", cacheAdminHandler.Stats).Methods("GET")
+	cacheRouter.HandleFunc("/{namespace}/keys", cacheAdminHandler.ListKeys).Methods("GET")
+	cacheRouter.HandleFunc("/{namespace}/keys", cacheAdminHandler.DeletePattern).Methods("DELETE")
+	cacheRouter.HandleFunc("/{namespace}/keys/{key}", cacheAdminHandler.Inspect).Methods("GET")
+	cacheRouter.HandleFunc("/{namespace}/keys/{key}", cacheAdminHandler.DeleteKey).Methods("DELETE")
+	cacheRouter.HandleFunc("/{namespace}/warm", cacheAdminHandler.Warm).Methods("POST")
+
 	return router
 }
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// InvalidationBus menyebarkan invalidasi cache ke semua replica supaya
+// cache L1 (in-process) di replica lain ikut dibuang.
+type InvalidationBus interface {
+	// Publish mengirim key lengkap (dengan prefix namespace) yang dihapus
+	Publish(ctx context.Context, keys ...string) error
+	// Subscribe mendaftarkan handler lokal: evict untuk key dari replica lain,
+	// flush saat koneksi subscriber terputus/tersambung ulang (bisa ada pesan yang hilang).
+	Subscribe(evict func(keys []string), flush func())
+}
+
+type invalidationMessage struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+type busHandler struct {
+	evict func(keys []string)
+	flush func()
+}
+
+// RedisBus mengimplementasikan InvalidationBus dengan Redis pub/sub
+type RedisBus struct {
+	client  redis.UniversalClient
+	channel string
+	origin  string
+
+	mu       sync.RWMutex
+	handlers []busHandler
+
+	pubsub *redis.PubSub
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewRedisBus(client redis.UniversalClient, channel string) *RedisBus {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return &RedisBus{
+		client:  client,
+		channel: channel,
+		origin:  hex.EncodeToString(buf),
+	}
+}
+
+func (b *RedisBus) Subscribe(evict func(keys []string), flush func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, busHandler{evict: evict, flush: flush})
+}
+
+func (b *RedisBus) Publish(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(invalidationMessage{Origin: b.origin, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, payload).Err()
+}
+
+// Start menjalankan subscriber di background sampai Close dipanggil
+func (b *RedisBus) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.done = make(chan struct{})
+	b.pubsub = b.client.Subscribe(ctx, b.channel)
+	go b.run(ctx)
+}
+
+// Close menghentikan subscriber
+func (b *RedisBus) Close() error {
+	if b.cancel == nil {
+		return nil
+	}
+	b.cancel()
+	// Receive tidak berhenti hanya karena ctx dibatalkan, koneksi harus ditutup
+	err := b.pubsub.Close()
+	<-b.done
+	return err
+}
+
+func (b *RedisBus) run(ctx context.Context) {
+	defer close(b.done)
+
+	subscribed := false
+	for {
+		// Receive otomatis menyambung ulang dan subscribe ulang setelah error
+		msg, err := b.pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if subscribed {
+				log.Printf("⚠️ Subscriber invalidasi cache terputus: %v", err)
+				subscribed = false
+				b.flushAll()
+			}
+			// Beri jeda supaya tidak loop cepat saat Redis mati
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind != "subscribe" {
+				continue
+			}
+			if !subscribed {
+				// Pesan selama terputus mungkin hilang: buang semua L1
+				b.flushAll()
+				subscribed = true
+				log.Printf("📡 Subscriber invalidasi cache aktif di channel %s", b.channel)
+			}
+		case *redis.Message:
+			var inv invalidationMessage
+			if err := json.Unmarshal([]byte(m.Payload), &inv); err != nil {
+				log.Printf("⚠️ Pesan invalidasi cache tidak valid: %v", err)
+				continue
+			}
+			if inv.Origin == b.origin {
+				continue
+			}
+			b.evict(inv.Keys)
+		}
+	}
+}
+
+func (b *RedisBus) evict(keys []string) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, h := range b.handlers {
+		h.evict(keys)
+	}
+}
+
+func (b *RedisBus) flushAll() {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, h := range b.handlers {
+		h.flush()
+	}
+}
@@ -0,0 +1,573 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache adalah cache bertipe untuk satu namespace (mis. "repository").
+// Get mengembalikan ok=false saat miss; error hanya untuk kegagalan backend.
+type Cache[T any] interface {
+	Get(ctx context.Context, key string) (T, bool, error)
+	Set(ctx context.Context, key string, value T) error
+	Delete(ctx context.Context, keys ...string) error
+
+	// Fetch mengambil nilai dari cache atau memanggil load saat miss, dengan
+	// proteksi stampede (lihat typedCache.Fetch). Error yang dikembalikan
+	// hanya berasal dari load; kegagalan cache dicatat lalu diabaikan.
+	Fetch(ctx context.Context, key string, load LoadFunc[T]) (T, bool, error)
+	// FetchItem sama seperti Fetch tetapi juga mengembalikan ETag yang disimpan
+	// bersama nilai, sehingga respons 304 tidak perlu meng-encode ulang data.
+	FetchItem(ctx context.Context, key string, load LoadFunc[T]) (Item[T], bool, error)
+}
+
+// Item adalah nilai cache beserta ETag-nya
+type Item[T any] struct {
+	Value T
+	ETag  string // hash konten JSON nilai, tanpa tanda kutip
+}
+
+// ETag menghitung strong ETag dari representasi JSON sebuah nilai
+func ETag(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:16])
+}
+
+// LoadFunc memuat nilai dari sumber asli. found=false berarti data tidak ada;
+// hasil ini disimpan sebagai entry negatif selama NegativeTTL (jika aktif).
+// Gunakan ctx dari parameter, bukan ctx pemanggil, karena load juga dipakai
+// untuk refresh di background.
+type LoadFunc[T any] func(ctx context.Context) (value T, found bool, err error)
+
+// Store adalah backend penyimpanan byte di bawah Cache[T] (Redis, LRU in-memory, ...)
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Config berisi pengaturan bersama untuk semua namespace
+type Config struct {
+	Prefix     string                   // prefix key, mis. "task-crud"
+	Version    int                      // dinaikkan untuk membuang semua entry lama
+	DefaultTTL time.Duration            // TTL jika namespace tidak diatur khusus
+	TTLs       map[string]time.Duration // TTL per namespace
+	Metrics    Metrics                  // default: DefaultStats
+
+	// StaleTTL adalah waktu tambahan setelah TTL di mana entry lama masih boleh
+	// dikirim sambil diperbarui di background (stale-while-revalidate).
+	StaleTTL time.Duration
+	// EarlyRefreshBeta mengatur refresh probabilistik sebelum TTL habis
+	// (algoritma XFetch). 0 mematikan early refresh, 1 adalah nilai umum.
+	EarlyRefreshBeta float64
+	// Locker dipakai agar hanya satu replica yang memuat key yang sama.
+	// nil berarti hanya coalescing di dalam proses.
+	Locker Locker
+	// LockTTL adalah umur lock dan batas waktu menunggu pemegang lock
+	LockTTL time.Duration
+
+	// L1Size dan L1TTL mengaktifkan cache in-process di depan Store (per namespace).
+	// Nilai di L1 sudah ter-decode sehingga read tidak perlu round trip maupun unmarshal.
+	L1Size int
+	L1TTL  time.Duration
+	// Bus menyebarkan Delete ke replica lain supaya L1 mereka ikut dibuang
+	Bus InvalidationBus
+
+	// NegativeTTL adalah umur entry negatif (data tidak ada) yang disimpan Fetch.
+	// Dibuat pendek supaya data yang baru dibuat cepat terlihat; 0 mematikan negative caching.
+	NegativeTTL time.Duration
+
+	// Codec adalah encoding nilai di Store: CodecJSON (default) atau CodecMsgpack.
+	// Entry dengan codec lain tetap bisa dibaca selama skemanya cocok.
+	Codec string
+	// CompressThreshold: nilai yang ter-encode minimal sebesar ini di-gzip
+	// (berguna untuk list besar); 0 mematikan kompresi.
+	CompressThreshold int
+}
+
+// TTL mengembalikan TTL untuk namespace tertentu
+func (c Config) TTL(namespace string) time.Duration {
+	if ttl, ok := c.TTLs[namespace]; ok && ttl > 0 {
+		return ttl
+	}
+	if c.DefaultTTL > 0 {
+		return c.DefaultTTL
+	}
+	return 10 * time.Minute
+}
+
+// KeyPrefix mengembalikan prefix key lengkap untuk namespace, mis. "task-crud:repository:v1:"
+func (c Config) KeyPrefix(namespace string) string {
+	if c.Prefix == "" {
+		return fmt.Sprintf("%s:v%d:", namespace, c.Version)
+	}
+	return fmt.Sprintf("%s:%s:v%d:", c.Prefix, namespace, c.Version)
+}
+
+// localEntry adalah entry L1 yang sudah ter-decode
+type localEntry[T any] struct {
+	value     T
+	expiresAt int64
+	delta     int64
+	missing   bool
+	tag       string
+}
+
+type typedCache[T any] struct {
+	store     Store
+	local     *lru[localEntry[T]] // nil jika L1 tidak aktif
+	localTTL  time.Duration
+	bus       InvalidationBus
+	namespace string
+	prefix    string
+	ttl       time.Duration
+	staleTTL  time.Duration
+	negTTL    time.Duration
+	beta      float64
+	locker    Locker
+	lockTTL   time.Duration
+	metrics   Metrics
+	group     singleflight.Group
+
+	codec       string
+	compressMin int
+	schema      uint32 // sidik tipe T; entry dengan skema lain dibuang
+}
+
+// New membuat Cache[T] di atas store untuk satu namespace. Nilai disimpan dalam
+// envelope biner berversi (lihat entry) dengan encoding cfg.Codec.
+func New[T any](store Store, cfg Config, namespace string) Cache[T] {
+	metrics := cfg.Metrics
+	if metrics == nil {
+		metrics = DefaultStats
+	}
+	lockTTL := cfg.LockTTL
+	if lockTTL <= 0 {
+		lockTTL = 5 * time.Second
+	}
+	c := &typedCache[T]{
+		store:     store,
+		bus:       cfg.Bus,
+		namespace: namespace,
+		prefix:    cfg.KeyPrefix(namespace),
+		ttl:       cfg.TTL(namespace),
+		staleTTL:  cfg.StaleTTL,
+		negTTL:    cfg.NegativeTTL,
+		beta:      cfg.EarlyRefreshBeta,
+		locker:    cfg.Locker,
+		lockTTL:   lockTTL,
+		metrics:   metrics,
+
+		codec:       cfg.Codec,
+		compressMin: cfg.CompressThreshold,
+		schema:      schemaOf(reflect.TypeOf((*T)(nil)).Elem()),
+	}
+
+	if cfg.L1Size > 0 && cfg.L1TTL > 0 {
+		c.local = newLRU[localEntry[T]](cfg.L1Size)
+		c.localTTL = cfg.L1TTL
+		if cfg.Bus != nil {
+			cfg.Bus.Subscribe(c.evictLocal, c.local.flush)
+		}
+	}
+	return c
+}
+
+// evictLocal membuang key L1 milik namespace ini dari pesan invalidasi replica lain
+func (c *typedCache[T]) evictLocal(keys []string) {
+	for _, k := range keys {
+		if strings.HasPrefix(k, c.prefix) {
+			c.local.delete(k)
+		}
+	}
+}
+
+func (c *typedCache[T]) key(key string) string {
+	return c.prefix + key
+}
+
+// Get hanya mengembalikan entry yang masih segar; entry negatif dianggap miss
+func (c *typedCache[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	var zero T
+	start := time.Now()
+
+	e, value, ok, err := c.read(ctx, key)
+	if err != nil {
+		c.fail(OpGet, key, err, start)
+		return zero, false, err
+	}
+	if !ok || e.Missing || time.Now().UnixMilli() >= e.ExpiresAt {
+		c.metrics.Record(c.namespace, OpGet, OutcomeMiss, time.Since(start))
+		return zero, false, nil
+	}
+
+	c.metrics.Record(c.namespace, OpGet, OutcomeHit, time.Since(start))
+	return value, true, nil
+}
+
+func (c *typedCache[T]) Set(ctx context.Context, key string, value T) error {
+	_, err := c.write(ctx, key, value, 0)
+	return err
+}
+
+func (c *typedCache[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	start := time.Now()
+
+	full := make([]string, len(keys))
+	for i, k := range keys {
+		full[i] = c.key(k)
+	}
+	err := c.store.Delete(ctx, full...)
+	// L1 dibuang setelah Store supaya read bersamaan tidak mengisi ulang nilai lama
+	if c.local != nil {
+		c.local.delete(full...)
+	}
+	if err != nil {
+		c.fail(OpDelete, fmt.Sprint(keys), err, start)
+		return err
+	}
+	if c.bus != nil {
+		if err := c.bus.Publish(ctx, full...); err != nil {
+			c.fail(OpPublish, fmt.Sprint(keys), err, start)
+		}
+	}
+
+	c.metrics.Record(c.namespace, OpDelete, OutcomeOK, time.Since(start))
+	return nil
+}
+
+// Fetch menggabungkan beberapa proteksi stampede:
+//   - entry segar dikirim langsung, tapi bisa di-refresh lebih awal secara
+//     probabilistik (XFetch) supaya key populer tidak pernah expired bersamaan;
+//   - entry basi (lewat TTL, masih dalam StaleTTL) dikirim sambil di-refresh di background;
+//   - saat miss, pemanggil bersamaan di proses yang sama digabung (singleflight)
+//     dan antar replica dijaga dengan lock Redis berumur pendek;
+//   - entry negatif dikirim sebagai found=false tanpa memanggil load sampai
+//     NegativeTTL habis atau key dihapus.
+func (c *typedCache[T]) Fetch(ctx context.Context, key string, load LoadFunc[T]) (T, bool, error) {
+	item, found, err := c.FetchItem(ctx, key, load)
+	return item.Value, found, err
+}
+
+func (c *typedCache[T]) FetchItem(ctx context.Context, key string, load LoadFunc[T]) (Item[T], bool, error) {
+	start := time.Now()
+	span := opentracing.SpanFromContext(ctx)
+
+	e, value, ok, err := c.read(ctx, key)
+	if err != nil {
+		c.fail(OpGet, key, err, start)
+	}
+	if ok && e.Missing {
+		c.metrics.Record(c.namespace, OpGet, OutcomeNegative, time.Since(start))
+		logSpan(span, OutcomeNegative)
+		return Item[T]{}, false, nil
+	}
+	if ok {
+		item := Item[T]{Value: value, ETag: e.Tag}
+		now := time.Now().UnixMilli()
+		if now < e.ExpiresAt {
+			c.metrics.Record(c.namespace, OpGet, OutcomeHit, time.Since(start))
+			logSpan(span, OutcomeHit)
+			if c.shouldRefreshEarly(e, now) {
+				c.refreshAsync(ctx, key, load)
+			}
+			return item, true, nil
+		}
+
+		c.metrics.Record(c.namespace, OpGet, OutcomeStale, time.Since(start))
+		logSpan(span, OutcomeStale)
+		c.refreshAsync(ctx, key, load)
+		return item, true, nil
+	}
+
+	c.metrics.Record(c.namespace, OpGet, OutcomeMiss, time.Since(start))
+	logSpan(span, OutcomeMiss)
+
+	type result struct {
+		item  Item[T]
+		found bool
+	}
+	leader := false
+	v, err, shared := c.group.Do(key, func() (interface{}, error) {
+		leader = true
+		item, found, err := c.loadLocked(ctx, key, load)
+		return result{item, found}, err
+	})
+	if shared && !leader {
+		c.metrics.Record(c.namespace, OpLoad, OutcomeCoalesced, 0)
+	}
+	if err != nil {
+		return Item[T]{}, false, err
+	}
+	r := v.(result)
+	return r.item, r.found, nil
+}
+
+// loadLocked memuat nilai dengan lock antar replica. Jika lock dipegang replica
+// lain, tunggu sampai nilainya muncul di cache atau lock kedaluwarsa.
+func (c *typedCache[T]) loadLocked(ctx context.Context, key string, load LoadFunc[T]) (Item[T], bool, error) {
+	if c.locker != nil {
+		release, acquired, err := c.locker.Acquire(ctx, c.key(key)+":lock", c.lockTTL)
+		if err != nil {
+			c.fail(OpLock, key, err, time.Now())
+		} else if acquired {
+			defer release()
+		} else if item, found, ok := c.waitForValue(ctx, key); ok {
+			return item, found, nil
+		}
+	}
+	return c.load(ctx, key, load)
+}
+
+// waitForValue mengembalikan ok=true jika entry (termasuk entry negatif) muncul
+func (c *typedCache[T]) waitForValue(ctx context.Context, key string) (item Item[T], found, ok bool) {
+	var zero Item[T]
+	deadline := time.Now().Add(c.lockTTL)
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return zero, false, false
+		case <-ticker.C:
+		}
+		if e, value, ok, err := c.read(ctx, key); err == nil && ok {
+			return Item[T]{Value: value, ETag: e.Tag}, !e.Missing, true
+		}
+	}
+	return zero, false, false
+}
+
+// load memanggil sumber asli lalu menyimpan hasilnya beserta lama load (delta XFetch).
+// Data yang tidak ada disimpan sebagai entry negatif.
+func (c *typedCache[T]) load(ctx context.Context, key string, load LoadFunc[T]) (Item[T], bool, error) {
+	start := time.Now()
+	value, found, err := load(ctx)
+	if err != nil {
+		return Item[T]{}, false, err
+	}
+	c.metrics.Record(c.namespace, OpLoad, OutcomeOK, time.Since(start))
+
+	if !found {
+		_ = c.writeMissing(ctx, key)
+		return Item[T]{}, false, nil
+	}
+
+	// Kegagalan simpan sudah dicatat di write, data tetap dikembalikan
+	tag, _ := c.write(ctx, key, value, time.Since(start))
+	return Item[T]{Value: value, ETag: tag}, true, nil
+}
+
+// refreshAsync memperbarui key di background; hanya satu refresh per key per
+// proses, dan (jika ada Locker) per cluster.
+func (c *typedCache[T]) refreshAsync(ctx context.Context, key string, load LoadFunc[T]) {
+	refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.lockTTL)
+	go func() {
+		defer cancel()
+		_, _, _ = c.group.Do("refresh:"+key, func() (interface{}, error) {
+			if c.locker != nil {
+				release, acquired, err := c.locker.Acquire(refreshCtx, c.key(key)+":lock", c.lockTTL)
+				if err != nil || !acquired {
+					return nil, err
+				}
+				defer release()
+			}
+			c.metrics.Record(c.namespace, OpRefresh, OutcomeOK, 0)
+			if _, _, err := c.load(refreshCtx, key, load); err != nil {
+				log.Printf("⚠️ Cache %s gagal refresh key %q: %v", c.namespace, key, err)
+			}
+			return nil, nil
+		})
+	}()
+}
+
+// shouldRefreshEarly mengimplementasikan XFetch:
+// refresh jika now - delta*beta*ln(rand) >= expiry
+func (c *typedCache[T]) shouldRefreshEarly(e entry, now int64) bool {
+	if c.beta <= 0 || e.Delta <= 0 {
+		return false
+	}
+	gap := -float64(e.Delta) * c.beta * math.Log(rand.Float64())
+	return float64(now)+gap >= float64(e.ExpiresAt)
+}
+
+// read mengambil dan men-decode entry, dari L1 lebih dulu jika aktif.
+// Entry rusak, format lama, atau skema tipe yang berbeda dianggap miss dan dibuang.
+func (c *typedCache[T]) read(ctx context.Context, key string) (entry, T, bool, error) {
+	var e entry
+	var value T
+
+	if c.local != nil {
+		if le, ok := c.local.get(c.key(key)); ok {
+			return entry{ExpiresAt: le.expiresAt, Delta: le.delta, Missing: le.missing, Tag: le.tag}, le.value, true, nil
+		}
+	}
+
+	data, ok, err := c.store.Get(ctx, c.key(key))
+	if err != nil || !ok {
+		return e, value, false, err
+	}
+
+	start := time.Now()
+	e, err = unmarshalEntry(data)
+	if err == nil && !e.Missing && e.Schema != c.schema {
+		err = errVersionMismatch
+	}
+	if err == nil && !e.Missing && len(e.Value) == 0 {
+		err = errors.New("entry tanpa nilai")
+	}
+	if err == nil && !e.Missing {
+		err = decodeValue(e.Codec, e.Value, &value)
+	}
+	if err != nil {
+		if errors.Is(err, errVersionMismatch) {
+			// Bukan kerusakan: entry ditulis versi aplikasi lain, cukup dibuang
+			c.metrics.Record(c.namespace, OpDecode, OutcomeEvicted, time.Since(start))
+		} else {
+			c.fail(OpDecode, key, err, start)
+		}
+		_ = c.store.Delete(ctx, c.key(key))
+		var zero T
+		return entry{}, zero, false, nil
+	}
+
+	c.setLocal(key, value, e)
+	return e, value, true, nil
+}
+
+// setLocal menyimpan entry ke L1; umur L1 tidak melebihi sisa umur entry di Store
+func (c *typedCache[T]) setLocal(key string, value T, e entry) {
+	if c.local == nil {
+		return
+	}
+	ttl := c.localTTL
+	end := time.UnixMilli(e.ExpiresAt)
+	if !e.Missing {
+		end = end.Add(c.staleTTL)
+	}
+	if remaining := time.Until(end); remaining < ttl {
+		ttl = remaining
+	}
+	if ttl <= 0 {
+		return
+	}
+	c.local.set(c.key(key), localEntry[T]{value: value, expiresAt: e.ExpiresAt, delta: e.Delta, missing: e.Missing, tag: e.Tag}, ttl)
+}
+
+// write menyimpan nilai beserta ETag-nya dan mengembalikan ETag tersebut
+// (tetap diisi walaupun penyimpanan ke Store gagal)
+func (c *typedCache[T]) write(ctx context.Context, key string, value T, delta time.Duration) (string, error) {
+	start := time.Now()
+
+	e := entry{ExpiresAt: start.Add(c.ttl).UnixMilli(), Delta: delta.Milliseconds(), Codec: c.codec, Schema: c.schema}
+	raw, err := encodeValue(c.codec, value)
+	if err == nil {
+		e.Value = raw
+		e.Tag, err = valueTag(c.codec, raw, value)
+	}
+	if err == nil {
+		raw, err = e.marshal(c.compressMin)
+	}
+	if err != nil {
+		c.fail(OpEncode, key, err, start)
+		return "", err
+	}
+
+	if err := c.store.Set(ctx, c.key(key), raw, c.ttl+c.staleTTL); err != nil {
+		c.fail(OpSet, key, err, start)
+		return e.Tag, err
+	}
+	c.setLocal(key, value, e)
+
+	c.metrics.Record(c.namespace, OpSet, OutcomeOK, time.Since(start))
+	return e.Tag, nil
+}
+
+// writeMissing menyimpan entry negatif selama NegativeTTL. Entry ini tidak punya
+// masa stale: setelah habis, pemanggil berikutnya langsung memuat ulang.
+func (c *typedCache[T]) writeMissing(ctx context.Context, key string) error {
+	if c.negTTL <= 0 {
+		return nil
+	}
+	start := time.Now()
+
+	e := entry{ExpiresAt: start.Add(c.negTTL).UnixMilli(), Missing: true, Schema: c.schema}
+	raw, err := e.marshal(0)
+	if err != nil {
+		c.fail(OpEncode, key, err, start)
+		return err
+	}
+	if err := c.store.Set(ctx, c.key(key), raw, c.negTTL); err != nil {
+		c.fail(OpSet, key, err, start)
+		return err
+	}
+	var zero T
+	c.setLocal(key, zero, e)
+
+	c.metrics.Record(c.namespace, OpSet, OutcomeNegative, time.Since(start))
+	return nil
+}
+
+// fail mencatat error cache secara seragam (log + metrics)
+func (c *typedCache[T]) fail(op, key string, err error, start time.Time) {
+	log.Printf("⚠️ Cache %s gagal %s key %q: %v", c.namespace, op, key, err)
+	c.metrics.Record(c.namespace, op, OutcomeError, time.Since(start))
+}
+
+func logSpan(span opentracing.Span, outcome string) {
+	if span != nil {
+		span.LogFields(otlog.String("cache", outcome))
+	}
+}
+
+type noopCache[T any] struct{}
+
+// NewNoop mengembalikan Cache[T] yang selalu miss, dipakai saat cache tidak aktif
+func NewNoop[T any]() Cache[T] {
+	return noopCache[T]{}
+}
+
+func (noopCache[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	var zero T
+	return zero, false, nil
+}
+
+func (noopCache[T]) Set(ctx context.Context, key string, value T) error { return nil }
+
+func (noopCache[T]) Delete(ctx context.Context, keys ...string) error { return nil }
+
+func (noopCache[T]) Fetch(ctx context.Context, key string, load LoadFunc[T]) (T, bool, error) {
+	return load(ctx)
+}
+
+// FetchItem menghitung ETag langsung dari hasil load karena tidak ada yang disimpan;
+// hasilnya sama dengan ETag cache aktif untuk nilai yang sama (lihat valueTag)
+func (noopCache[T]) FetchItem(ctx context.Context, key string, load LoadFunc[T]) (Item[T], bool, error) {
+	value, found, err := load(ctx)
+	if err != nil || !found {
+		return Item[T]{}, false, err
+	}
+	item := Item[T]{Value: value}
+	if raw, err := json.Marshal(value); err == nil {
+		item.ETag, _ = valueTag(CodecJSON, raw, value)
+	}
+	return item, true, nil
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DependencyIndex mencatat entry cache mana yang menyimpan salinan data lain,
+// mis. "repository:12" menyimpan data user 5 sehingga bergantung pada "user:5".
+// Ref berformat "<namespace>:<key>".
+type DependencyIndex interface {
+	Add(ctx context.Context, dependency string, refs ...string) error
+	// Pop mengembalikan semua ref untuk dependency lalu menghapus catatannya
+	Pop(ctx context.Context, dependency string) ([]string, error)
+}
+
+// Ref membentuk ref "<namespace>:<key>" untuk DependencyIndex
+func Ref(namespace, key string) string {
+	return namespace + ":" + key
+}
+
+// SplitRef memecah ref menjadi namespace dan key
+func SplitRef(ref string) (namespace, key string, ok bool) {
+	return strings.Cut(ref, ":")
+}
+
+// RedisDependencyIndex menyimpan dependency sebagai Redis set dengan TTL
+type RedisDependencyIndex struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisDependencyIndex membuat index dengan key "<prefix><dependency>".
+// ttl sebaiknya sama dengan TTL entry yang paling lama disimpan.
+func NewRedisDependencyIndex(client redis.UniversalClient, prefix string, ttl time.Duration) *RedisDependencyIndex {
+	return &RedisDependencyIndex{client: client, prefix: prefix, ttl: ttl}
+}
+
+func (d *RedisDependencyIndex) Add(ctx context.Context, dependency string, refs ...string) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	members := make([]interface{}, len(refs))
+	for i, r := range refs {
+		members[i] = r
+	}
+
+	key := d.prefix + dependency
+	pipe := d.client.Pipeline()
+	pipe.SAdd(ctx, key, members...)
+	if d.ttl > 0 {
+		pipe.Expire(ctx, key, d.ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (d *RedisDependencyIndex) Pop(ctx context.Context, dependency string) ([]string, error) {
+	key := d.prefix + dependency
+	pipe := d.client.TxPipeline()
+	members := pipe.SMembers(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return members.Val(), nil
+}
+
+// MemoryDependencyIndex adalah DependencyIndex in-memory untuk backend LRU
+type MemoryDependencyIndex struct {
+	mu   sync.Mutex
+	deps map[string]map[string]struct{}
+}
+
+func NewMemoryDependencyIndex() *MemoryDependencyIndex {
+	return &MemoryDependencyIndex{deps: make(map[string]map[string]struct{})}
+}
+
+func (d *MemoryDependencyIndex) Add(ctx context.Context, dependency string, refs ...string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	set, ok := d.deps[dependency]
+	if !ok {
+		set = make(map[string]struct{}, len(refs))
+		d.deps[dependency] = set
+	}
+	for _, r := range refs {
+		set[r] = struct{}{}
+	}
+	return nil
+}
+
+func (d *MemoryDependencyIndex) Pop(ctx context.Context, dependency string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	set := d.deps[dependency]
+	delete(d.deps, dependency)
+
+	refs := make([]string, 0, len(set))
+	for r := range set {
+		refs = append(refs, r)
+	}
+	return refs, nil
+}
+
+type noopDependencyIndex struct{}
+
+// NewNoopDependencyIndex mengembalikan DependencyIndex yang tidak mencatat apa pun
+func NewNoopDependencyIndex() DependencyIndex {
+	return noopDependencyIndex{}
+}
+
+func (noopDependencyIndex) Add(ctx context.Context, dependency string, refs ...string) error {
+	return nil
+}
+
+func (noopDependencyIndex) Pop(ctx context.Context, dependency string) ([]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,225 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Encoding nilai di dalam envelope
+const (
+	CodecJSON    = "json"
+	CodecMsgpack = "msgpack"
+)
+
+// envelopeFormat dinaikkan jika layout biner envelope berubah
+const envelopeFormat byte = 1
+
+const (
+	flagMissing byte = 1 << iota
+	flagMsgpack
+	flagGzip
+)
+
+// headerSize = format(1) + flags(1) + schema(4) + expiresAt(8) + delta(4) + panjang tag(1)
+const headerSize = 1 + 1 + 4 + 8 + 4 + 1
+
+// errVersionMismatch berarti entry ditulis oleh format envelope atau skema tipe yang
+// berbeda (mis. sebelum field entity berubah); entry seperti ini dianggap miss dan dibuang.
+var errVersionMismatch = errors.New("versi entry cache tidak cocok")
+
+// entry adalah isi satu key di Store. ExpiresAt adalah batas segar (TTL);
+// entry tetap disimpan sampai ExpiresAt+StaleTTL. Delta adalah lama load terakhir.
+// Missing menandai entry negatif: sentinel "data tidak ada" tanpa Value.
+// Tag adalah ETag dari Value; Schema adalah sidik tipe nilai (lihat schemaOf).
+//
+// Layout biner: header tetap, tag, lalu Value yang di-encode dengan Codec dan
+// opsional di-gzip. Value di struct ini selalu sudah didekompresi.
+type entry struct {
+	Value     []byte
+	ExpiresAt int64 // unix milli
+	Delta     int64 // milli
+	Missing   bool
+	Tag       string
+	Codec     string
+	Schema    uint32
+}
+
+// marshal meng-gzip Value jika ukurannya minimal compressMin byte (0 = tanpa kompresi)
+func (e entry) marshal(compressMin int) ([]byte, error) {
+	if len(e.Tag) > 255 {
+		return nil, fmt.Errorf("tag terlalu panjang: %d", len(e.Tag))
+	}
+
+	var flags byte
+	if e.Missing {
+		flags |= flagMissing
+	}
+	if e.Codec == CodecMsgpack {
+		flags |= flagMsgpack
+	}
+	payload := e.Value
+	if compressMin > 0 && len(payload) >= compressMin {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(payload); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		// Simpan terkompresi hanya jika memang lebih kecil
+		if buf.Len() < len(payload) {
+			payload = buf.Bytes()
+			flags |= flagGzip
+		}
+	}
+
+	out := make([]byte, headerSize, headerSize+len(e.Tag)+len(payload))
+	out[0] = envelopeFormat
+	out[1] = flags
+	binary.BigEndian.PutUint32(out[2:6], e.Schema)
+	binary.BigEndian.PutUint64(out[6:14], uint64(e.ExpiresAt))
+	binary.BigEndian.PutUint32(out[14:18], uint32(min(max(e.Delta, 0), int64(^uint32(0)))))
+	out[18] = byte(len(e.Tag))
+	out = append(out, e.Tag...)
+	return append(out, payload...), nil
+}
+
+// unmarshalEntry membaca envelope; format lain (termasuk entry JSON lama) menghasilkan errVersionMismatch
+func unmarshalEntry(data []byte) (entry, error) {
+	if len(data) < headerSize || data[0] != envelopeFormat {
+		return entry{}, errVersionMismatch
+	}
+	flags := data[1]
+	e := entry{
+		Schema:    binary.BigEndian.Uint32(data[2:6]),
+		ExpiresAt: int64(binary.BigEndian.Uint64(data[6:14])),
+		Delta:     int64(binary.BigEndian.Uint32(data[14:18])),
+		Missing:   flags&flagMissing != 0,
+		Codec:     CodecJSON,
+	}
+	if flags&flagMsgpack != 0 {
+		e.Codec = CodecMsgpack
+	}
+
+	tagLen := int(data[18])
+	if len(data) < headerSize+tagLen {
+		return entry{}, errors.New("entry cache terpotong")
+	}
+	e.Tag = string(data[headerSize : headerSize+tagLen])
+	payload := data[headerSize+tagLen:]
+
+	if flags&flagGzip != 0 {
+		zr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return entry{}, err
+		}
+		if payload, err = io.ReadAll(zr); err != nil {
+			return entry{}, err
+		}
+	}
+	if len(payload) > 0 {
+		e.Value = payload
+	}
+	return e, nil
+}
+
+// encodeValue meng-encode nilai dengan codec. MessagePack memakai tag json
+// supaya nama field sama dengan respons API.
+func encodeValue(codec string, v interface{}) ([]byte, error) {
+	if codec != CodecMsgpack {
+		return json.Marshal(v)
+	}
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// valueTag menghitung ETag dari representasi JSON nilai apa pun codec-nya, sehingga
+// ETag tidak berubah saat CACHE_CODEC diganti atau saat cache diganti noopCache.
+// raw adalah hasil encodeValue dan dipakai ulang jika codec-nya JSON.
+func valueTag(codec string, raw []byte, v interface{}) (string, error) {
+	if codec != CodecMsgpack {
+		return ETag(raw), nil
+	}
+	jsonRaw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return ETag(jsonRaw), nil
+}
+
+func decodeValue(codec string, data []byte, v interface{}) error {
+	if codec != CodecMsgpack {
+		return json.Unmarshal(data, v)
+	}
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+// schemaOf menghitung sidik struktur tipe (nama field, tipe, tag) sehingga
+// perubahan entity otomatis membuat entry lama tidak cocok lagi
+func schemaOf(t reflect.Type) uint32 {
+	h := fnv.New32a()
+	writeSchema(h, t, map[reflect.Type]bool{})
+	return h.Sum32()
+}
+
+func writeSchema(w io.Writer, t reflect.Type, seen map[reflect.Type]bool) {
+	if t == nil {
+		io.WriteString(w, "nil")
+		return
+	}
+	// Tipe dengan encoding sendiri (mis. time.Time) dianggap atomik
+	if t.PkgPath() != "" && (t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType)) {
+		io.WriteString(w, t.PkgPath()+"."+t.Name())
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		io.WriteString(w, t.Kind().String()+"[")
+		writeSchema(w, t.Elem(), seen)
+		io.WriteString(w, "]")
+	case reflect.Map:
+		io.WriteString(w, "map[")
+		writeSchema(w, t.Key(), seen)
+		io.WriteString(w, "]")
+		writeSchema(w, t.Elem(), seen)
+	case reflect.Struct:
+		if seen[t] {
+			io.WriteString(w, "ref:"+t.String())
+			return
+		}
+		seen[t] = true
+		io.WriteString(w, "struct{")
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			io.WriteString(w, f.Name+" "+f.Tag.Get("json")+" ")
+			writeSchema(w, f.Type, seen)
+			io.WriteString(w, ";")
+		}
+		io.WriteString(w, "}")
+	default:
+		io.WriteString(w, t.Kind().String())
+	}
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
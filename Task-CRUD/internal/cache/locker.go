@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Locker adalah lock terdistribusi berumur pendek untuk mencegah beberapa
+// replica memuat key yang sama bersamaan.
+type Locker interface {
+	// Acquire mencoba mengambil lock tanpa menunggu. acquired=false berarti
+	// lock sedang dipegang pihak lain.
+	Acquire(ctx context.Context, key string, ttl time.Duration) (release func(), acquired bool, err error)
+}
+
+// releaseScript hanya menghapus lock jika token masih milik pemanggil
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker mengimplementasikan Locker dengan SET NX PX
+type RedisLocker struct {
+	client redis.UniversalClient
+}
+
+func NewRedisLocker(client redis.UniversalClient) *RedisLocker {
+	return &RedisLocker{client: client}
+}
+
+func (l *RedisLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, false, err
+	}
+	token := hex.EncodeToString(buf)
+
+	acquired, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !acquired {
+		return nil, false, err
+	}
+
+	release := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := releaseScript.Run(ctx, l.client, []string{key}, token).Err(); err != nil {
+			log.Printf("⚠️ Gagal melepas lock cache %q: %v", key, err)
+		}
+	}
+	return release, true, nil
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru adalah map terbatas dengan TTL per item; item yang paling lama tidak
+// dipakai dibuang saat kapasitas penuh. Aman untuk goroutine.
+type lru[V any] struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // depan = paling baru dipakai
+}
+
+type lruItem[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+func newLRU[V any](capacity int) *lru[V] {
+	return &lru[V]{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (l *lru[V]) get(key string) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var zero V
+	el, ok := l.items[key]
+	if !ok {
+		return zero, false
+	}
+	item := el.Value.(*lruItem[V])
+	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+		l.remove(el)
+		return zero, false
+	}
+	l.order.MoveToFront(el)
+	return item.value, true
+}
+
+func (l *lru[V]) set(key string, value V, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if el, ok := l.items[key]; ok {
+		item := el.Value.(*lruItem[V])
+		item.value = value
+		item.expiresAt = expiresAt
+		l.order.MoveToFront(el)
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&lruItem[V]{key: key, value: value, expiresAt: expiresAt})
+	for l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+	}
+}
+
+func (l *lru[V]) delete(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := l.items[key]; ok {
+			l.remove(el)
+		}
+	}
+}
+
+func (l *lru[V]) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.items = make(map[string]*list.Element)
+	l.order.Init()
+}
+
+// keys mengembalikan key yang belum kedaluwarsa dan cocok dengan match
+func (l *lru[V]) keys(match func(key string) bool) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	var out []string
+	for key, el := range l.items {
+		item := el.Value.(*lruItem[V])
+		if !item.expiresAt.IsZero() && now.After(item.expiresAt) {
+			continue
+		}
+		if match(key) {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+// ttl mengembalikan sisa umur key (0 jika tanpa kedaluwarsa)
+func (l *lru[V]) ttl(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.items[key]
+	if !ok {
+		return 0, false
+	}
+	item := el.Value.(*lruItem[V])
+	if item.expiresAt.IsZero() {
+		return 0, true
+	}
+	remaining := time.Until(item.expiresAt)
+	if remaining <= 0 {
+		return 0, false
+	}
+	return remaining, true
+}
+
+func (l *lru[V]) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+func (l *lru[V]) remove(el *list.Element) {
+	l.order.Remove(el)
+	delete(l.items, el.Value.(*lruItem[V]).key)
+}
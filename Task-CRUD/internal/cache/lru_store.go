@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// LRUStore adalah Store in-memory dengan kapasitas terbatas dan TTL per entry.
+// Entry yang paling lama tidak dipakai dibuang saat kapasitas penuh.
+type LRUStore struct {
+	items *lru[[]byte]
+}
+
+func NewLRUStore(capacity int) *LRUStore {
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	return &LRUStore{items: newLRU[[]byte](capacity)}
+}
+
+func (s *LRUStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok := s.items.get(key)
+	return value, ok, nil
+}
+
+func (s *LRUStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	// Salin supaya pemanggil tidak bisa mengubah isi cache
+	s.items.set(key, append([]byte(nil), value...), ttl)
+	return nil
+}
+
+func (s *LRUStore) Delete(ctx context.Context, keys ...string) error {
+	s.items.delete(keys...)
+	return nil
+}
+
+func (s *LRUStore) Scan(ctx context.Context, pattern string, fn func(keys []string) error) error {
+	return scanBatches(s.items.keys(func(key string) bool { return matchGlob(pattern, key) }), 100, fn)
+}
+
+func (s *LRUStore) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	ttl, ok := s.items.ttl(key)
+	return ttl, ok, nil
+}
+
+// Len mengembalikan jumlah entry (termasuk yang sudah kedaluwarsa tapi belum dibuang)
+func (s *LRUStore) Len() int {
+	return s.items.len()
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Operasi dan hasil yang dicatat oleh Metrics
+const (
+	OpGet     = "get"
+	OpSet     = "set"
+	OpDelete  = "delete"
+	OpEncode  = "encode"
+	OpDecode  = "decode"
+	OpLoad    = "load"
+	OpRefresh = "refresh"
+	OpLock    = "lock"
+	OpPublish = "publish"
+
+	OutcomeHit       = "hit"
+	OutcomeMiss      = "miss"
+	OutcomeStale     = "stale"     // entry basi dikirim sambil di-refresh
+	OutcomeCoalesced = "coalesced" // pemanggil ikut menunggu load yang sudah berjalan
+	OutcomeNegative  = "negative"  // get: entry negatif ditemukan; set: entry negatif disimpan
+	OutcomeEvicted   = "evicted"   // entry dengan versi/skema lain dibuang saat dibaca
+	OutcomeOK        = "ok"
+	OutcomeError     = "error"
+)
+
+// Metrics menerima setiap pemanggilan cache per namespace
+type Metrics interface {
+	Record(namespace, op, outcome string, latency time.Duration)
+}
+
+// DefaultStats dipakai oleh semua cache yang tidak diberi Metrics sendiri
+var DefaultStats = NewStats()
+
+// NamespaceStats adalah ringkasan statistik satu namespace
+type NamespaceStats struct {
+	Hits         int64         `json:"hits"`
+	Misses       int64         `json:"misses"`
+	Stale        int64         `json:"stale"`
+	NegativeHits int64         `json:"negative_hits"`
+	NegativeSets int64         `json:"negative_sets"`
+	Evicted      int64         `json:"evicted"`
+	Errors       int64         `json:"errors"`
+	Sets         int64         `json:"sets"`
+	Deletes      int64         `json:"deletes"`
+	Loads        int64         `json:"loads"`
+	Refreshes    int64         `json:"refreshes"`
+	Coalesced    int64         `json:"coalesced"`
+	Calls        int64         `json:"calls"`
+	TotalLatency time.Duration `json:"total_latency_ns"`
+	MaxLatency   time.Duration `json:"max_latency_ns"`
+}
+
+// HitRatio mengembalikan rasio hit (termasuk stale dan negatif) terhadap semua get (0 jika belum ada get)
+func (s NamespaceStats) HitRatio() float64 {
+	hits := s.Hits + s.Stale + s.NegativeHits
+	total := hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
+
+// Stats adalah implementasi Metrics in-memory yang aman untuk goroutine
+type Stats struct {
+	mu         sync.Mutex
+	namespaces map[string]*NamespaceStats
+}
+
+func NewStats() *Stats {
+	return &Stats{namespaces: make(map[string]*NamespaceStats)}
+}
+
+func (s *Stats) Record(namespace, op, outcome string, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns, ok := s.namespaces[namespace]
+	if !ok {
+		ns = &NamespaceStats{}
+		s.namespaces[namespace] = ns
+	}
+
+	switch outcome {
+	case OutcomeHit:
+		ns.Hits++
+	case OutcomeMiss:
+		ns.Misses++
+	case OutcomeStale:
+		ns.Stale++
+	case OutcomeCoalesced:
+		ns.Coalesced++
+	case OutcomeNegative:
+		if op == OpSet {
+			ns.NegativeSets++
+		} else {
+			ns.NegativeHits++
+		}
+	case OutcomeEvicted:
+		ns.Evicted++
+	case OutcomeError:
+		ns.Errors++
+	}
+	if outcome == OutcomeOK {
+		switch op {
+		case OpSet:
+			ns.Sets++
+		case OpDelete:
+			ns.Deletes++
+		case OpLoad:
+			ns.Loads++
+		case OpRefresh:
+			ns.Refreshes++
+		}
+	}
+
+	// Latency hanya untuk pemanggilan backend, bukan event turunan (load/refresh/coalesced)
+	if op == OpGet || op == OpSet || op == OpDelete {
+		ns.Calls++
+		ns.TotalLatency += latency
+		if latency > ns.MaxLatency {
+			ns.MaxLatency = latency
+		}
+	}
+}
+
+// Snapshot mengembalikan salinan statistik semua namespace
+func (s *Stats) Snapshot() map[string]NamespaceStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]NamespaceStats, len(s.namespaces))
+	for name, ns := range s.namespaces {
+		out[name] = *ns
+	}
+	return out
+}
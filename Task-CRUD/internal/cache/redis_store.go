@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore menyimpan entry cache di Redis (standalone, sentinel atau cluster)
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if _, ok := s.client.(*redis.ClusterClient); ok && len(keys) > 1 {
+		// DEL multi-key di cluster gagal (CROSSSLOT) jika key beda slot:
+		// hapus satu per satu, pipeline membaginya per node
+		pipe := s.client.Pipeline()
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
+
+// Scan memakai SCAN (bukan KEYS) supaya Redis tidak terblokir pada keyspace besar.
+// Di cluster, SCAN hanya melihat satu node sehingga dijalankan di setiap master.
+func (s *RedisStore) Scan(ctx context.Context, pattern string, fn func(keys []string) error) error {
+	masters := []redis.Cmdable{s.client}
+	if cluster, ok := s.client.(*redis.ClusterClient); ok {
+		nodes, err := clusterMasters(ctx, cluster)
+		if err != nil {
+			return err
+		}
+		masters = nodes
+	}
+
+	// fn tidak aman dipanggil paralel, jadi node dipindai berurutan
+	for _, node := range masters {
+		if err := scanNode(ctx, node, pattern, fn); err != nil {
+			if errors.Is(err, ErrStopScan) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func clusterMasters(ctx context.Context, cluster *redis.ClusterClient) ([]redis.Cmdable, error) {
+	var masters []redis.Cmdable
+	var mu sync.Mutex
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		mu.Lock()
+		masters = append(masters, node)
+		mu.Unlock()
+		return nil
+	})
+	return masters, err
+}
+
+// scanNode memindai satu node dan mengembalikan ErrStopScan apa adanya supaya
+// pemindaian cluster ikut berhenti
+func scanNode(ctx context.Context, client redis.Cmdable, pattern string, fn func(keys []string) error) error {
+	const batchSize = 100
+
+	iter := client.Scan(ctx, 0, pattern, batchSize).Iterator()
+	batch := make([]string, 0, batchSize)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) < batchSize {
+			continue
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return fn(batch)
+}
+
+func (s *RedisStore) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	ttl, err := s.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, false, err
+	}
+	// go-redis meneruskan nilai khusus PTTL apa adanya: -2 key tidak ada, -1 tanpa kedaluwarsa
+	switch ttl {
+	case -2:
+		return 0, false, nil
+	case -1:
+		return 0, true, nil
+	}
+	return ttl, true, nil
+}
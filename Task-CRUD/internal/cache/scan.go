@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path"
+	"time"
+)
+
+// ErrStopScan dikembalikan fn untuk menghentikan Scan lebih awal tanpa error
+var ErrStopScan = errors.New("scan dihentikan")
+
+// Scanner adalah operasi administrasi opsional pada Store
+type Scanner interface {
+	// Scan memanggil fn per batch key yang cocok dengan pattern glob gaya Redis
+	Scan(ctx context.Context, pattern string, fn func(keys []string) error) error
+	// TTL mengembalikan sisa umur key; ok=false jika key tidak ada, 0 jika tanpa kedaluwarsa
+	TTL(ctx context.Context, key string) (ttl time.Duration, ok bool, err error)
+}
+
+// EntryInfo adalah isi entry cache yang sudah dibongkar untuk keperluan admin
+type EntryInfo struct {
+	Key        string          `json:"key"`
+	TTLMs      int64           `json:"ttl_ms"`      // sisa umur di Store (0 = tanpa kedaluwarsa)
+	FreshUntil time.Time       `json:"fresh_until"` // setelah ini entry basi (stale)
+	Stale      bool            `json:"stale"`
+	Negative   bool            `json:"negative"`
+	ETag       string          `json:"etag,omitempty"`
+	LoadMs     int64           `json:"load_ms"`    // lama load terakhir
+	Size       int             `json:"size_bytes"` // ukuran di Store (setelah kompresi)
+	Codec      string          `json:"codec,omitempty"`
+	Value      json.RawMessage `json:"value,omitempty"`
+}
+
+// Describe membongkar data mentah dari Store menjadi EntryInfo
+func Describe(key string, data []byte, ttl time.Duration) (EntryInfo, error) {
+	e, err := unmarshalEntry(data)
+	if err != nil {
+		return EntryInfo{}, err
+	}
+	value := json.RawMessage(e.Value)
+	if e.Codec == CodecMsgpack && len(e.Value) > 0 {
+		// Ditampilkan sebagai JSON supaya bisa dibaca dari endpoint admin
+		var v interface{}
+		if err := decodeValue(e.Codec, e.Value, &v); err != nil {
+			return EntryInfo{}, err
+		}
+		if value, err = json.Marshal(v); err != nil {
+			return EntryInfo{}, err
+		}
+	}
+	freshUntil := time.UnixMilli(e.ExpiresAt)
+	return EntryInfo{
+		Key:        key,
+		TTLMs:      ttl.Milliseconds(),
+		FreshUntil: freshUntil,
+		Stale:      !e.Missing && time.Now().After(freshUntil),
+		Negative:   e.Missing,
+		ETag:       e.Tag,
+		LoadMs:     e.Delta,
+		Size:       len(data),
+		Codec:      e.Codec,
+		Value:      value,
+	}, nil
+}
+
+// scanBatches membagi keys menjadi batch dan berhenti saat fn mengembalikan ErrStopScan
+func scanBatches(keys []string, size int, fn func(keys []string) error) error {
+	for start := 0; start < len(keys); start += size {
+		end := min(start+size, len(keys))
+		if err := fn(keys[start:end]); err != nil {
+			if errors.Is(err, ErrStopScan) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// matchGlob mencocokkan key dengan pattern glob (*, ?, [..]) untuk store in-memory
+func matchGlob(pattern, key string) bool {
+	ok, err := path.Match(pattern, key)
+	return err == nil && ok
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Switch adalah Cache[T] yang implementasinya bisa diganti saat runtime,
+// mis. dari noop ke Redis setelah Redis kembali tersedia.
+type Switch[T any] struct {
+	current atomic.Pointer[Cache[T]]
+}
+
+func NewSwitch[T any](initial Cache[T]) *Switch[T] {
+	s := &Switch[T]{}
+	s.Use(initial)
+	return s
+}
+
+// Use mengganti implementasi; pemanggilan yang sedang berjalan tetap memakai yang lama
+func (s *Switch[T]) Use(c Cache[T]) {
+	s.current.Store(&c)
+}
+
+func (s *Switch[T]) cache() Cache[T] {
+	return *s.current.Load()
+}
+
+func (s *Switch[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	return s.cache().Get(ctx, key)
+}
+
+func (s *Switch[T]) Set(ctx context.Context, key string, value T) error {
+	return s.cache().Set(ctx, key, value)
+}
+
+func (s *Switch[T]) Delete(ctx context.Context, keys ...string) error {
+	return s.cache().Delete(ctx, keys...)
+}
+
+func (s *Switch[T]) Fetch(ctx context.Context, key string, load LoadFunc[T]) (T, bool, error) {
+	return s.cache().Fetch(ctx, key, load)
+}
+
+func (s *Switch[T]) FetchItem(ctx context.Context, key string, load LoadFunc[T]) (Item[T], bool, error) {
+	return s.cache().FetchItem(ctx, key, load)
+}
+
+// SwitchDependencyIndex adalah DependencyIndex yang implementasinya bisa diganti saat runtime
+type SwitchDependencyIndex struct {
+	current atomic.Pointer[DependencyIndex]
+}
+
+func NewSwitchDependencyIndex(initial DependencyIndex) *SwitchDependencyIndex {
+	s := &SwitchDependencyIndex{}
+	s.Use(initial)
+	return s
+}
+
+func (s *SwitchDependencyIndex) Use(d DependencyIndex) {
+	s.current.Store(&d)
+}
+
+func (s *SwitchDependencyIndex) Add(ctx context.Context, dependency string, refs ...string) error {
+	return (*s.current.Load()).Add(ctx, dependency, refs...)
+}
+
+func (s *SwitchDependencyIndex) Pop(ctx context.Context, dependency string) ([]string, error) {
+	return (*s.current.Load()).Pop(ctx, dependency)
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Operasi DBChange, sama dengan TG_OP di trigger PostgreSQL
+const (
+	DBChangeInsert = "INSERT"
+	DBChangeUpdate = "UPDATE"
+	DBChangeDelete = "DELETE"
+)
+
+// DBChange adalah perubahan baris users/repositories yang ditulis di luar
+// service (migrasi, psql, service lain). Baris diisi oleh trigger database
+// lalu diproses oleh ChangeFeed; OldData/NewData adalah to_jsonb baris
+// sebelum/sesudah perubahan.
+type DBChange struct {
+	ID          uint64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	Table       string          `gorm:"column:table_name;type:varchar(100);not null" json:"table_name"` // users | repositories
+	Operation   string          `gorm:"type:varchar(10);not null" json:"operation"`                     // INSERT | UPDATE | DELETE
+	RowID       uint64          `gorm:"not null" json:"row_id"`
+	OldData     json.RawMessage `gorm:"type:jsonb" json:"old_data,omitempty"`
+	NewData     json.RawMessage `gorm:"type:jsonb" json:"new_data,omitempty"`
+	Origin      string          `gorm:"type:varchar(255)" json:"origin"` // application_name sesi penulis
+	ChangedAt   time.Time       `gorm:"not null;default:now()" json:"changed_at"`
+	ProcessedAt *time.Time      `gorm:"index" json:"processed_at,omitempty"`
+}
+
+// TableName explicitly sets the table name to "db_changes"
+func (DBChange) TableName() string {
+	return "db_changes"
+}
@@ -0,0 +1,6 @@
+package entity
+
+import "errors"
+
+// ErrNotFound is returned when the requested user or repository does not exist.
+var ErrNotFound = errors.New("data tidak ditemukan")
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+)
+
+// OutboxEvent adalah event yang ditulis di transaksi yang sama dengan perubahan
+// entity, lalu dikirim ke Kafka oleh relay. Baris yang sudah terkirim diberi
+// PublishedAt dan dibersihkan setelah masa retensi.
+type OutboxEvent struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	AggregateType string     `gorm:"type:varchar(50);not null;index:idx_outbox_aggregate,priority:1" json:"aggregate_type"` // mis. "repository"
+	AggregateID   string     `gorm:"type:varchar(100);not null;index:idx_outbox_aggregate,priority:2" json:"aggregate_id"`
+	Topic         string     `gorm:"type:varchar(255);not null" json:"topic"`
+	EventType     string     `gorm:"type:varchar(255)" json:"event_type"` // dikirim di header ce_type
+	Key           string     `gorm:"type:varchar(255)" json:"key"`        // key pesan Kafka
+	Payload       []byte     `gorm:"type:bytea;not null" json:"payload"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `gorm:"not null;index" json:"next_attempt_at"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	PublishedAt   *time.Time `gorm:"index" json:"published_at,omitempty"`
+}
+
+// TableName explicitly sets the table name to "outbox"
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
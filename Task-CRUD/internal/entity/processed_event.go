@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+)
+
+// ProcessedEvent menandai event yang sudah diproses oleh satu consumer group.
+// Ditulis di transaksi yang sama dengan perubahan handler sehingga event yang
+// dikirim ulang Kafka tidak menjalankan side effect dua kali.
+type ProcessedEvent struct {
+	ConsumerGroup string    `gorm:"type:varchar(255);primaryKey" json:"consumer_group"`
+	EventID       string    `gorm:"type:varchar(100);primaryKey" json:"event_id"`
+	EventType     string    `gorm:"type:varchar(255)" json:"event_type"`
+	ProcessedAt   time.Time `gorm:"autoCreateTime;index" json:"processed_at"`
+}
+
+// TableName explicitly sets the table name to "processed_events"
+func (ProcessedEvent) TableName() string {
+	return "processed_events"
+}
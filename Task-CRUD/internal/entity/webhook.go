@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"time"
+)
+
+// Webhook adalah langganan pihak luar atas event repository/user. Event yang
+// cocok dengan EventTypes dikirim ke URL dengan tanda tangan HMAC-SHA256 dari Secret.
+type Webhook struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	URL         string     `gorm:"type:varchar(2048);not null" json:"url"`
+	EventTypes  []string   `gorm:"type:text;serializer:json" json:"event_types"` // kosong atau "*" = semua event
+	Secret      string     `gorm:"type:varchar(255);not null" json:"secret,omitempty"`
+	Active      bool       `gorm:"not null;default:true" json:"active"`
+	Failures    int        `gorm:"not null;default:0" json:"consecutive_failures"` // gagal berturut-turut; 0 setelah sukses
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+	DisabledWhy string     `gorm:"type:text" json:"disabled_reason,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName explicitly sets the table name to "webhooks"
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Status pengiriman webhook
+const (
+	DeliveryPending   = "pending"   // menunggu dikirim atau dicoba lagi
+	DeliverySucceeded = "succeeded" // endpoint membalas 2xx
+	DeliveryFailed    = "failed"    // percobaan habis
+)
+
+// WebhookDelivery adalah satu event untuk satu webhook beserta log percobaan
+// terakhirnya. Redeliver membuat baris baru dengan RedeliveryOf berisi ID asal.
+// Selama webhook nonaktif, delivery pending ditahan dan lanjut saat diaktifkan lagi.
+type WebhookDelivery struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	WebhookID     uint       `gorm:"not null;index;uniqueIndex:idx_webhook_delivery_event,priority:1,where:redelivery_of IS NULL" json:"webhook_id"`
+	EventID       string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_webhook_delivery_event,priority:2,where:redelivery_of IS NULL" json:"event_id"`
+	EventType     string     `gorm:"type:varchar(255);not null" json:"event_type"`
+	Payload       []byte     `gorm:"type:bytea;not null" json:"-"`
+	RedeliveryOf  *uint64    `json:"redelivery_of,omitempty"`
+	Status        string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index" json:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	LockedUntil   *time.Time `gorm:"index" json:"-"` // lease dispatcher; delivery tidak diklaim replica lain sebelum lewat
+
+	// Log percobaan terakhir
+	RequestURL      string `gorm:"type:varchar(2048)" json:"request_url,omitempty"`
+	RequestHeaders  string `gorm:"type:text" json:"request_headers,omitempty"` // JSON
+	RequestBody     string `gorm:"type:text" json:"request_body,omitempty"`
+	ResponseStatus  int    `json:"response_status,omitempty"`
+	ResponseHeaders string `gorm:"type:text" json:"response_headers,omitempty"` // JSON
+	ResponseBody    string `gorm:"type:text" json:"response_body,omitempty"`    // dipotong
+	Error           string `gorm:"type:text" json:"error,omitempty"`
+	DurationMs      int64  `json:"duration_ms,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName explicitly sets the table name to "webhook_deliveries"
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
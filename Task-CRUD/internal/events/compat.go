@@ -0,0 +1,150 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// CheckCompatibility membandingkan dua JSON Schema dan mengembalikan daftar
+// perubahan yang memutus consumer lama (backward incompatible):
+//   - property dihapus;
+//   - tipe property dipersempit atau diganti;
+//   - property baru dijadikan required;
+//   - nilai enum dihapus.
+//
+// Menambah property opsional, melonggarkan tipe, atau menambah nilai enum dianggap aman.
+func CheckCompatibility(oldSchema, newSchema []byte) ([]string, error) {
+	var oldNode, newNode map[string]interface{}
+	if err := json.Unmarshal(oldSchema, &oldNode); err != nil {
+		return nil, fmt.Errorf("schema lama tidak valid: %w", err)
+	}
+	if err := json.Unmarshal(newSchema, &newNode); err != nil {
+		return nil, fmt.Errorf("schema baru tidak valid: %w", err)
+	}
+
+	var problems []string
+	compareNode("#", oldNode, newNode, &problems)
+	return problems, nil
+}
+
+func compareNode(path string, oldNode, newNode map[string]interface{}, problems *[]string) {
+	if ref, ok := oldNode["$ref"]; ok && ref != newNode["$ref"] {
+		*problems = append(*problems, fmt.Sprintf("%s: $ref berubah dari %v ke %v", path, ref, newNode["$ref"]))
+	}
+
+	oldTypes, newTypes := typeSet(oldNode["type"]), typeSet(newNode["type"])
+	if len(oldTypes) > 0 {
+		for t := range oldTypes {
+			if len(newTypes) > 0 && !newTypes[t] {
+				*problems = append(*problems, fmt.Sprintf("%s: tipe %q tidak lagi diterima", path, t))
+			}
+		}
+	}
+
+	if oldEnum, ok := oldNode["enum"].([]interface{}); ok {
+		newEnum, _ := newNode["enum"].([]interface{})
+		for _, v := range oldEnum {
+			if _, restricted := newNode["enum"]; restricted && !containsValue(newEnum, v) {
+				*problems = append(*problems, fmt.Sprintf("%s: nilai enum %v dihapus", path, v))
+			}
+		}
+	}
+
+	oldRequired := stringSet(oldNode["required"])
+	for name := range stringSet(newNode["required"]) {
+		if !oldRequired[name] {
+			*problems = append(*problems, fmt.Sprintf("%s: property %q baru menjadi required", path, name))
+		}
+	}
+
+	for _, key := range []string{"properties", "$defs", "definitions"} {
+		oldProps, _ := oldNode[key].(map[string]interface{})
+		newProps, _ := newNode[key].(map[string]interface{})
+		for _, name := range sortedKeys(oldProps) {
+			childPath := path + "/" + key + "/" + name
+			newChild, ok := newProps[name].(map[string]interface{})
+			if !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: dihapus", childPath))
+				continue
+			}
+			if oldChild, ok := oldProps[name].(map[string]interface{}); ok {
+				compareNode(childPath, oldChild, newChild, problems)
+			}
+		}
+	}
+
+	if oldItems, ok := oldNode["items"].(map[string]interface{}); ok {
+		if newItems, ok := newNode["items"].(map[string]interface{}); ok {
+			compareNode(path+"/items", oldItems, newItems, problems)
+		}
+	}
+
+	for _, key := range []string{"oneOf", "anyOf"} {
+		oldAlts, _ := oldNode[key].([]interface{})
+		newAlts, _ := newNode[key].([]interface{})
+		if len(oldAlts) == 0 {
+			continue
+		}
+		// Setiap alternatif lama harus tetap ada (dibandingkan per posisi)
+		if len(newAlts) < len(oldAlts) {
+			*problems = append(*problems, fmt.Sprintf("%s/%s: alternatif dikurangi dari %d ke %d", path, key, len(oldAlts), len(newAlts)))
+			continue
+		}
+		for i := range oldAlts {
+			oldAlt, _ := oldAlts[i].(map[string]interface{})
+			newAlt, _ := newAlts[i].(map[string]interface{})
+			if oldAlt != nil && newAlt != nil {
+				compareNode(fmt.Sprintf("%s/%s/%d", path, key, i), oldAlt, newAlt, problems)
+			}
+		}
+	}
+}
+
+func typeSet(v interface{}) map[string]bool {
+	set := map[string]bool{}
+	switch t := v.(type) {
+	case string:
+		set[t] = true
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				set[s] = true
+			}
+		}
+	}
+	// integer termasuk number, jadi melonggarkan integer -> number aman
+	if set["number"] {
+		set["integer"] = true
+	}
+	return set
+}
+
+func stringSet(v interface{}) map[string]bool {
+	set := map[string]bool{}
+	items, _ := v.([]interface{})
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, item := range values {
+		if fmt.Sprint(item) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,189 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Versi spesifikasi CloudEvents dan content type pesan di Kafka (structured mode)
+const (
+	SpecVersion       = "1.0"
+	ContentType       = "application/cloudevents+json"
+	DataContentType   = "application/json"
+	typePrefix        = "com.taskcrud."
+	defaultSchemaBase = "/events/schemas/"
+	headerContentType = "content-type"
+
+	// TypeHeader membawa tipe event di header Kafka, supaya consumer topic
+	// per aggregate bisa memilih event tanpa membaca payload
+	TypeHeader = "ce_type"
+)
+
+// Source mengisi atribut "source" semua event; bisa diganti lewat EVENT_SOURCE
+var Source = "/task-crud"
+
+// Event adalah envelope CloudEvents 1.0. Data selalu berbentuk Change
+// (before/after) dan dideskripsikan oleh JSON Schema di DataSchema.
+// CorrelationID dan CausationID adalah extension untuk menelusuri sebab-akibat:
+// correlation sama untuk semua event dari satu request, causation adalah ID
+// event/perintah yang memicu event ini.
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	DataSchema      string          `json:"dataschema"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	CausationID     string          `json:"causationid,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// Change adalah isi Data: kondisi entity sebelum dan sesudah perubahan.
+// Before nil untuk event created, After nil untuk event deleted.
+// Changed berisi nama field (JSON) yang berbeda, hanya jika keduanya ada.
+type Change struct {
+	Before  interface{} `json:"before"`
+	After   interface{} `json:"after"`
+	Changed []string    `json:"changed,omitempty"`
+}
+
+// New membuat event untuk tipe yang sudah diregistrasi, mis. New(ctx, "repository.created", "repository/1", nil, repo).
+// Atribut correlation/causation diambil dari context jika ada.
+func New(ctx context.Context, name, subject string, before, after interface{}) (Event, error) {
+	schema, ok := Lookup(name)
+	if !ok {
+		return Event{}, fmt.Errorf("tipe event tidak terdaftar: %s", name)
+	}
+	change := Change{Before: before, After: after}
+	if before != nil && after != nil {
+		var err error
+		if change.Changed, err = changedFields(before, after); err != nil {
+			return Event{}, fmt.Errorf("bandingkan data event %s gagal: %w", name, err)
+		}
+	}
+	data, err := json.Marshal(change)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal data event %s gagal: %w", name, err)
+	}
+
+	return Event{
+		SpecVersion:     SpecVersion,
+		ID:              NewID(),
+		Source:          Source,
+		Type:            schema.Type(),
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: DataContentType,
+		DataSchema:      schema.URI(),
+		CorrelationID:   CorrelationID(ctx),
+		CausationID:     CausationID(ctx),
+		Data:            data,
+	}, nil
+}
+
+// changedFields membandingkan field level atas dari bentuk JSON before dan after
+func changedFields(before, after interface{}) ([]string, error) {
+	var b, a map[string]interface{}
+	if err := remarshal(before, &b); err != nil {
+		return nil, err
+	}
+	if err := remarshal(after, &a); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for k, v := range a {
+		if old, ok := b[k]; !ok || !reflect.DeepEqual(old, v) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+func remarshal(v interface{}, out *map[string]interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// Name mengembalikan nama pendek event (tanpa prefix tipe), mis. "repository.created"
+func (e Event) Name() string {
+	if len(e.Type) > len(typePrefix) && e.Type[:len(typePrefix)] == typePrefix {
+		return e.Type[len(typePrefix):]
+	}
+	return e.Type
+}
+
+// Aggregate mengembalikan tipe dan ID aggregate dari subject, mis. "repository/12" -> ("repository", "12")
+func (e Event) Aggregate() (aggregateType, id string) {
+	aggregateType, id, _ = strings.Cut(e.Subject, "/")
+	return aggregateType, id
+}
+
+// Decode membaca envelope dari value pesan Kafka
+func Decode(raw []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return Event{}, err
+	}
+	if e.SpecVersion != SpecVersion || e.ID == "" || e.Type == "" {
+		return Event{}, fmt.Errorf("bukan CloudEvent %s yang valid", SpecVersion)
+	}
+	return e, nil
+}
+
+// Header mengembalikan pasangan header Kafka yang menandai pesan sebagai CloudEvent terstruktur
+func Header() (string, []byte) {
+	return headerContentType, []byte(ContentType)
+}
+
+// NewID membuat UUID v4 acak untuk atribut id
+func NewID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+type correlationKey struct{}
+type causationKey struct{}
+
+// WithCorrelationID menyimpan correlation ID (mis. dari header X-Request-ID) di context
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID mengembalikan correlation ID dari context, kosong jika tidak ada
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// WithCausationID menyimpan ID event/perintah yang sedang diproses di context
+func WithCausationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, causationKey{}, id)
+}
+
+// CausationID mengembalikan causation ID dari context, kosong jika tidak ada
+func CausationID(ctx context.Context) string {
+	id, _ := ctx.Value(causationKey{}).(string)
+	return id
+}
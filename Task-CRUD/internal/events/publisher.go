@@ -0,0 +1,126 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrPublisherUnavailable dikembalikan publisher yang sedang tidak tersambung
+// ke broker (mis. Kafka mati). Pemanggil boleh melewati event tersebut.
+var ErrPublisherUnavailable = errors.New("publisher event tidak tersedia")
+
+// PublishOptions adalah opsi satu kali Publish. Key kosong berarti ID
+// aggregate dari subject; Headers ditambahkan ke header bawaan publisher.
+type PublishOptions struct {
+	Key     string
+	Headers map[string]string
+	Async   bool // kembali tanpa menunggu broker; error hanya dicatat di log
+}
+
+// PublishOption mengubah PublishOptions
+type PublishOption func(*PublishOptions)
+
+// WithKey mengganti key pesan (default: ID aggregate)
+func WithKey(key string) PublishOption {
+	return func(o *PublishOptions) { o.Key = key }
+}
+
+// WithHeader menambah header pesan
+func WithHeader(key, value string) PublishOption {
+	return func(o *PublishOptions) {
+		if o.Headers == nil {
+			o.Headers = make(map[string]string)
+		}
+		o.Headers[key] = value
+	}
+}
+
+// Async mengirim tanpa menunggu broker
+func Async() PublishOption {
+	return func(o *PublishOptions) { o.Async = true }
+}
+
+// Sync menunggu broker menerima pesan (default)
+func Sync() PublishOption {
+	return func(o *PublishOptions) { o.Async = false }
+}
+
+// ApplyPublishOptions menerapkan opts berurutan dan mengisi key default
+func ApplyPublishOptions(event Event, opts ...PublishOption) PublishOptions {
+	var o PublishOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Key == "" {
+		_, o.Key = event.Aggregate()
+	}
+	return o
+}
+
+// Published adalah event yang dicatat MemoryPublisher beserta opsinya
+type Published struct {
+	Event   Event
+	Options PublishOptions
+}
+
+// MemoryPublisher menyimpan event di memori; dipakai test untuk memeriksa
+// event yang dikirim usecase tanpa broker
+type MemoryPublisher struct {
+	mu        sync.Mutex
+	published []Published
+	err       error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event Event, opts ...PublishOption) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, Published{Event: event, Options: ApplyPublishOptions(event, opts...)})
+	return nil
+}
+
+// Published mengembalikan salinan semua event yang sudah dikirim, berurutan
+func (p *MemoryPublisher) Published() []Published {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Published(nil), p.published...)
+}
+
+// Names mengembalikan nama event yang sudah dikirim, berurutan
+func (p *MemoryPublisher) Names() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, len(p.published))
+	for i, e := range p.published {
+		names[i] = e.Event.Name()
+	}
+	return names
+}
+
+// FailWith membuat Publish berikutnya mengembalikan err (nil untuk normal lagi)
+func (p *MemoryPublisher) FailWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// Reset menghapus catatan event
+func (p *MemoryPublisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.published = nil
+}
+
+// NopPublisher membuang semua event, untuk menjalankan usecase tanpa event
+type NopPublisher struct{}
+
+func (NopPublisher) Publish(context.Context, Event, ...PublishOption) error {
+	return nil
+}
@@ -0,0 +1,159 @@
+package events
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Schema adalah JSON Schema untuk Data satu tipe event pada versi tertentu
+type Schema struct {
+	Name    string          `json:"name"` // mis. "repository.created"
+	Version int             `json:"version"`
+	Body    json.RawMessage `json:"schema"`
+}
+
+// Type mengembalikan atribut CloudEvents "type", mis. "com.taskcrud.repository.created"
+func (s Schema) Type() string {
+	return typePrefix + s.Name
+}
+
+// URI mengembalikan atribut "dataschema"; path ini juga dilayani oleh endpoint schema
+func (s Schema) URI() string {
+	return fmt.Sprintf("%s%s/v%d", SchemaBaseURI, s.Name, s.Version)
+}
+
+// SchemaBaseURI adalah prefix atribut dataschema
+var SchemaBaseURI = defaultSchemaBase
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string][]Schema{} // nama -> semua versi, urut naik
+)
+
+// Nama event yang dipakai aplikasi; setiap nama wajib punya file schemas/<nama>.v<N>.json
+const (
+	RepositoryCreated = "repository.created"
+	RepositoryUpdated = "repository.updated"
+	RepositoryDeleted = "repository.deleted"
+	UserCreated       = "user.created"
+	UserUpdated       = "user.updated"
+	UserDeleted       = "user.deleted"
+)
+
+func init() {
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		body, err := schemaFiles.ReadFile("schemas/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		name, version, err := parseSchemaFile(entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		if err := Register(name, version, body); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// parseSchemaFile memecah "repository.created.v1.json" menjadi nama dan versi
+func parseSchemaFile(file string) (string, int, error) {
+	base := strings.TrimSuffix(file, ".json")
+	i := strings.LastIndex(base, ".v")
+	if i < 0 {
+		return "", 0, fmt.Errorf("nama file schema tidak valid: %s", file)
+	}
+	version, err := strconv.Atoi(base[i+2:])
+	if err != nil || version < 1 {
+		return "", 0, fmt.Errorf("versi schema tidak valid: %s", file)
+	}
+	return base[:i], version, nil
+}
+
+// Register mendaftarkan schema. Versi baru harus kompatibel dengan versi
+// sebelumnya (dicek oleh test lewat CheckCompatibility); perubahan yang tidak
+// kompatibel harus memakai nama event baru.
+func Register(name string, version int, body []byte) error {
+	if !json.Valid(body) {
+		return fmt.Errorf("schema %s v%d bukan JSON yang valid", name, version)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	versions := registry[name]
+	for _, s := range versions {
+		if s.Version == version {
+			return fmt.Errorf("schema %s v%d sudah terdaftar", name, version)
+		}
+	}
+	versions = append(versions, Schema{Name: name, Version: version, Body: body})
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	registry[name] = versions
+	return nil
+}
+
+// Lookup mengembalikan versi terbaru schema untuk nama event
+func Lookup(name string) (Schema, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	versions := registry[name]
+	if len(versions) == 0 {
+		return Schema{}, false
+	}
+	return versions[len(versions)-1], true
+}
+
+// LookupVersion mengembalikan schema untuk nama dan versi tertentu
+func LookupVersion(name string, version int) (Schema, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, s := range registry[name] {
+		if s.Version == version {
+			return s, true
+		}
+	}
+	return Schema{}, false
+}
+
+// Schemas mengembalikan semua schema terdaftar, urut nama lalu versi
+func Schemas() []Schema {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	var all []Schema
+	for _, versions := range registry {
+		all = append(all, versions...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Name != all[j].Name {
+			return all[i].Name < all[j].Name
+		}
+		return all[i].Version < all[j].Version
+	})
+	return all
+}
+
+// Names mengembalikan nama semua event terdaftar, terurut
+func Names() []string {
+	var names []string
+	for _, s := range Schemas() {
+		if len(names) == 0 || names[len(names)-1] != s.Name {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"sort"
+	"strings"
+)
+
+// Routing menentukan topic Kafka dan jumlah partisinya untuk setiap event.
+//   - Mode per event (default): "repository.created" -> "repository_created".
+//   - Mode per aggregate: semua event satu aggregate ke satu topic
+//     ("repository" -> "repository-topic"); tipe event dibawa di TypeHeader.
+//     Urutan created/updated/deleted satu entity terjaga karena semuanya di
+//     partisi yang sama (key = ID aggregate).
+//
+// Topics mengganti nama topic untuk nama event ("repository.created") atau,
+// pada mode per aggregate, untuk tipe aggregate ("repository").
+type Routing struct {
+	PerAggregate    bool
+	Topics          map[string]string
+	Partitions      int            // default jumlah partisi topic baru
+	TopicPartitions map[string]int // jumlah partisi per topic
+}
+
+// Routes dipakai Topic; diisi dari konfigurasi KAFKA_* saat startup
+var Routes = Routing{Partitions: 1}
+
+// Topic memetakan nama event ke topic Kafka sesuai Routes
+func Topic(name string) string {
+	return Routes.Topic(name)
+}
+
+func (r Routing) Topic(name string) string {
+	if topic, ok := r.Topics[name]; ok {
+		return topic
+	}
+	if r.PerAggregate {
+		aggregate, _, _ := strings.Cut(name, ".")
+		if topic, ok := r.Topics[aggregate]; ok {
+			return topic
+		}
+		return aggregate + "-topic"
+	}
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+// AllTopics mengembalikan topic untuk semua event yang terdaftar, terurut dan tanpa duplikat
+func (r Routing) AllTopics() []string {
+	seen := make(map[string]bool)
+	var topics []string
+	for _, s := range Schemas() {
+		topic := r.Topic(s.Name)
+		if !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// PartitionsFor mengembalikan jumlah partisi yang diinginkan untuk topic
+func (r Routing) PartitionsFor(topic string) int {
+	if n, ok := r.TopicPartitions[topic]; ok && n > 0 {
+		return n
+	}
+	if r.Partitions > 0 {
+		return r.Partitions
+	}
+	return 1
+}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// State adalah kondisi satu dependency
+type State string
+
+const (
+	StateUp       State = "up"
+	StateDegraded State = "degraded" // dependency opsional mati, fitur terkait dimatikan
+	StateDown     State = "down"     // dependency wajib mati, service tidak siap
+)
+
+// Component adalah status terakhir satu dependency untuk readiness
+type Component struct {
+	Name     string    `json:"name"`
+	State    State     `json:"state"`
+	Required bool      `json:"required"`
+	Error    string    `json:"error,omitempty"`
+	Since    time.Time `json:"since"`
+}
+
+// Registry menyimpan status dependency; aman untuk goroutine
+type Registry struct {
+	mu         sync.RWMutex
+	components map[string]Component
+}
+
+func NewRegistry() *Registry {
+	return &Registry{components: make(map[string]Component)}
+}
+
+// Set mencatat status dependency. Since hanya berubah saat state berubah.
+func (r *Registry) Set(name string, required bool, state State, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.components[name]
+	if !ok || c.State != state {
+		c.Since = time.Now()
+	}
+	c.Name = name
+	c.Required = required
+	c.State = state
+	c.Error = ""
+	if err != nil {
+		c.Error = err.Error()
+	}
+	r.components[name] = c
+}
+
+// Report mengembalikan status keseluruhan ("ready", "degraded", "not_ready")
+// beserta detail setiap dependency. ready=false hanya jika dependency wajib mati.
+func (r *Registry) Report() (status string, ready bool, components []Component) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	status, ready = "ready", true
+	for _, c := range r.components {
+		components = append(components, c)
+		switch {
+		case c.State == StateUp:
+		case c.Required:
+			status, ready = "not_ready", false
+		case ready:
+			status = "degraded"
+		}
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+	return status, ready, components
+}
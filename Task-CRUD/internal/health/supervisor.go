@@ -0,0 +1,147 @@
+package health
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"time"
+)
+
+// Supervisor memantau dependency opsional (Redis, Kafka). Saat Check gagal,
+// OnDown dipanggil dan Check diulang dengan backoff; saat berhasil lagi, OnUp
+// dipanggil supaya fitur yang bergantung padanya diaktifkan kembali.
+// Jika OnUp mengembalikan error, dependency tetap dianggap mati dan dicoba lagi.
+type Supervisor struct {
+	Name     string
+	Registry *Registry
+	Check    func(ctx context.Context) error
+	OnUp     func() error
+	OnDown   func(err error)
+
+	Interval         time.Duration // jeda ping saat sehat (default 5s)
+	MinBackoff       time.Duration // default 1s
+	MaxBackoff       time.Duration // default 30s
+	FailureThreshold int           // gagal berturut-turut sebelum dianggap mati (default 3)
+	CheckTimeout     time.Duration // default 3s
+
+	up     bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Start menjalankan Check pertama secara sinkron (supaya startup normal langsung
+// memakai dependency) lalu melanjutkan pemantauan di background sampai Stop.
+func (s *Supervisor) Start() {
+	s.defaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	if err := s.check(ctx); err != nil {
+		s.markDown(err)
+	} else {
+		s.markUp()
+	}
+	go s.run(ctx)
+}
+
+// Stop menghentikan pemantauan; OnDown tidak dipanggil
+func (s *Supervisor) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+func (s *Supervisor) run(ctx context.Context) {
+	defer close(s.done)
+
+	failures := 0
+	backoff := s.MinBackoff
+	for {
+		wait := s.Interval
+		if !s.up {
+			wait = jitter(backoff)
+			backoff = min(backoff*2, s.MaxBackoff)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		err := s.check(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err == nil:
+			failures = 0
+			backoff = s.MinBackoff
+			if !s.up {
+				s.markUp()
+			}
+		case s.up:
+			failures++
+			if failures >= s.FailureThreshold {
+				failures = 0
+				s.markDown(err)
+			}
+		default:
+			s.Registry.Set(s.Name, false, StateDegraded, err)
+		}
+	}
+}
+
+func (s *Supervisor) check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.CheckTimeout)
+	defer cancel()
+	return s.Check(ctx)
+}
+
+func (s *Supervisor) markUp() {
+	if s.OnUp != nil {
+		if err := s.OnUp(); err != nil {
+			s.Registry.Set(s.Name, false, StateDegraded, err)
+			log.Printf("⚠️ %s tersambung tapi gagal diaktifkan: %v", s.Name, err)
+			return
+		}
+	}
+	s.up = true
+	s.Registry.Set(s.Name, false, StateUp, nil)
+	log.Printf("✅ %s tersedia, fitur terkait diaktifkan", s.Name)
+}
+
+func (s *Supervisor) markDown(err error) {
+	s.up = false
+	s.Registry.Set(s.Name, false, StateDegraded, err)
+	log.Printf("⚠️ %s tidak tersedia, berjalan dalam mode degraded: %v", s.Name, err)
+	if s.OnDown != nil {
+		s.OnDown(err)
+	}
+}
+
+func (s *Supervisor) defaults() {
+	if s.Interval <= 0 {
+		s.Interval = 5 * time.Second
+	}
+	if s.MinBackoff <= 0 {
+		s.MinBackoff = time.Second
+	}
+	if s.MaxBackoff <= 0 {
+		s.MaxBackoff = 30 * time.Second
+	}
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = 3
+	}
+	if s.CheckTimeout <= 0 {
+		s.CheckTimeout = 3 * time.Second
+	}
+}
+
+// jitter menambah acak ±20% supaya replica tidak reconnect bersamaan
+func jitter(d time.Duration) time.Duration {
+	delta := float64(d) * 0.2
+	return d + time.Duration(delta*(2*rand.Float64()-1))
+}
@@ -2,7 +2,10 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
+	"Task-CRUD/internal/cache"
+	"Task-CRUD/internal/events"
 "Task-CRUD/internal/entity"
 )
 
@@ -43,18 +46,129 @@ type UserRepositoryInterfaceGorm interface {
 	DeleteUser(ctx context.Context, id uint) error
 }
 
+// UserBatchRepositoryInterface dipakai untuk operasi massal (seed/import) pada tabel users
+type UserBatchRepositoryInterface interface {
+	CreateUsersBatch(ctx context.Context, users []entity.User, batchSize int) error
+	GetUsersByEmails(ctx context.Context, emails []string) ([]entity.User, error)
+}
+
+// RepoBatchRepositoryInterface dipakai untuk operasi massal (seed/import) pada tabel repositories
+type RepoBatchRepositoryInterface interface {
+	CreateRepositoriesBatch(ctx context.Context, repos []entity.Repository, batchSize int) error
+	GetRepositoriesByUserIDs(ctx context.Context, userIDs []uint) ([]entity.Repository, error)
+}
+
+// Transactor menjalankan fn di dalam satu transaksi database; repository yang
+// dipanggil dengan context dari fn otomatis ikut transaksi tersebut
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// OutboxRepositoryInterface menyimpan event yang menunggu dikirim ke Kafka
+type OutboxRepositoryInterface interface {
+	Enqueue(ctx context.Context, events ...entity.OutboxEvent) error
+	ProcessPending(ctx context.Context, limit int, fn func(events []entity.OutboxEvent) error) (int, error)
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ChangeFeedRepositoryInterface membaca perubahan users/repositories yang ditulis
+// di luar service (lihat entity.DBChange)
+type ChangeFeedRepositoryInterface interface {
+	// ProcessPending mengunci perubahan yang belum diproses lalu memanggil fn di dalam
+	// transaksi (dibawa ctx); perubahan ditandai selesai jika fn sukses
+	ProcessPending(ctx context.Context, limit int, fn func(ctx context.Context, changes []entity.DBChange) error) (int, error)
+	DeleteProcessed(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ChangeListener membuka koneksi LISTEN untuk notifikasi perubahan dari trigger database
+type ChangeListener interface {
+	Listen(ctx context.Context) (ChangeSubscription, error)
+}
+
+// ChangeSubscription adalah satu koneksi LISTEN. Wait kembali nil saat ada
+// notifikasi, error context saat ctx habis (koneksi tetap bisa dipakai), atau
+// error lain jika koneksi putus.
+type ChangeSubscription interface {
+	Wait(ctx context.Context) error
+	Close() error
+}
+
+// EventPublisher mengirim domain event ke broker. Implementasi: kafka.Publisher,
+// events.MemoryPublisher (test) dan events.NopPublisher.
+type EventPublisher interface {
+	Publish(ctx context.Context, event events.Event, opts ...events.PublishOption) error
+}
+
+// WebhookRepositoryInterface menyimpan langganan webhook dan log pengirimannya
+type WebhookRepositoryInterface interface {
+	Create(ctx context.Context, webhook *entity.Webhook) error
+	GetByID(ctx context.Context, id uint) (*entity.Webhook, error)
+	List(ctx context.Context) ([]entity.Webhook, error)
+	ListActive(ctx context.Context) ([]entity.Webhook, error)
+	Update(ctx context.Context, webhook *entity.Webhook) error
+	Delete(ctx context.Context, id uint) error
+
+	EnqueueDeliveries(ctx context.Context, deliveries ...entity.WebhookDelivery) error
+	GetDelivery(ctx context.Context, webhookID uint, id uint64) (*entity.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]entity.WebhookDelivery, error)
+	// ClaimDue menyewa delivery yang siap dikirim selama lease tanpa menahan transaksi
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, map[uint]*entity.Webhook, error)
+	// CompleteDeliveries menyimpan hasil pengiriman, melepas lease, lalu memanggil fn
+	// dengan webhook yang dikunci; perubahan webhook disimpan di transaksi yang sama
+	CompleteDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery, fn func(deliveries []entity.WebhookDelivery, webhooks map[uint]*entity.Webhook) error) error
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// WebhookUseCaseInterface mengelola langganan webhook dan pengirimannya
+type WebhookUseCaseInterface interface {
+	CreateWebhook(ctx context.Context, webhook *entity.Webhook) error
+	GetWebhook(ctx context.Context, id uint) (*entity.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]entity.Webhook, error)
+	UpdateWebhook(ctx context.Context, id uint, webhook *entity.Webhook) error
+	DeleteWebhook(ctx context.Context, id uint) error
+	ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]entity.WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookID uint, deliveryID uint64) (*entity.WebhookDelivery, error)
+	// EnqueueEvent membuat delivery untuk semua webhook aktif yang berlangganan event
+	EnqueueEvent(ctx context.Context, event events.Event) (int, error)
+}
+
+// DedupRepositoryInterface mencatat event yang sudah diproses per consumer group
+type DedupRepositoryInterface interface {
+	Seen(ctx context.Context, group, eventID string) (bool, error)
+	// Mark mengembalikan false jika event sudah pernah ditandai sebelumnya
+	Mark(ctx context.Context, group, eventID, eventType string) (bool, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
 type RepoUseCaseInterface interface {
 	GetAllRepos(ctx context.Context) ([]entity.Repository, error)
 	GetRepositoryByID(ctx context.Context, id uint) (*entity.Repository, error)
+	// Varian WithETag juga mengembalikan ETag (hash konten) yang disimpan bersama cache
+	GetAllReposWithETag(ctx context.Context) ([]entity.Repository, string, error)
+	GetRepositoryByIDWithETag(ctx context.Context, id uint) (*entity.Repository, string, error)
 	CreateRepo(ctx context.Context, repo *entity.Repository) error
 	UpdateRepo(ctx context.Context, id uint, repo *entity.Repository) error
 	DeleteRepo(ctx context.Context, id uint) error
+	ReplayRepoEvents(ctx context.Context, topic string, ids ...uint) (int, error)
 }
 
 type UserUseCaseInterface interface {
 	GetUsers(ctx context.Context) ([]entity.User, error)
 	GetUserByID(ctx context.Context, id uint) (*entity.User, error)
+	// Varian WithETag juga mengembalikan ETag (hash konten) yang disimpan bersama cache
+	GetUsersWithETag(ctx context.Context) ([]entity.User, string, error)
+	GetUserByIDWithETag(ctx context.Context, id uint) (*entity.User, string, error)
 	CreateUser(ctx context.Context, user *entity.User) error
 	UpdateUser(ctx context.Context, id uint, user *entity.User) error
 	DeleteUser(ctx context.Context, id uint) error
-}
\ No newline at end of file
+}
+
+// CacheAdminUseCaseInterface dipakai admin API untuk melihat dan mengelola isi cache
+type CacheAdminUseCaseInterface interface {
+	ListKeys(ctx context.Context, namespace, pattern string, limit int) ([]string, error)
+	Inspect(ctx context.Context, namespace, key string) (*cache.EntryInfo, error)
+	DeleteKeys(ctx context.Context, namespace string, keys ...string) error
+	DeletePattern(ctx context.Context, namespace, pattern string) (int, error)
+	Warm(ctx context.Context, namespace string, ids ...uint) (int, error)
+	Stats() map[string]cache.NamespaceStats
+}
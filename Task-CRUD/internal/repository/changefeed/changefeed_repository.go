@@ -0,0 +1,165 @@
+package changefeed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"Task-CRUD/internal/entity"
+	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/repository/tx"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"gorm.io/gorm"
+)
+
+// Channel adalah channel NOTIFY yang dipakai trigger; payload-nya ID baris db_changes
+const Channel = "task_crud_changes"
+
+// triggerFunction mencatat perubahan baris ke db_changes lalu mengirim NOTIFY.
+// Perubahan dari sesi dengan application_name service sendiri (argumen trigger)
+// dilewati karena usecase sudah meng-invalidate cache dan mengirim event-nya.
+// NOTIFY baru sampai ke listener setelah commit, dan baris db_changes tetap ada
+// meskipun listener sedang putus sehingga bisa disinkronkan ulang.
+const triggerFunction = `
+CREATE OR REPLACE FUNCTION public.task_crud_record_change() RETURNS trigger AS $$
+DECLARE
+	old_row jsonb;
+	new_row jsonb;
+	change_id bigint;
+BEGIN
+	IF current_setting('application_name', true) = TG_ARGV[0] THEN
+		RETURN NULL;
+	END IF;
+	IF TG_OP <> 'INSERT' THEN
+		old_row := to_jsonb(OLD);
+	END IF;
+	IF TG_OP <> 'DELETE' THEN
+		new_row := to_jsonb(NEW);
+	END IF;
+	IF TG_OP = 'UPDATE' AND old_row = new_row THEN
+		RETURN NULL;
+	END IF;
+
+	-- Data event repository memuat user pemiliknya, sama seperti event dari usecase
+	IF TG_TABLE_NAME = 'repositories' THEN
+		IF old_row IS NOT NULL THEN
+			old_row := old_row || jsonb_build_object('user',
+				(SELECT to_jsonb(u) FROM public.users u WHERE u.id = (old_row->>'user_id')::bigint));
+		END IF;
+		IF new_row IS NOT NULL THEN
+			new_row := new_row || jsonb_build_object('user',
+				(SELECT to_jsonb(u) FROM public.users u WHERE u.id = (new_row->>'user_id')::bigint));
+		END IF;
+	END IF;
+
+	INSERT INTO public.db_changes (table_name, operation, row_id, old_data, new_data, origin, changed_at)
+	VALUES (TG_TABLE_NAME, TG_OP, (COALESCE(new_row, old_row)->>'id')::bigint, old_row, new_row,
+		current_setting('application_name', true), NOW())
+	RETURNING id INTO change_id;
+
+	PERFORM pg_notify('` + Channel + `', change_id::text);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`
+
+// watchedTables adalah tabel yang perubahannya dicatat trigger
+var watchedTables = []string{"users", "repositories"}
+
+// claimQuery mengunci perubahan yang belum diproses, terurut sesuai urutan commit trigger
+const claimQuery = `
+	SELECT * FROM db_changes
+	WHERE processed_at IS NULL
+	ORDER BY id
+	LIMIT ?
+	FOR UPDATE`
+
+type ChangeFeedRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewChangeFeedRepositoryGorm(db *gorm.DB) interfaces.ChangeFeedRepositoryInterface {
+	return &ChangeFeedRepositoryGorm{db: db}
+}
+
+// InstallTriggers membuat (ulang) fungsi dan trigger pencatat perubahan pada
+// users dan repositories. appName adalah application_name koneksi service;
+// perubahan dari koneksi tersebut tidak dicatat. Dijalankan setelah AutoMigrate.
+func InstallTriggers(ctx context.Context, db *gorm.DB, appName string) error {
+	statements := []string{triggerFunction}
+	arg := "'" + strings.ReplaceAll(appName, "'", "''") + "'"
+	for _, table := range watchedTables {
+		statements = append(statements,
+			fmt.Sprintf("DROP TRIGGER IF EXISTS task_crud_changes ON public.%s", table),
+			fmt.Sprintf("CREATE TRIGGER task_crud_changes AFTER INSERT OR UPDATE OR DELETE ON public.%s "+
+				"FOR EACH ROW EXECUTE FUNCTION public.task_crud_record_change(%s)", table, arg),
+		)
+	}
+	return db.WithContext(ctx).Transaction(func(txDB *gorm.DB) error {
+		for _, stmt := range statements {
+			if err := txDB.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("pasang trigger change feed gagal: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ProcessPending memproses perubahan satu batch pada satu waktu di semua
+// replica (advisory lock), supaya event satu entity tetap terurut di outbox.
+// Replica yang tidak mendapat lock langsung kembali dengan 0.
+func (r *ChangeFeedRepositoryGorm) ProcessPending(ctx context.Context, limit int, fn func(ctx context.Context, changes []entity.DBChange) error) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ChangeFeedRepository.ProcessPending")
+	defer span.Finish()
+
+	claimed := 0
+	err := tx.NewTransactorGorm(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		txDB := tx.DB(ctx, r.db)
+
+		var locked bool
+		if err := txDB.Raw("SELECT pg_try_advisory_xact_lock(hashtext(?))", Channel).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		var changes []entity.DBChange
+		if err := txDB.Raw(claimQuery, limit).Scan(&changes).Error; err != nil {
+			return err
+		}
+		claimed = len(changes)
+		if claimed == 0 {
+			return nil
+		}
+		if err := fn(ctx, changes); err != nil {
+			return err
+		}
+
+		ids := make([]uint64, len(changes))
+		for i, c := range changes {
+			ids[i] = c.ID
+		}
+		return txDB.Model(&entity.DBChange{}).Where("id IN ?", ids).Update("processed_at", time.Now()).Error
+	})
+	if err != nil {
+		ext.LogError(span, err)
+		return 0, err
+	}
+	return claimed, nil
+}
+
+// DeleteProcessed menghapus perubahan yang sudah diproses sebelum waktu tertentu
+func (r *ChangeFeedRepositoryGorm) DeleteProcessed(ctx context.Context, before time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ChangeFeedRepository.DeleteProcessed")
+	defer span.Finish()
+
+	result := r.db.WithContext(ctx).Where("processed_at IS NOT NULL AND processed_at < ?", before).Delete(&entity.DBChange{})
+	if result.Error != nil {
+		ext.LogError(span, result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,46 @@
+package changefeed
+
+import (
+	"context"
+
+	interfaces "Task-CRUD/internal/interfaces"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ListenerPgx membuka koneksi pgx terpisah dari pool GORM, karena LISTEN
+// terikat ke satu sesi dan koneksi pool bisa ditutup kapan saja
+type ListenerPgx struct {
+	dsn string
+}
+
+func NewListenerPgx(dsn string) interfaces.ChangeListener {
+	return &ListenerPgx{dsn: dsn}
+}
+
+// Listen tersambung ke database dan menjalankan LISTEN sebelum kembali, sehingga
+// notifikasi setelah Listen sukses tidak ada yang terlewat
+func (l *ListenerPgx) Listen(ctx context.Context) (interfaces.ChangeSubscription, error) {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		conn.Close(context.Background())
+		return nil, err
+	}
+	return &pgxSubscription{conn: conn}, nil
+}
+
+type pgxSubscription struct {
+	conn *pgx.Conn
+}
+
+func (s *pgxSubscription) Wait(ctx context.Context) error {
+	_, err := s.conn.WaitForNotification(ctx)
+	return err
+}
+
+func (s *pgxSubscription) Close() error {
+	return s.conn.Close(context.Background())
+}
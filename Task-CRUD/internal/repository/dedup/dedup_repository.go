@@ -0,0 +1,105 @@
+package dedup
+
+import (
+	"context"
+	"time"
+
+	"Task-CRUD/internal/entity"
+	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/repository/tx"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DedupRepositoryGorm menyimpan event yang sudah diproses di tabel processed_events.
+// Mark memakai transaksi di context, jadi tanda dedup ikut commit/rollback
+// bersama perubahan yang ditulis handler.
+type DedupRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewDedupRepositoryGorm(db *gorm.DB) interfaces.DedupRepositoryInterface {
+	return &DedupRepositoryGorm{db: db}
+}
+
+func (r *DedupRepositoryGorm) Seen(ctx context.Context, group, eventID string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "DedupRepository.Seen")
+	defer span.Finish()
+
+	var count int64
+	err := tx.DB(ctx, r.db).Model(&entity.ProcessedEvent{}).
+		Where("consumer_group = ? AND event_id = ?", group, eventID).
+		Count(&count).Error
+	if err != nil {
+		ext.LogError(span, err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Mark menyisipkan tanda dengan ON CONFLICT DO NOTHING. Consumer lain yang
+// memproses event yang sama bersamaan akan menunggu lock baris ini lalu
+// mendapat false, sehingga transaksinya bisa di-rollback.
+func (r *DedupRepositoryGorm) Mark(ctx context.Context, group, eventID, eventType string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "DedupRepository.Mark")
+	defer span.Finish()
+
+	row := entity.ProcessedEvent{ConsumerGroup: group, EventID: eventID, EventType: eventType}
+	result := tx.DB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if result.Error != nil {
+		ext.LogError(span, result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Purge menghapus tanda yang lebih lama dari before
+func (r *DedupRepositoryGorm) Purge(ctx context.Context, before time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "DedupRepository.Purge")
+	defer span.Finish()
+
+	result := r.db.WithContext(ctx).Where("processed_at < ?", before).Delete(&entity.ProcessedEvent{})
+	if result.Error != nil {
+		ext.LogError(span, result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// DedupRepositoryRedis menyimpan tanda dedup sebagai key Redis dengan TTL.
+// Tidak ikut transaksi database: tanda ditulis setelah handler sukses, jadi
+// tetap ada jendela kecil untuk duplikat jika proses mati di antaranya.
+type DedupRepositoryRedis struct {
+	rdb    redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+func NewDedupRepositoryRedis(rdb redis.UniversalClient, prefix string, ttl time.Duration) interfaces.DedupRepositoryInterface {
+	return &DedupRepositoryRedis{rdb: rdb, prefix: prefix, ttl: ttl}
+}
+
+func (r *DedupRepositoryRedis) key(group, eventID string) string {
+	return r.prefix + ":" + group + ":" + eventID
+}
+
+func (r *DedupRepositoryRedis) Seen(ctx context.Context, group, eventID string) (bool, error) {
+	n, err := r.rdb.Exists(ctx, r.key(group, eventID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *DedupRepositoryRedis) Mark(ctx context.Context, group, eventID, eventType string) (bool, error) {
+	return r.rdb.SetNX(ctx, r.key(group, eventID), eventType, r.ttl).Result()
+}
+
+// Purge tidak melakukan apa-apa: key kedaluwarsa sendiri lewat TTL
+func (r *DedupRepositoryRedis) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
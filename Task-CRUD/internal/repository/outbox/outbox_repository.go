@@ -0,0 +1,139 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"Task-CRUD/internal/entity"
+	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/repository/tx"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"gorm.io/gorm"
+)
+
+// claimQuery mengunci baris yang siap dikirim. SKIP LOCKED membuat beberapa
+// replica bisa menjalankan relay bersamaan tanpa saling menunggu. Urutan per
+// aggregate dijaga dengan dua aturan:
+//   - advisory lock per aggregate: satu aggregate hanya diproses satu replica;
+//   - baris dilewati selama ada baris lebih lama dari aggregate yang sama yang
+//     masih menunggu retry.
+//
+// Advisory lock diambil di query luar, hanya untuk baris yang sudah terkunci
+// dan lolos LIMIT. Di WHERE query dalam, Postgres bisa mengevaluasinya untuk
+// baris yang akhirnya dibuang LIMIT/SKIP LOCKED, sehingga replica memegang
+// lock aggregate yang tidak pernah ia proses.
+const claimQuery = `
+	WITH candidates AS MATERIALIZED (
+	    SELECT * FROM outbox o
+	    WHERE o.published_at IS NULL
+	      AND o.next_attempt_at <= NOW()
+	      AND NOT EXISTS (
+	          SELECT 1 FROM outbox p
+	          WHERE p.aggregate_type = o.aggregate_type
+	            AND p.aggregate_id = o.aggregate_id
+	            AND p.published_at IS NULL
+	            AND p.id < o.id
+	            AND p.next_attempt_at > NOW()
+	      )
+	    ORDER BY o.id
+	    LIMIT ?
+	    FOR UPDATE SKIP LOCKED
+	)
+	SELECT * FROM candidates c
+	WHERE pg_try_advisory_xact_lock(hashtext(c.aggregate_type || ':' || c.aggregate_id))
+	ORDER BY c.id
+	`
+
+type OutboxRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepositoryGorm(db *gorm.DB) interfaces.OutboxRepositoryInterface {
+	return &OutboxRepositoryGorm{db: db}
+}
+
+// Enqueue menulis event memakai transaksi di context (lihat tx.TransactorGorm)
+func (r *OutboxRepositoryGorm) Enqueue(ctx context.Context, events ...entity.OutboxEvent) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "OutboxRepository.Enqueue")
+	defer span.Finish()
+
+	if len(events) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for i := range events {
+		if events[i].NextAttemptAt.IsZero() {
+			events[i].NextAttemptAt = now
+		}
+	}
+	if err := tx.DB(ctx, r.db).Create(&events).Error; err != nil {
+		ext.LogError(span, err)
+		return err
+	}
+	return nil
+}
+
+// ProcessPending mengunci maksimal limit event yang siap dikirim lalu memanggil fn.
+// fn mengisi PublishedAt untuk event yang terkirim, atau Attempts/LastError/
+// NextAttemptAt untuk yang gagal; perubahan itu disimpan sebelum lock dilepas.
+func (r *OutboxRepositoryGorm) ProcessPending(ctx context.Context, limit int, fn func(events []entity.OutboxEvent) error) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "OutboxRepository.ProcessPending")
+	defer span.Finish()
+
+	claimed := 0
+	err := r.db.WithContext(ctx).Transaction(func(txDB *gorm.DB) error {
+		var events []entity.OutboxEvent
+		if err := txDB.Raw(claimQuery, limit).Scan(&events).Error; err != nil {
+			return err
+		}
+		claimed = len(events)
+		if claimed == 0 {
+			return nil
+		}
+		if err := fn(events); err != nil {
+			return err
+		}
+
+		var published []uint64
+		var publishedAt time.Time
+		for _, e := range events {
+			if e.PublishedAt != nil {
+				published = append(published, e.ID)
+				publishedAt = *e.PublishedAt
+				continue
+			}
+			err := txDB.Model(&entity.OutboxEvent{}).Where("id = ?", e.ID).Updates(map[string]interface{}{
+				"attempts":        e.Attempts,
+				"last_error":      e.LastError,
+				"next_attempt_at": e.NextAttemptAt,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(published) == 0 {
+			return nil
+		}
+		return txDB.Model(&entity.OutboxEvent{}).Where("id IN ?", published).Update("published_at", publishedAt).Error
+	})
+	if err != nil {
+		ext.LogError(span, err)
+		return 0, err
+	}
+	return claimed, nil
+}
+
+// DeletePublished menghapus event yang sudah terkirim sebelum waktu tertentu
+func (r *OutboxRepositoryGorm) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "OutboxRepository.DeletePublished")
+	defer span.Finish()
+
+	result := r.db.WithContext(ctx).Where("published_at IS NOT NULL AND published_at < ?", before).Delete(&entity.OutboxEvent{})
+	if result.Error != nil {
+		ext.LogError(span, result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
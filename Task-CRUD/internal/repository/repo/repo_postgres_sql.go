@@ -6,6 +6,7 @@ import (
 
 	"Task-CRUD/internal/entity"
 	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/repository/tx"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
@@ -29,7 +30,7 @@ func (r *RepoRepositoryPostgres) GetAllRepositories(ctx context.Context) ([]enti
 	FROM repositories r
 	JOIN users u ON r.user_id = u.id
 	`
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := tx.SQL(ctx, r.db).QueryContext(ctx, query)
 	if err != nil {
 		ext.LogError(span, err)
 		return nil, err
@@ -70,13 +71,13 @@ func (r *RepoRepositoryPostgres) GetRepositoryByID(ctx context.Context, id uint)
 	var repo entity.Repository
 	var user entity.User
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := tx.SQL(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&repo.ID, &repo.Name, &repo.UserID, &repo.URL, &repo.AIEnabled, &repo.CreatedAt, &repo.UpdatedAt,
 		&user.ID, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, entity.ErrNotFound
 		}
 		ext.LogError(span, err)
 		return nil, err
@@ -95,7 +96,7 @@ func (r *RepoRepositoryPostgres) CreateRepository(ctx context.Context, repo *ent
 	VALUES ($1, $2, $3, $4, NOW(), NOW())
 	RETURNING id
 	`
-	err := r.db.QueryRowContext(ctx, query,
+	err := tx.SQL(ctx, r.db).QueryRowContext(ctx, query,
 		repo.Name, repo.UserID, repo.URL, repo.AIEnabled,
 	).Scan(&repo.ID)
 	if err != nil {
@@ -113,7 +114,7 @@ func (r *RepoRepositoryPostgres) UpdateRepository(ctx context.Context, id uint,
 	SET name = $1, user_id = $2, url = $3, ai_enabled = $4, updated_at = NOW()
 	WHERE id = $5
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := tx.SQL(ctx, r.db).ExecContext(ctx, query,
 		updatedRepo.Name, updatedRepo.UserID, updatedRepo.URL, updatedRepo.AIEnabled, id,
 	)
 	if err != nil {
@@ -126,7 +127,7 @@ func (r *RepoRepositoryPostgres) DeleteRepository(ctx context.Context, id uint)
 	span, ctx := opentracing.StartSpanFromContext(ctx, "RepoRepositorySQL.DeleteRepository")
 	defer span.Finish()
 
-	_, err := r.db.ExecContext(ctx, `DELETE FROM repositories WHERE id = $1`, id)
+	_, err := tx.SQL(ctx, r.db).ExecContext(ctx, `DELETE FROM repositories WHERE id = $1`, id)
 	if err != nil {
 		ext.LogError(span, err)
 	}
@@ -3,13 +3,16 @@ package repo
 import (
 	"Task-CRUD/internal/entity"
 	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/repository/tx"
 	"context"
+	"errors"
 	"log"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RepoRepositoryGorm struct {
@@ -20,12 +23,17 @@ func NewRepoRepositoryGorm(db *gorm.DB) interfaces.RepoRepositoryInterfaceGorm {
 	return &RepoRepositoryGorm{db: db}
 }
 
+// NewRepoBatchRepositoryGorm mengembalikan repository GORM yang sama untuk operasi massal
+func NewRepoBatchRepositoryGorm(db *gorm.DB) interfaces.RepoBatchRepositoryInterface {
+	return &RepoRepositoryGorm{db: db}
+}
+
 func (r *RepoRepositoryGorm) GetAllRepositories(ctx context.Context) ([]entity.Repository, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "RepoRepository.GetAllRepositories")
 	defer span.Finish()
 
 	var repos []entity.Repository
-	if err := r.db.WithContext(ctx).Preload("User").Find(&repos).Error; err != nil {
+	if err := tx.DB(ctx, r.db).Preload("User").Find(&repos).Error; err != nil {
 		ext.LogError(span, err)
 		return nil, err
 	}
@@ -37,7 +45,10 @@ func (r *RepoRepositoryGorm) GetRepositoryByID(ctx context.Context, id uint) (*e
 	defer span.Finish()
 
 	var repo entity.Repository
-	if err := r.db.WithContext(ctx).Preload("User").First(&repo, id).Error; err != nil {
+	if err := tx.DB(ctx, r.db).Preload("User").First(&repo, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNotFound
+		}
 		ext.LogError(span, err)
 		return nil, err
 	}
@@ -50,7 +61,7 @@ func (r *RepoRepositoryGorm) CreateRepository(ctx context.Context, repo *entity.
 
 	repo.CreatedAt = time.Now()
 	repo.UpdatedAt = time.Now()
-	if err := r.db.WithContext(ctx).Create(repo).Error; err != nil {
+	if err := tx.DB(ctx, r.db).Create(repo).Error; err != nil {
 		log.Printf("ERROR | GORM gagal insert repository: %v", err)
 		ext.LogError(span, err)
 		return err
@@ -63,7 +74,7 @@ func (r *RepoRepositoryGorm) UpdateRepository(ctx context.Context, id uint, upda
 	defer span.Finish()
 
 	updatedRepo.UpdatedAt = time.Now()
-	if err := r.db.WithContext(ctx).Model(&entity.Repository{}).Where("id = ?", id).Updates(updatedRepo).Error; err != nil {
+	if err := tx.DB(ctx, r.db).Model(&entity.Repository{}).Where("id = ?", id).Updates(updatedRepo).Error; err != nil {
 		ext.LogError(span, err)
 		return err
 	}
@@ -74,9 +85,39 @@ func (r *RepoRepositoryGorm) DeleteRepository(ctx context.Context, id uint) erro
 	span, ctx := opentracing.StartSpanFromContext(ctx, "RepoRepository.DeleteRepository")
 	defer span.Finish()
 
-	if err := r.db.WithContext(ctx).Delete(&entity.Repository{}, id).Error; err != nil {
+	if err := tx.DB(ctx, r.db).Delete(&entity.Repository{}, id).Error; err != nil {
+		ext.LogError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (r *RepoRepositoryGorm) CreateRepositoriesBatch(ctx context.Context, repos []entity.Repository, batchSize int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RepoRepository.CreateRepositoriesBatch")
+	defer span.Finish()
+
+	if len(repos) == 0 {
+		return nil
+	}
+	if err := tx.DB(ctx, r.db).Omit(clause.Associations).CreateInBatches(&repos, batchSize).Error; err != nil {
+		log.Printf("ERROR | GORM gagal batch insert repository: %v", err)
 		ext.LogError(span, err)
 		return err
 	}
 	return nil
 }
+
+func (r *RepoRepositoryGorm) GetRepositoriesByUserIDs(ctx context.Context, userIDs []uint) ([]entity.Repository, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RepoRepository.GetRepositoriesByUserIDs")
+	defer span.Finish()
+
+	var repos []entity.Repository
+	if len(userIDs) == 0 {
+		return repos, nil
+	}
+	if err := tx.DB(ctx, r.db).Where("user_id IN ?", userIDs).Find(&repos).Error; err != nil {
+		ext.LogError(span, err)
+		return nil, err
+	}
+	return repos, nil
+}
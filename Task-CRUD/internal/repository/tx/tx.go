@@ -0,0 +1,59 @@
+package tx
+
+import (
+	"context"
+	"database/sql"
+
+	interfaces "Task-CRUD/internal/interfaces"
+
+	"gorm.io/gorm"
+)
+
+type ctxKey struct{}
+
+// TransactorGorm menjalankan fungsi di dalam satu transaksi database. Transaksi
+// dibawa lewat context sehingga repository GORM (DB) maupun SQL native (SQL)
+// yang dipanggil di dalamnya otomatis ikut transaksi yang sama.
+type TransactorGorm struct {
+	db *gorm.DB
+}
+
+func NewTransactorGorm(db *gorm.DB) interfaces.Transactor {
+	return &TransactorGorm{db: db}
+}
+
+// WithinTransaction meng-commit jika fn sukses dan rollback jika fn error.
+// Jika context sudah membawa transaksi, fn dijalankan di transaksi tersebut.
+func (t *TransactorGorm) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(ctxKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return t.db.WithContext(ctx).Transaction(func(txDB *gorm.DB) error {
+		return fn(context.WithValue(ctx, ctxKey{}, txDB))
+	})
+}
+
+// DB mengembalikan transaksi aktif dari context, atau db jika tidak ada transaksi
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if txDB, ok := ctx.Value(ctxKey{}).(*gorm.DB); ok {
+		return txDB.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// Conn adalah bagian dari *sql.DB dan *sql.Tx yang dipakai repository SQL native
+type Conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// SQL mengembalikan *sql.Tx milik transaksi aktif di context, atau db jika tidak ada
+func SQL(ctx context.Context, db *sql.DB) Conn {
+	if txDB, ok := ctx.Value(ctxKey{}).(*gorm.DB); ok {
+		if sqlTx, ok := txDB.Statement.ConnPool.(*sql.Tx); ok {
+			return sqlTx
+		}
+	}
+	return db
+}
@@ -2,9 +2,10 @@ package user
 
 import (
 	"Task-CRUD/internal/entity"
+	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/repository/tx"
 	"context"
 	"database/sql"
-	interfaces "Task-CRUD/internal/interfaces"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
@@ -22,7 +23,7 @@ func (r *UserRepositoryPostgres) GetAllUsers(ctx context.Context) ([]entity.User
 	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepositoryPostgres.GetAllUsers")
 	defer span.Finish()
 
-	rows, err := r.db.QueryContext(ctx, `SELECT id, name, email, created_at, updated_at FROM users`)
+	rows, err := tx.SQL(ctx, r.db).QueryContext(ctx, `SELECT id, name, email, created_at, updated_at FROM users`)
 	if err != nil {
 		ext.LogError(span, err)
 		return nil, err
@@ -48,11 +49,11 @@ func (r *UserRepositoryPostgres) GetUserByID(ctx context.Context, id uint) (*ent
 
 	query := `SELECT id, name, email, created_at, updated_at FROM users WHERE id = $1`
 	var user entity.User
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := tx.SQL(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&user.ID, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
-		return nil, nil
+		return nil, entity.ErrNotFound
 	} else if err != nil {
 		ext.LogError(span, err)
 		return nil, err
@@ -65,7 +66,7 @@ func (r *UserRepositoryPostgres) CreateUser(ctx context.Context, user *entity.Us
 	defer span.Finish()
 
 	query := `INSERT INTO users (name, email, created_at, updated_at) VALUES ($1, $2, NOW(), NOW()) RETURNING id`
-	err := r.db.QueryRowContext(ctx, query, user.Name, user.Email).Scan(&user.ID)
+	err := tx.SQL(ctx, r.db).QueryRowContext(ctx, query, user.Name, user.Email).Scan(&user.ID)
 	if err != nil {
 		ext.LogError(span, err)
 	}
@@ -77,7 +78,7 @@ func (r *UserRepositoryPostgres) UpdateUser(ctx context.Context, id uint, user *
 	defer span.Finish()
 
 	query := `UPDATE users SET name = $1, email = $2, updated_at = NOW() WHERE id = $3`
-	_, err := r.db.ExecContext(ctx, query, user.Name, user.Email, id)
+	_, err := tx.SQL(ctx, r.db).ExecContext(ctx, query, user.Name, user.Email, id)
 	if err != nil {
 		ext.LogError(span, err)
 	}
@@ -88,7 +89,7 @@ func (r *UserRepositoryPostgres) DeleteUser(ctx context.Context, id uint) error
 	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepositoryPostgres.DeleteUser")
 	defer span.Finish()
 
-	_, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	_, err := tx.SQL(ctx, r.db).ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
 	if err != nil {
 		ext.LogError(span, err)
 	}
@@ -3,14 +3,17 @@ package user
 import (
 	"Task-CRUD/internal/entity"
 	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/repository/tx"
 
 	"context"
+	"errors"
 	"log"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepositoryGorm struct {
@@ -21,12 +24,17 @@ func NewUserRepositoryGorm(db *gorm.DB) interfaces.UserRepositoryInterfaceGorm {
 	return &UserRepositoryGorm{db: db}
 }
 
+// NewUserBatchRepositoryGorm mengembalikan repository GORM yang sama untuk operasi massal
+func NewUserBatchRepositoryGorm(db *gorm.DB) interfaces.UserBatchRepositoryInterface {
+	return &UserRepositoryGorm{db: db}
+}
+
 func (r *UserRepositoryGorm) GetAllUsers(ctx context.Context) ([]entity.User, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepositoryGorm.GetAllUsers")
 	defer span.Finish()
 
 	var users []entity.User
-	err := r.db.WithContext(ctx).Find(&users).Error
+	err := tx.DB(ctx, r.db).Find(&users).Error
 	if err != nil {
 		ext.LogError(span, err)
 	}
@@ -38,14 +46,15 @@ func (r *UserRepositoryGorm) GetUserByID(ctx context.Context, id uint) (*entity.
 	defer span.Finish()
 
 	var user entity.User
-	err := r.db.WithContext(ctx).First(&user, id).Error
-	if err == gorm.ErrRecordNotFound {
-		return nil, nil
+	err := tx.DB(ctx, r.db).First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, entity.ErrNotFound
 	}
 	if err != nil {
 		ext.LogError(span, err)
+		return nil, err
 	}
-	return &user, err
+	return &user, nil
 }
 
 func (r *UserRepositoryGorm) CreateUser(ctx context.Context, user *entity.User) error {
@@ -55,7 +64,7 @@ func (r *UserRepositoryGorm) CreateUser(ctx context.Context, user *entity.User)
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
-	err := r.db.WithContext(ctx).Create(user).Error
+	err := tx.DB(ctx, r.db).Create(user).Error
 	if err != nil {
 		ext.LogError(span, err)
 		log.Printf("ERROR | GORM gagal insert user: %v", err)
@@ -68,7 +77,7 @@ func (r *UserRepositoryGorm) UpdateUser(ctx context.Context, id uint, user *enti
 	defer span.Finish()
 
 	user.UpdatedAt = time.Now()
-	err := r.db.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).Updates(user).Error
+	err := tx.DB(ctx, r.db).Model(&entity.User{}).Where("id = ?", id).Updates(user).Error
 	if err != nil {
 		ext.LogError(span, err)
 	}
@@ -79,9 +88,42 @@ func (r *UserRepositoryGorm) DeleteUser(ctx context.Context, id uint) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepositoryGorm.DeleteUser")
 	defer span.Finish()
 
-	err := r.db.WithContext(ctx).Delete(&entity.User{}, id).Error
+	err := tx.DB(ctx, r.db).Delete(&entity.User{}, id).Error
+	if err != nil {
+		ext.LogError(span, err)
+	}
+	return err
+}
+
+// CreateUsersBatch menyimpan banyak user sekaligus. Email yang sudah ada dilewati (ON CONFLICT DO NOTHING).
+func (r *UserRepositoryGorm) CreateUsersBatch(ctx context.Context, users []entity.User, batchSize int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepositoryGorm.CreateUsersBatch")
+	defer span.Finish()
+
+	if len(users) == 0 {
+		return nil
+	}
+	err := tx.DB(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "email"}}, DoNothing: true}).
+		CreateInBatches(&users, batchSize).Error
 	if err != nil {
 		ext.LogError(span, err)
+		log.Printf("ERROR | GORM gagal batch insert user: %v", err)
 	}
 	return err
 }
+
+func (r *UserRepositoryGorm) GetUsersByEmails(ctx context.Context, emails []string) ([]entity.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepositoryGorm.GetUsersByEmails")
+	defer span.Finish()
+
+	var users []entity.User
+	if len(emails) == 0 {
+		return users, nil
+	}
+	err := tx.DB(ctx, r.db).Where("email IN ?", emails).Find(&users).Error
+	if err != nil {
+		ext.LogError(span, err)
+	}
+	return users, err
+}
@@ -0,0 +1,307 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"Task-CRUD/internal/entity"
+	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/repository/tx"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// claimQuery menyewa delivery yang jatuh tempo dengan mengisi locked_until.
+// Lock baris hanya dipegang selama statement ini; delivery yang masih disewa
+// replica lain dilewati sampai lease-nya habis (mis. replica tersebut mati).
+const claimQuery = `
+	UPDATE webhook_deliveries SET locked_until = NOW() + ? * INTERVAL '1 second'
+	WHERE id IN (
+		SELECT d.id FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.status = ?
+		  AND d.next_attempt_at <= NOW()
+		  AND (d.locked_until IS NULL OR d.locked_until <= NOW())
+		  AND w.active
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT ?
+		FOR UPDATE OF d SKIP LOCKED
+	)
+	RETURNING *
+	`
+
+type WebhookRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepositoryGorm(db *gorm.DB) interfaces.WebhookRepositoryInterface {
+	return &WebhookRepositoryGorm{db: db}
+}
+
+func (r *WebhookRepositoryGorm) Create(ctx context.Context, webhook *entity.Webhook) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.Create")
+	defer span.Finish()
+
+	if err := tx.DB(ctx, r.db).Create(webhook).Error; err != nil {
+		ext.LogError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryGorm) GetByID(ctx context.Context, id uint) (*entity.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.GetByID")
+	defer span.Finish()
+
+	var webhook entity.Webhook
+	err := tx.DB(ctx, r.db).First(&webhook, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, entity.ErrNotFound
+	}
+	if err != nil {
+		ext.LogError(span, err)
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepositoryGorm) List(ctx context.Context) ([]entity.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.List")
+	defer span.Finish()
+
+	var webhooks []entity.Webhook
+	if err := tx.DB(ctx, r.db).Order("id").Find(&webhooks).Error; err != nil {
+		ext.LogError(span, err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepositoryGorm) ListActive(ctx context.Context) ([]entity.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.ListActive")
+	defer span.Finish()
+
+	var webhooks []entity.Webhook
+	if err := tx.DB(ctx, r.db).Where("active").Order("id").Find(&webhooks).Error; err != nil {
+		ext.LogError(span, err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// Update menyimpan semua kolom webhook (termasuk Active dan penghitung kegagalan)
+func (r *WebhookRepositoryGorm) Update(ctx context.Context, webhook *entity.Webhook) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.Update")
+	defer span.Finish()
+
+	result := tx.DB(ctx, r.db).Model(webhook).Select("*").Omit("created_at").Updates(webhook)
+	if result.Error != nil {
+		ext.LogError(span, result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
+}
+
+// Delete menghapus webhook beserta log pengirimannya
+func (r *WebhookRepositoryGorm) Delete(ctx context.Context, id uint) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.Delete")
+	defer span.Finish()
+
+	var affected int64
+	err := tx.DB(ctx, r.db).Transaction(func(txDB *gorm.DB) error {
+		if err := txDB.Where("webhook_id = ?", id).Delete(&entity.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		result := txDB.Delete(&entity.Webhook{}, id)
+		affected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		ext.LogError(span, err)
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
+}
+
+// EnqueueDeliveries menulis delivery baru memakai transaksi di context. Event
+// yang sudah punya delivery untuk webhook yang sama dilewati (event dikirim ulang Kafka).
+func (r *WebhookRepositoryGorm) EnqueueDeliveries(ctx context.Context, deliveries ...entity.WebhookDelivery) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.EnqueueDeliveries")
+	defer span.Finish()
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := tx.DB(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "webhook_id"}, {Name: "event_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "redelivery_of IS NULL"}}},
+		DoNothing:   true,
+	}).Create(&deliveries).Error
+	if err != nil {
+		ext.LogError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryGorm) GetDelivery(ctx context.Context, webhookID uint, id uint64) (*entity.WebhookDelivery, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.GetDelivery")
+	defer span.Finish()
+
+	var delivery entity.WebhookDelivery
+	err := tx.DB(ctx, r.db).Where("webhook_id = ? AND id = ?", webhookID, id).First(&delivery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, entity.ErrNotFound
+	}
+	if err != nil {
+		ext.LogError(span, err)
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries mengembalikan delivery terbaru lebih dulu
+func (r *WebhookRepositoryGorm) ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]entity.WebhookDelivery, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.ListDeliveries")
+	defer span.Finish()
+
+	var deliveries []entity.WebhookDelivery
+	err := tx.DB(ctx, r.db).Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		ext.LogError(span, err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimDue menyewa paling banyak limit delivery selama lease dan mengembalikan
+// webhook-nya. Tidak ada transaksi yang tetap terbuka setelah ClaimDue selesai.
+func (r *WebhookRepositoryGorm) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, map[uint]*entity.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.ClaimDue")
+	defer span.Finish()
+
+	var deliveries []entity.WebhookDelivery
+	err := r.db.WithContext(ctx).Raw(claimQuery, lease.Seconds(), entity.DeliveryPending, limit).Scan(&deliveries).Error
+	if err != nil {
+		ext.LogError(span, err)
+		return nil, nil, err
+	}
+	if len(deliveries) == 0 {
+		return nil, nil, nil
+	}
+	// RETURNING tidak menjamin urutan
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].NextAttemptAt.Equal(deliveries[j].NextAttemptAt) {
+			return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+
+	var rows []entity.Webhook
+	if err := r.db.WithContext(ctx).Where("id IN ?", webhookIDs(deliveries)).Find(&rows).Error; err != nil {
+		ext.LogError(span, err)
+		return nil, nil, err
+	}
+	return deliveries, webhookMap(rows), nil
+}
+
+// CompleteDeliveries menyimpan hasil pengiriman dan melepas lease dalam satu
+// transaksi pendek. Delivery yang lease-nya sudah diambil alih replica lain
+// dilewati. fn menerima delivery yang tersimpan beserta webhook-nya yang dikunci
+// (FOR UPDATE), sehingga penghitung kegagalan dari beberapa replica tidak saling menimpa.
+func (r *WebhookRepositoryGorm) CompleteDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery, fn func(deliveries []entity.WebhookDelivery, webhooks map[uint]*entity.Webhook) error) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.CompleteDeliveries")
+	defer span.Finish()
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Transaction(func(txDB *gorm.DB) error {
+		completed := make([]entity.WebhookDelivery, 0, len(deliveries))
+		for i := range deliveries {
+			delivery := deliveries[i]
+			lease := delivery.LockedUntil
+			delivery.LockedUntil = nil
+			result := txDB.Model(&delivery).Where("locked_until = ?", lease).Select("*").Omit("created_at").Updates(&delivery)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 1 {
+				completed = append(completed, delivery)
+			}
+		}
+		if len(completed) == 0 {
+			return nil
+		}
+
+		var rows []entity.Webhook
+		err := txDB.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", webhookIDs(completed)).Order("id").Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		webhooks := webhookMap(rows)
+		if err := fn(completed, webhooks); err != nil {
+			return err
+		}
+		for _, w := range webhooks {
+			err := txDB.Model(w).Updates(map[string]interface{}{
+				"active":       w.Active,
+				"failures":     w.Failures,
+				"disabled_at":  w.DisabledAt,
+				"disabled_why": w.DisabledWhy,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		ext.LogError(span, err)
+		return err
+	}
+	return nil
+}
+
+func webhookIDs(deliveries []entity.WebhookDelivery) []uint {
+	ids := make([]uint, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.WebhookID)
+	}
+	return ids
+}
+
+func webhookMap(rows []entity.Webhook) map[uint]*entity.Webhook {
+	webhooks := make(map[uint]*entity.Webhook, len(rows))
+	for i := range rows {
+		webhooks[rows[i].ID] = &rows[i]
+	}
+	return webhooks
+}
+
+// DeleteDeliveriesBefore menghapus log delivery yang sudah selesai sebelum waktu tertentu
+func (r *WebhookRepositoryGorm) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookRepository.DeleteDeliveriesBefore")
+	defer span.Finish()
+
+	result := r.db.WithContext(ctx).
+		Where("status <> ? AND updated_at < ?", entity.DeliveryPending, before).
+		Delete(&entity.WebhookDelivery{})
+	if result.Error != nil {
+		ext.LogError(span, result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,172 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tipe schema yang dikenal Confluent Schema Registry
+const (
+	TypeAvro     = "AVRO"
+	TypeProtobuf = "PROTOBUF"
+	TypeJSON     = "JSON"
+)
+
+// ErrNotFound dikembalikan untuk schema ID atau subject yang tidak ada di registry
+var ErrNotFound = errors.New("schema tidak ditemukan di registry")
+
+// Schema adalah satu schema di registry. Type kosong berarti AVRO (default Confluent).
+type Schema struct {
+	Type   string
+	Schema string
+}
+
+// Registry mendaftarkan schema per subject dan mencari schema dari ID-nya.
+// Implementasi: Client (Confluent-compatible, lewat HTTP) dan Memory (stub untuk test).
+type Registry interface {
+	// Register mengembalikan ID schema; schema yang sama di subject yang sama selalu mendapat ID yang sama
+	Register(ctx context.Context, subject string, schema Schema) (int, error)
+	SchemaByID(ctx context.Context, id int) (Schema, error)
+}
+
+// ClientConfig mengatur Client
+type ClientConfig struct {
+	URL      string
+	Username string // basic auth, opsional
+	Password string
+	Timeout  time.Duration // default 10s
+}
+
+// Client adalah klien REST Confluent Schema Registry. Hasil Register dan
+// SchemaByID di-cache tanpa batas waktu karena schema di registry tidak pernah berubah.
+type Client struct {
+	cfg  ClientConfig
+	http *http.Client
+
+	mu  sync.RWMutex
+	ids map[string]int // subject + schema -> ID
+	byI map[int]Schema
+}
+
+func NewClient(cfg ClientConfig) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	return &Client{
+		cfg:  cfg,
+		http: &http.Client{Timeout: cfg.Timeout},
+		ids:  make(map[string]int),
+		byI:  make(map[int]Schema),
+	}
+}
+
+// Register memanggil POST /subjects/{subject}/versions
+func (c *Client) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	key := subject + "\x00" + schema.Type + "\x00" + schema.Schema
+	c.mu.RLock()
+	id, ok := c.ids[key]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	body := map[string]string{"schema": schema.Schema}
+	if schema.Type != "" && schema.Type != TypeAvro {
+		body["schemaType"] = schema.Type
+	}
+	var resp struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", body, &resp); err != nil {
+		return 0, fmt.Errorf("register schema %s gagal: %w", subject, err)
+	}
+
+	c.mu.Lock()
+	c.ids[key] = resp.ID
+	c.byI[resp.ID] = schema
+	c.mu.Unlock()
+	return resp.ID, nil
+}
+
+// SchemaByID memanggil GET /schemas/ids/{id}
+func (c *Client) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.byI[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	var resp struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &resp); err != nil {
+		return Schema{}, fmt.Errorf("ambil schema %d gagal: %w", id, err)
+	}
+	schema = Schema{Type: resp.SchemaType, Schema: resp.Schema}
+	if schema.Type == "" {
+		schema.Type = TypeAvro
+	}
+
+	c.mu.Lock()
+	c.byI[id] = schema
+	c.mu.Unlock()
+	return schema, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		raw, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.URL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+	if c.cfg.Username != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			ErrorCode int    `json:"error_code"`
+			Message   string `json:"message"`
+		}
+		_ = json.Unmarshal(raw, &apiErr)
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %s", ErrNotFound, apiErr.Message)
+		}
+		return fmt.Errorf("registry membalas %s: %s", resp.Status, apiErr.Message)
+	}
+	return json.Unmarshal(raw, out)
+}
@@ -0,0 +1,117 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Memory adalah registry in-memory untuk test dan pengembangan lokal. Memory
+// juga melayani subset REST API Confluent (ServeHTTP), sehingga Client bisa
+// diuji dengan httptest.NewServer(memory).
+type Memory struct {
+	mu       sync.Mutex
+	schemas  []Schema         // ID = indeks + 1
+	ids      map[string]int   // schema -> ID (ID global, seperti Confluent)
+	subjects map[string][]int // subject -> ID per versi
+	requests atomic.Int64
+}
+
+func NewMemory() *Memory {
+	return &Memory{ids: make(map[string]int), subjects: make(map[string][]int)}
+}
+
+func (m *Memory) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	if schema.Type == "" {
+		schema.Type = TypeAvro
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := schema.Type + "\x00" + schema.Schema
+	id, ok := m.ids[key]
+	if !ok {
+		m.schemas = append(m.schemas, schema)
+		id = len(m.schemas)
+		m.ids[key] = id
+	}
+	for _, existing := range m.subjects[subject] {
+		if existing == id {
+			return id, nil
+		}
+	}
+	m.subjects[subject] = append(m.subjects[subject], id)
+	return id, nil
+}
+
+func (m *Memory) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id <= 0 || id > len(m.schemas) {
+		return Schema{}, ErrNotFound
+	}
+	return m.schemas[id-1], nil
+}
+
+// Subjects mengembalikan ID schema per versi untuk subject
+func (m *Memory) Subjects(subject string) []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int(nil), m.subjects[subject]...)
+}
+
+// Requests mengembalikan jumlah request HTTP yang diterima (untuk menguji cache Client)
+func (m *Memory) Requests() int64 {
+	return m.requests.Load()
+}
+
+// ServeHTTP melayani POST /subjects/{subject}/versions dan GET /schemas/ids/{id}
+func (m *Memory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.requests.Add(1)
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "subjects/") && strings.HasSuffix(path, "/versions"):
+		subject := strings.TrimSuffix(strings.TrimPrefix(path, "subjects/"), "/versions")
+		var req struct {
+			Schema     string `json:"schema"`
+			SchemaType string `json:"schemaType"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Schema == "" {
+			writeRegistryError(w, http.StatusUnprocessableEntity, 42201, "schema tidak valid")
+			return
+		}
+		id, _ := m.Register(r.Context(), subject, Schema{Type: req.SchemaType, Schema: req.Schema})
+		json.NewEncoder(w).Encode(map[string]int{"id": id})
+
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "schemas/ids/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(path, "schemas/ids/"))
+		if err != nil {
+			writeRegistryError(w, http.StatusNotFound, 40403, "schema tidak ditemukan")
+			return
+		}
+		schema, err := m.SchemaByID(r.Context(), id)
+		if err != nil {
+			writeRegistryError(w, http.StatusNotFound, 40403, "schema tidak ditemukan")
+			return
+		}
+		resp := map[string]string{"schema": schema.Schema}
+		if schema.Type != TypeAvro {
+			resp["schemaType"] = schema.Type
+		}
+		json.NewEncoder(w).Encode(resp)
+
+	default:
+		writeRegistryError(w, http.StatusNotFound, 404, "endpoint tidak didukung stub registry")
+	}
+}
+
+func writeRegistryError(w http.ResponseWriter, status, code int, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"error_code": code, "message": message})
+}
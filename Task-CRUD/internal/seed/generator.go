@@ -0,0 +1,113 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"Task-CRUD/internal/entity"
+)
+
+var (
+	firstNames = []string{
+		"Budi", "Siti", "Agus", "Dewi", "Rizky", "Putri", "Andi", "Ayu", "Fajar", "Nur",
+		"Dimas", "Intan", "Yoga", "Rina", "Bayu", "Lestari", "Hendra", "Maya", "Arif", "Wulan",
+		"Eko", "Fitri", "Gilang", "Sari", "Joko", "Indah", "Reza", "Nadia", "Teguh", "Kartika",
+	}
+	lastNames = []string{
+		"Santoso", "Rahmawati", "Pratama", "Wijaya", "Saputra", "Hidayat", "Kusuma", "Nugroho", "Setiawan", "Permata",
+		"Siregar", "Nasution", "Halim", "Gunawan", "Utami", "Lubis", "Susanto", "Wibowo", "Anggraini", "Purnomo",
+	}
+	emailDomains = []string{"example.com", "example.org", "example.net"}
+
+	repoPrefixes = []string{
+		"go", "simple", "awesome", "tiny", "fast", "open", "micro", "cloud", "smart", "rest",
+	}
+	repoTopics = []string{
+		"inventory", "payment", "auth", "chat", "crm", "blog", "scheduler", "gateway", "notifier", "analytics",
+		"kasir", "absensi", "ecommerce", "library", "todo", "parking", "booking", "wallet", "survey", "monitoring",
+	}
+	repoSuffixes = []string{"api", "service", "app", "cli", "worker", "sdk", "dashboard", "bot"}
+	repoHosts    = []string{"github.com", "gitlab.com", "bitbucket.org"}
+
+	descriptions = []string{
+		"Layanan %s dengan arsitektur clean architecture",
+		"Prototipe %s untuk kebutuhan internal tim",
+		"Implementasi %s menggunakan Go dan PostgreSQL",
+		"Eksperimen %s dengan Redis dan Kafka",
+		"",
+	}
+)
+
+// Generator menghasilkan data palsu yang deterministik: seed dan indeks yang
+// sama selalu menghasilkan user/repository yang sama, sehingga seed bisa
+// dijalankan ulang tanpa menggandakan data.
+type Generator struct {
+	seed int64
+}
+
+func NewGenerator(seed int64) *Generator {
+	return &Generator{seed: seed}
+}
+
+// rng membuat sumber acak tersendiri per entity supaya hasil tidak
+// bergantung pada urutan pemanggilan.
+func (g *Generator) rng(parts ...int) *rand.Rand {
+	v := g.seed
+	for _, p := range parts {
+		v = v*1_000_003 + int64(p) + 1
+	}
+	return rand.New(rand.NewSource(v))
+}
+
+// User menghasilkan user ke-i
+func (g *Generator) User(i int) entity.User {
+	r := g.rng(i)
+	first := firstNames[r.Intn(len(firstNames))]
+	last := lastNames[r.Intn(len(lastNames))]
+	domain := emailDomains[r.Intn(len(emailDomains))]
+
+	return entity.User{
+		Name:  first + " " + last,
+		Email: fmt.Sprintf("%s.%s%d@%s", strings.ToLower(first), strings.ToLower(last), i+1, domain),
+	}
+}
+
+// Username menghasilkan handle user untuk URL repository
+func (g *Generator) Username(user entity.User) string {
+	local := strings.SplitN(user.Email, "@", 2)[0]
+	return strings.ReplaceAll(local, ".", "")
+}
+
+// Repositories menghasilkan n repository untuk user ke-i. Nama selalu unik per user.
+func (g *Generator) Repositories(i int, user entity.User, n int) []entity.Repository {
+	repos := make([]entity.Repository, 0, n)
+	used := make(map[string]bool, n)
+	host := repoHosts[g.rng(i, -1).Intn(len(repoHosts))]
+	owner := g.Username(user)
+
+	for j := 0; j < n; j++ {
+		r := g.rng(i, j)
+		topic := repoTopics[r.Intn(len(repoTopics))]
+		name := fmt.Sprintf("%s-%s-%s",
+			repoPrefixes[r.Intn(len(repoPrefixes))], topic, repoSuffixes[r.Intn(len(repoSuffixes))])
+		if used[name] {
+			name = fmt.Sprintf("%s-%d", name, j+1)
+		}
+		used[name] = true
+
+		desc := descriptions[r.Intn(len(descriptions))]
+		if desc != "" {
+			desc = fmt.Sprintf(desc, topic)
+		}
+
+		repos = append(repos, entity.Repository{
+			Name:        name,
+			UserID:      user.ID,
+			URL:         fmt.Sprintf("https://%s/%s/%s", host, owner, name),
+			AIEnabled:   r.Intn(4) == 0,
+			Description: desc,
+		})
+	}
+	return repos
+}
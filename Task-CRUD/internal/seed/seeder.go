@@ -0,0 +1,205 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"Task-CRUD/internal/entity"
+	interfaces "Task-CRUD/internal/interfaces"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// Options mengatur jumlah data dan cara penulisan seed
+type Options struct {
+	Users        int   // jumlah user yang dibuat
+	ReposPerUser int   // jumlah repository per user
+	Seed         int64 // nilai seed; nilai sama menghasilkan data yang sama
+	BatchSize    int   // ukuran batch insert (default 100)
+}
+
+// Result merangkum hasil seed
+type Result struct {
+	UsersCreated int
+	UsersSkipped int
+	ReposCreated int
+	ReposSkipped int
+}
+
+// Seeder menulis data hasil Generator ke database.
+// Tanpa usecase, data ditulis langsung lewat repository dengan batch insert.
+// Dengan usecase, setiap entity melewati validasi, invalidasi cache dan event Kafka.
+type Seeder struct {
+	userRepo interfaces.UserBatchRepositoryInterface
+	repoRepo interfaces.RepoBatchRepositoryInterface
+	userUC   interfaces.UserUseCaseInterface
+	repoUC   interfaces.RepoUseCaseInterface
+}
+
+func NewSeeder(
+	userRepo interfaces.UserBatchRepositoryInterface,
+	repoRepo interfaces.RepoBatchRepositoryInterface,
+) *Seeder {
+	return &Seeder{userRepo: userRepo, repoRepo: repoRepo}
+}
+
+func NewSeederWithUseCase(
+	userRepo interfaces.UserBatchRepositoryInterface,
+	repoRepo interfaces.RepoBatchRepositoryInterface,
+	userUC interfaces.UserUseCaseInterface,
+	repoUC interfaces.RepoUseCaseInterface,
+) *Seeder {
+	return &Seeder{userRepo: userRepo, repoRepo: repoRepo, userUC: userUC, repoUC: repoUC}
+}
+
+// Run membuat data per batch user. Data yang sudah ada (email user, atau
+// pasangan user + nama repository) dilewati sehingga Run idempotent.
+func (s *Seeder) Run(ctx context.Context, opts Options) (Result, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Seeder.Run")
+	defer span.Finish()
+
+	var res Result
+	if opts.Users < 0 || opts.ReposPerUser < 0 {
+		return res, errors.New("jumlah user/repository tidak boleh negatif")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+
+	gen := NewGenerator(opts.Seed)
+	for start := 0; start < opts.Users; start += opts.BatchSize {
+		end := min(start+opts.BatchSize, opts.Users)
+
+		users, err := s.seedUsers(ctx, gen, start, end, &res)
+		if err != nil {
+			return res, err
+		}
+		if err := s.seedRepos(ctx, gen, start, users, opts, &res); err != nil {
+			return res, err
+		}
+		log.Printf("🌱 Seed batch user %d-%d selesai", start+1, end)
+	}
+
+	if s.userUC != nil {
+		// Isi ulang cache list setelah semua data masuk
+		if _, err := s.userUC.GetUsers(ctx); err != nil {
+			log.Printf("⚠️ Gagal mengisi cache users: %v", err)
+		}
+		if _, err := s.repoUC.GetAllRepos(ctx); err != nil {
+			log.Printf("⚠️ Gagal mengisi cache repositories: %v", err)
+		}
+	}
+
+	return res, nil
+}
+
+// seedUsers membuat user indeks [start, end) yang belum ada dan mengembalikan
+// semua user pada rentang tersebut (lengkap dengan ID) sesuai urutan indeks.
+func (s *Seeder) seedUsers(ctx context.Context, gen *Generator, start, end int, res *Result) ([]entity.User, error) {
+	users := make([]entity.User, 0, end-start)
+	emails := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		u := gen.User(i)
+		users = append(users, u)
+		emails = append(emails, u.Email)
+	}
+
+	existing, err := s.userIDsByEmail(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []entity.User
+	for _, u := range users {
+		if _, ok := existing[u.Email]; ok {
+			res.UsersSkipped++
+			continue
+		}
+		missing = append(missing, u)
+	}
+
+	if s.userUC != nil {
+		for i := range missing {
+			if err := s.userUC.CreateUser(ctx, &missing[i]); err != nil {
+				return nil, fmt.Errorf("gagal membuat user %s: %w", missing[i].Email, err)
+			}
+		}
+	} else if err := s.userRepo.CreateUsersBatch(ctx, missing, len(missing)); err != nil {
+		return nil, fmt.Errorf("gagal batch insert user: %w", err)
+	}
+	res.UsersCreated += len(missing)
+
+	// Ambil ulang ID dari database supaya konsisten untuk user lama maupun baru
+	ids, err := s.userIDsByEmail(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		id, ok := ids[users[i].Email]
+		if !ok {
+			return nil, fmt.Errorf("user %s tidak ditemukan setelah insert", users[i].Email)
+		}
+		users[i].ID = id
+	}
+	return users, nil
+}
+
+func (s *Seeder) seedRepos(ctx context.Context, gen *Generator, start int, users []entity.User, opts Options, res *Result) error {
+	if opts.ReposPerUser == 0 {
+		return nil
+	}
+
+	userIDs := make([]uint, len(users))
+	for i, u := range users {
+		userIDs[i] = u.ID
+	}
+	existingRepos, err := s.repoRepo.GetRepositoriesByUserIDs(ctx, userIDs)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil repository yang sudah ada: %w", err)
+	}
+	exists := make(map[string]bool, len(existingRepos))
+	for _, r := range existingRepos {
+		exists[repoKey(r.UserID, r.Name)] = true
+	}
+
+	var missing []entity.Repository
+	for i, u := range users {
+		for _, r := range gen.Repositories(start+i, u, opts.ReposPerUser) {
+			if exists[repoKey(r.UserID, r.Name)] {
+				res.ReposSkipped++
+				continue
+			}
+			missing = append(missing, r)
+		}
+	}
+
+	if s.repoUC != nil {
+		for i := range missing {
+			if err := s.repoUC.CreateRepo(ctx, &missing[i]); err != nil {
+				return fmt.Errorf("gagal membuat repository %s: %w", missing[i].Name, err)
+			}
+		}
+	} else if err := s.repoRepo.CreateRepositoriesBatch(ctx, missing, opts.BatchSize); err != nil {
+		return fmt.Errorf("gagal batch insert repository: %w", err)
+	}
+	res.ReposCreated += len(missing)
+	return nil
+}
+
+func (s *Seeder) userIDsByEmail(ctx context.Context, emails []string) (map[string]uint, error) {
+	users, err := s.userRepo.GetUsersByEmails(ctx, emails)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil user berdasarkan email: %w", err)
+	}
+	ids := make(map[string]uint, len(users))
+	for _, u := range users {
+		ids[u.Email] = u.ID
+	}
+	return ids, nil
+}
+
+func repoKey(userID uint, name string) string {
+	return fmt.Sprintf("%d/%s", userID, name)
+}
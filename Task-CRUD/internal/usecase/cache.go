@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"Task-CRUD/internal/cache"
+	"Task-CRUD/internal/entity"
+	"Task-CRUD/internal/events"
+)
+
+// Namespace cache yang dipakai usecase
+const (
+	NamespaceRepositories = "repositories" // list semua repository, key "all"
+	NamespaceRepository   = "repository"   // satu repository, key = ID
+	NamespaceUsers        = "users"        // list semua user, key "all"
+	NamespaceUser         = "user"         // satu user, key = ID
+	NamespaceDeps         = "deps"         // index dependency antar entry cache
+)
+
+const cacheKeyAll = "all"
+
+// Caches mengelompokkan cache bertipe yang dipakai bersama oleh RepoUseCase dan UserUseCase
+type Caches struct {
+	Repositories cache.Cache[[]entity.Repository]
+	Repository   cache.Cache[entity.Repository]
+	Users        cache.Cache[[]entity.User]
+	User         cache.Cache[entity.User]
+
+	// Deps mencatat entry repository mana yang menyimpan salinan user tertentu
+	Deps cache.DependencyIndex
+
+	// backend (store dan cfg) dipakai CacheAdminUseCase untuk scan/inspect key
+	backend  atomic.Pointer[cacheBackend]
+	switches *cacheSwitches // nil jika Caches tidak dibuat dengan NewSwitchableCaches
+}
+
+type cacheBackend struct {
+	store cache.Store
+	cfg   cache.Config
+}
+
+type cacheSwitches struct {
+	repositories *cache.Switch[[]entity.Repository]
+	repository   *cache.Switch[entity.Repository]
+	users        *cache.Switch[[]entity.User]
+	user         *cache.Switch[entity.User]
+	deps         *cache.SwitchDependencyIndex
+}
+
+// NewCaches membuat semua cache di atas satu store. Store nil berarti cache tidak aktif.
+func NewCaches(store cache.Store, deps cache.DependencyIndex, cfg cache.Config) *Caches {
+	if store == nil {
+		return NoCaches()
+	}
+	if deps == nil {
+		deps = cache.NewNoopDependencyIndex()
+	}
+	c := &Caches{
+		Repositories: cache.New[[]entity.Repository](store, cfg, NamespaceRepositories),
+		Repository:   cache.New[entity.Repository](store, cfg, NamespaceRepository),
+		Users:        cache.New[[]entity.User](store, cfg, NamespaceUsers),
+		User:         cache.New[entity.User](store, cfg, NamespaceUser),
+		Deps:         deps,
+	}
+	c.backend.Store(&cacheBackend{store: store, cfg: cfg})
+	return c
+}
+
+// NoCaches mengembalikan Caches yang selalu miss
+func NoCaches() *Caches {
+	c := &Caches{
+		Repositories: cache.NewNoop[[]entity.Repository](),
+		Repository:   cache.NewNoop[entity.Repository](),
+		Users:        cache.NewNoop[[]entity.User](),
+		User:         cache.NewNoop[entity.User](),
+		Deps:         cache.NewNoopDependencyIndex(),
+	}
+	c.backend.Store(&cacheBackend{})
+	return c
+}
+
+// NewSwitchableCaches membungkus initial supaya isinya bisa diganti dengan Swap,
+// dipakai saat backend cache (Redis) bisa hilang dan kembali selama service berjalan.
+func NewSwitchableCaches(initial *Caches) *Caches {
+	sw := &cacheSwitches{
+		repositories: cache.NewSwitch(initial.Repositories),
+		repository:   cache.NewSwitch(initial.Repository),
+		users:        cache.NewSwitch(initial.Users),
+		user:         cache.NewSwitch(initial.User),
+		deps:         cache.NewSwitchDependencyIndex(initial.Deps),
+	}
+	c := &Caches{
+		Repositories: sw.repositories,
+		Repository:   sw.repository,
+		Users:        sw.users,
+		User:         sw.user,
+		Deps:         sw.deps,
+		switches:     sw,
+	}
+	c.backend.Store(initial.backend.Load())
+	return c
+}
+
+// Swap mengganti isi Caches hasil NewSwitchableCaches dengan next
+func (c *Caches) Swap(next *Caches) {
+	if c.switches == nil {
+		panic("usecase: Swap hanya untuk Caches dari NewSwitchableCaches")
+	}
+	c.switches.repositories.Use(next.Repositories)
+	c.switches.repository.Use(next.Repository)
+	c.switches.users.Use(next.Users)
+	c.switches.user.Use(next.User)
+	c.switches.deps.Use(next.Deps)
+	c.backend.Store(next.backend.Load())
+}
+
+func idKey(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func userDependency(userID uint) string {
+	return cache.Ref(NamespaceUser, idKey(userID))
+}
+
+// TrackRepository mencatat bahwa cache repository:<id> menyimpan salinan user pemiliknya
+func (c *Caches) TrackRepository(ctx context.Context, repo *entity.Repository) error {
+	return c.Deps.Add(ctx, userDependency(repo.UserID), cache.Ref(NamespaceRepository, idKey(repo.ID)))
+}
+
+// InvalidateRepositories menghapus list repository dan entry repository dengan ID yang diberikan
+func (c *Caches) InvalidateRepositories(ctx context.Context, ids ...uint) error {
+	errList := c.Repositories.Delete(ctx, cacheKeyAll)
+	if len(ids) > 0 {
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = idKey(id)
+		}
+		errList = errors.Join(errList, c.Repository.Delete(ctx, keys...))
+	}
+	return errList
+}
+
+// InvalidateNewUser menghapus list user dan entry negatif untuk ID user yang baru dibuat
+func (c *Caches) InvalidateNewUser(ctx context.Context, id uint) error {
+	return errors.Join(c.Users.Delete(ctx, cacheKeyAll), c.User.Delete(ctx, idKey(id)))
+}
+
+// InvalidateUser menghapus cache user beserta semua entry repository yang menyimpan
+// salinan user tersebut (list repository selalu ikut dihapus karena memuat semua user).
+// Tanpa ID, hanya list user yang dihapus.
+func (c *Caches) InvalidateUser(ctx context.Context, ids ...uint) error {
+	err := c.Users.Delete(ctx, cacheKeyAll)
+	if len(ids) == 0 {
+		return err
+	}
+
+	var repoIDs []uint
+	for _, id := range ids {
+		err = errors.Join(err, c.User.Delete(ctx, idKey(id)))
+
+		refs, popErr := c.Deps.Pop(ctx, userDependency(id))
+		if popErr != nil {
+			err = errors.Join(err, popErr)
+			continue
+		}
+		for _, ref := range refs {
+			ns, key, ok := cache.SplitRef(ref)
+			if !ok || ns != NamespaceRepository {
+				continue
+			}
+			if repoID, parseErr := strconv.ParseUint(key, 10, 32); parseErr == nil {
+				repoIDs = append(repoIDs, uint(repoID))
+			}
+		}
+	}
+
+	return errors.Join(err, c.InvalidateRepositories(ctx, repoIDs...))
+}
+
+// InvalidateForEvent menghapus cache yang terdampak event repository/user.
+// Dipakai consumer supaya instance lain (mis. worker di host berbeda) ikut
+// membuang cache setelah perubahan yang ditulis instance lain.
+func (c *Caches) InvalidateForEvent(ctx context.Context, event events.Event) error {
+	kind, rawID, ok := strings.Cut(event.Subject, "/")
+	if !ok {
+		return fmt.Errorf("subject event tidak valid: %q", event.Subject)
+	}
+	id, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil {
+		return fmt.Errorf("subject event tidak valid: %q", event.Subject)
+	}
+
+	switch kind {
+	case "repository":
+		return c.InvalidateRepositories(ctx, uint(id))
+	case "user":
+		if event.Name() == events.UserCreated {
+			return c.InvalidateNewUser(ctx, uint(id))
+		}
+		return c.InvalidateUser(ctx, uint(id))
+	}
+	return nil
+}
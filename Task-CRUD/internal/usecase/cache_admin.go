@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"Task-CRUD/internal/cache"
+	interfaces "Task-CRUD/internal/interfaces"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// ErrCacheAdminUnsupported dikembalikan jika backend cache tidak mendukung scan (mis. cache mati)
+var ErrCacheAdminUnsupported = errors.New("backend cache tidak mendukung operasi admin")
+
+// ErrUnknownNamespace dikembalikan untuk namespace di luar CacheNamespaces
+var ErrUnknownNamespace = errors.New("namespace cache tidak dikenal")
+
+// ErrInvalidCacheRequest dikembalikan untuk parameter admin yang tidak lengkap
+var ErrInvalidCacheRequest = errors.New("permintaan admin cache tidak valid")
+
+// CacheNamespaces adalah namespace yang bisa dikelola lewat admin API
+var CacheNamespaces = []string{NamespaceRepositories, NamespaceRepository, NamespaceUsers, NamespaceUser}
+
+type CacheAdminUseCase struct {
+	caches *Caches
+	repoUC interfaces.RepoUseCaseInterface
+	userUC interfaces.UserUseCaseInterface
+}
+
+func NewCacheAdminUseCase(
+	caches *Caches,
+	repoUC interfaces.RepoUseCaseInterface,
+	userUC interfaces.UserUseCaseInterface,
+) interfaces.CacheAdminUseCaseInterface {
+	if caches == nil {
+		caches = NoCaches()
+	}
+	return &CacheAdminUseCase{caches: caches, repoUC: repoUC, userUC: userUC}
+}
+
+// ListKeys mengembalikan key (tanpa prefix namespace) yang cocok dengan pattern, maksimal limit
+func (uc *CacheAdminUseCase) ListKeys(ctx context.Context, namespace, pattern string, limit int) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "CacheAdminUseCase.ListKeys")
+	defer span.Finish()
+
+	scanner, prefix, err := uc.scanner(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	keys := []string{}
+	err = scanner.Scan(ctx, prefix+pattern, func(batch []string) error {
+		for _, full := range batch {
+			key := strings.TrimPrefix(full, prefix)
+			// Lewati key pendukung seperti "<id>:lock"
+			if strings.Contains(key, ":") {
+				continue
+			}
+			keys = append(keys, key)
+			if limit > 0 && len(keys) >= limit {
+				return cache.ErrStopScan
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		span.LogFields(log.Error(err))
+		return nil, fmt.Errorf("scan key cache gagal: %w", err)
+	}
+	return keys, nil
+}
+
+// Inspect mengembalikan isi entry beserta sisa TTL-nya
+func (uc *CacheAdminUseCase) Inspect(ctx context.Context, namespace, key string) (*cache.EntryInfo, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "CacheAdminUseCase.Inspect")
+	defer span.Finish()
+
+	scanner, prefix, err := uc.scanner(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok, err := uc.caches.backend.Load().store.Get(ctx, prefix+key)
+	if err != nil {
+		span.LogFields(log.Error(err))
+		return nil, fmt.Errorf("baca entry cache gagal: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	ttl, _, err := scanner.TTL(ctx, prefix+key)
+	if err != nil {
+		span.LogFields(log.Error(err))
+		return nil, fmt.Errorf("baca TTL cache gagal: %w", err)
+	}
+
+	info, err := cache.Describe(key, data, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("entry cache rusak: %w", err)
+	}
+	return &info, nil
+}
+
+// DeleteKeys menghapus key lewat cache bertipe supaya L1 dan replica lain ikut dibuang
+func (uc *CacheAdminUseCase) DeleteKeys(ctx context.Context, namespace string, keys ...string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "CacheAdminUseCase.DeleteKeys")
+	defer span.Finish()
+
+	del, err := uc.caches.deleter(namespace)
+	if err != nil {
+		return err
+	}
+	if err := del(ctx, keys...); err != nil {
+		span.LogFields(log.Error(err))
+		return fmt.Errorf("hapus key cache gagal: %w", err)
+	}
+	return nil
+}
+
+// DeletePattern menghapus semua key dalam namespace yang cocok dengan pattern (SCAN per batch)
+func (uc *CacheAdminUseCase) DeletePattern(ctx context.Context, namespace, pattern string) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "CacheAdminUseCase.DeletePattern")
+	defer span.Finish()
+
+	scanner, prefix, err := uc.scanner(namespace)
+	if err != nil {
+		return 0, err
+	}
+	del, err := uc.caches.deleter(namespace)
+	if err != nil {
+		return 0, err
+	}
+	if pattern == "" {
+		return 0, fmt.Errorf("%w: pattern tidak boleh kosong", ErrInvalidCacheRequest)
+	}
+
+	deleted := 0
+	err = scanner.Scan(ctx, prefix+pattern, func(batch []string) error {
+		keys := make([]string, 0, len(batch))
+		for _, full := range batch {
+			key := strings.TrimPrefix(full, prefix)
+			// Key pendukung seperti "<id>:lock" milik fetch yang sedang berjalan
+			if strings.Contains(key, ":") {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		if err := del(ctx, keys...); err != nil {
+			return err
+		}
+		deleted += len(keys)
+		return nil
+	})
+	if err != nil {
+		span.LogFields(log.Error(err))
+		return deleted, fmt.Errorf("hapus pattern cache gagal: %w", err)
+	}
+	return deleted, nil
+}
+
+// Warm memuat ulang entry dari database. Untuk namespace entity (repository/user)
+// ids wajib diisi; untuk namespace list, ids diabaikan.
+func (uc *CacheAdminUseCase) Warm(ctx context.Context, namespace string, ids ...uint) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "CacheAdminUseCase.Warm")
+	defer span.Finish()
+
+	switch namespace {
+	case NamespaceRepositories:
+		if err := uc.caches.Repositories.Delete(ctx, cacheKeyAll); err != nil {
+			return 0, err
+		}
+		_, err := uc.repoUC.GetAllRepos(ctx)
+		return boolToCount(err == nil), err
+	case NamespaceUsers:
+		if err := uc.caches.Users.Delete(ctx, cacheKeyAll); err != nil {
+			return 0, err
+		}
+		_, err := uc.userUC.GetUsers(ctx)
+		return boolToCount(err == nil), err
+	case NamespaceRepository, NamespaceUser:
+	default:
+		return 0, ErrUnknownNamespace
+	}
+
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("%w: ids wajib diisi untuk namespace %s", ErrInvalidCacheRequest, namespace)
+	}
+
+	warmed := 0
+	for _, id := range ids {
+		var err error
+		if namespace == NamespaceRepository {
+			if err = uc.caches.Repository.Delete(ctx, idKey(id)); err == nil {
+				_, err = uc.repoUC.GetRepositoryByID(ctx, id)
+			}
+		} else {
+			if err = uc.caches.User.Delete(ctx, idKey(id)); err == nil {
+				_, err = uc.userUC.GetUserByID(ctx, id)
+			}
+		}
+		if err != nil {
+			span.LogFields(log.Error(err))
+			return warmed, fmt.Errorf("warm %s %d gagal: %w", namespace, id, err)
+		}
+		warmed++
+	}
+	return warmed, nil
+}
+
+// Stats mengembalikan statistik cache per namespace
+func (uc *CacheAdminUseCase) Stats() map[string]cache.NamespaceStats {
+	if stats, ok := uc.caches.backend.Load().cfg.Metrics.(*cache.Stats); ok {
+		return stats.Snapshot()
+	}
+	return cache.DefaultStats.Snapshot()
+}
+
+func (uc *CacheAdminUseCase) scanner(namespace string) (cache.Scanner, string, error) {
+	if !knownNamespace(namespace) {
+		return nil, "", ErrUnknownNamespace
+	}
+	backend := uc.caches.backend.Load()
+	scanner, ok := backend.store.(cache.Scanner)
+	if !ok {
+		return nil, "", ErrCacheAdminUnsupported
+	}
+	return scanner, backend.cfg.KeyPrefix(namespace), nil
+}
+
+// deleter mengembalikan Delete milik cache bertipe untuk namespace
+func (c *Caches) deleter(namespace string) (func(ctx context.Context, keys ...string) error, error) {
+	switch namespace {
+	case NamespaceRepositories:
+		return c.Repositories.Delete, nil
+	case NamespaceRepository:
+		return c.Repository.Delete, nil
+	case NamespaceUsers:
+		return c.Users.Delete, nil
+	case NamespaceUser:
+		return c.User.Delete, nil
+	}
+	return nil, ErrUnknownNamespace
+}
+
+func knownNamespace(namespace string) bool {
+	for _, ns := range CacheNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func boolToCount(ok bool) int {
+	if ok {
+		return 1
+	}
+	return 0
+}
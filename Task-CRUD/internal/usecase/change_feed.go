@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"Task-CRUD/internal/entity"
+	"Task-CRUD/internal/events"
+	interfaces "Task-CRUD/internal/interfaces"
+)
+
+// changeEventNames memetakan tabel dan operasi trigger ke tipe aggregate dan nama event
+var changeEventNames = map[string]struct {
+	aggregate string
+	names     map[string]string
+}{
+	"repositories": {"repository", map[string]string{
+		entity.DBChangeInsert: events.RepositoryCreated,
+		entity.DBChangeUpdate: events.RepositoryUpdated,
+		entity.DBChangeDelete: events.RepositoryDeleted,
+	}},
+	"users": {"user", map[string]string{
+		entity.DBChangeInsert: events.UserCreated,
+		entity.DBChangeUpdate: events.UserUpdated,
+		entity.DBChangeDelete: events.UserDeleted,
+	}},
+}
+
+// ChangeFeed mengubah perubahan users/repositories yang ditulis langsung ke
+// database (migrasi, psql, service lain) menjadi invalidasi cache dan domain
+// event, sama seperti perubahan lewat RepoUseCase/UserUseCase. Notifikasi
+// LISTEN hanya pemicu; sumbernya tabel db_changes, sehingga setelah koneksi
+// putus feed tersambung ulang lalu memproses semua perubahan yang tertinggal.
+// Aman dijalankan di beberapa replica (lihat ChangeFeedRepositoryInterface).
+type ChangeFeed struct {
+	Changes  interfaces.ChangeFeedRepositoryInterface
+	Listener interfaces.ChangeListener
+	Caches   *Caches
+	Pipeline *EventPipeline // nil berarti event dimatikan; cache tetap di-invalidate
+
+	PollInterval    time.Duration // sinkronisasi berkala meskipun tidak ada notifikasi (default 30s)
+	BatchSize       int           // default 100
+	MinBackoff      time.Duration // jeda reconnect awal (default 1s)
+	MaxBackoff      time.Duration // default 30s
+	Retention       time.Duration // umur perubahan yang sudah diproses sebelum dihapus (default 24h)
+	CleanupInterval time.Duration // default 10m
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Start menjalankan feed di background sampai Stop
+func (f *ChangeFeed) Start() {
+	f.defaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	f.done = make(chan struct{})
+	go f.run(ctx)
+}
+
+// Stop menutup koneksi LISTEN setelah batch yang sedang berjalan selesai
+func (f *ChangeFeed) Stop() {
+	if f.cancel == nil {
+		return
+	}
+	f.cancel()
+	<-f.done
+}
+
+func (f *ChangeFeed) run(ctx context.Context) {
+	defer close(f.done)
+
+	failures := 0
+	for ctx.Err() == nil {
+		if failures > 0 {
+			wait := f.backoff(failures)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+
+		sub, err := f.Listener.Listen(ctx)
+		if err != nil {
+			failures++
+			if ctx.Err() == nil {
+				log.Printf("⚠️ Change feed gagal LISTEN (percobaan %d): %v", failures, err)
+			}
+			continue
+		}
+		if failures > 0 {
+			log.Println("✅ Change feed tersambung kembali, sinkronisasi ulang perubahan yang tertinggal")
+		}
+		failures = 0
+
+		err = f.listen(ctx, sub)
+		if closeErr := sub.Close(); closeErr != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Gagal menutup koneksi change feed: %v", closeErr)
+		}
+		if ctx.Err() == nil {
+			log.Printf("⚠️ Koneksi change feed putus: %v", err)
+			failures++
+		}
+	}
+}
+
+// listen memproses semua perubahan tertunda (sinkronisasi setelah tersambung),
+// lalu memproses lagi setiap ada notifikasi atau setiap PollInterval. Kembali
+// saat koneksi putus atau ctx dibatalkan.
+func (f *ChangeFeed) listen(ctx context.Context, sub interfaces.ChangeSubscription) error {
+	lastCleanup := time.Now()
+	for {
+		if err := f.drain(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Change feed gagal memproses perubahan: %v", err)
+		}
+
+		if time.Since(lastCleanup) >= f.CleanupInterval {
+			lastCleanup = time.Now()
+			if n, err := f.Changes.DeleteProcessed(ctx, time.Now().Add(-f.Retention)); err != nil {
+				log.Printf("⚠️ Gagal membersihkan db_changes: %v", err)
+			} else if n > 0 {
+				log.Printf("🧹 %d perubahan database yang sudah diproses dibersihkan", n)
+			}
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, f.PollInterval)
+		err := sub.Wait(waitCtx)
+		timedOut := waitCtx.Err() != nil
+		cancel()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Timeout bukan kegagalan: koneksi tetap dipakai dan perubahan dicek lagi
+		if err != nil && !timedOut {
+			return err
+		}
+	}
+}
+
+// drain memproses batch sampai tidak ada lagi perubahan tertunda
+func (f *ChangeFeed) drain(ctx context.Context) error {
+	for {
+		claimed, err := f.RunOnce(ctx)
+		if err != nil || claimed < f.BatchSize {
+			return err
+		}
+	}
+}
+
+// RunOnce memproses satu batch perubahan dan mengembalikan jumlahnya. Event
+// ditulis ke outbox di transaksi yang sama dengan penandaan perubahan, cache
+// di-invalidate setelah commit.
+func (f *ChangeFeed) RunOnce(ctx context.Context) (int, error) {
+	f.defaults()
+
+	var changed []events.Event
+	claimed, err := f.Changes.ProcessPending(ctx, f.BatchSize, func(ctx context.Context, changes []entity.DBChange) error {
+		changed = changed[:0]
+		for _, c := range changes {
+			aggregateType, event, err := changeEvent(ctx, c)
+			if err != nil {
+				// Baris yang tidak bisa dibaca tidak akan pernah berhasil; jangan tahan antrean
+				log.Printf("⚠️ Perubahan database %d dilewati: %v", c.ID, err)
+				continue
+			}
+			if err := f.Pipeline.Stage(ctx, aggregateType, uint(c.RowID), event); err != nil {
+				return err
+			}
+			changed = append(changed, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range changed {
+		if err := f.Caches.InvalidateForEvent(ctx, event); err != nil {
+			log.Printf("⚠️ Gagal invalidate cache untuk %s: %v", event.Subject, err)
+		}
+		if err := f.Pipeline.Publish(ctx, event); err != nil {
+			log.Printf("⚠️ Gagal mengirim event %s: %v", event.Name(), err)
+		}
+	}
+	if len(changed) > 0 {
+		log.Printf("🔄 %d perubahan database di luar service diproses", len(changed))
+	}
+	return claimed, nil
+}
+
+// changeEvent membuat domain event dari satu baris db_changes. Causation event
+// adalah baris db_changes tersebut.
+func changeEvent(ctx context.Context, c entity.DBChange) (string, events.Event, error) {
+	mapping, ok := changeEventNames[c.Table]
+	if !ok {
+		return "", events.Event{}, fmt.Errorf("tabel %q tidak dikenal", c.Table)
+	}
+	name, ok := mapping.names[c.Operation]
+	if !ok {
+		return "", events.Event{}, fmt.Errorf("operasi %q tidak dikenal", c.Operation)
+	}
+
+	var before, after interface{}
+	var err error
+	switch mapping.aggregate {
+	case "repository":
+		if before, err = decodeRow[entity.Repository](c.OldData); err == nil {
+			after, err = decodeRow[entity.Repository](c.NewData)
+		}
+	case "user":
+		if before, err = decodeRow[entity.User](c.OldData); err == nil {
+			after, err = decodeRow[entity.User](c.NewData)
+		}
+	}
+	if err != nil {
+		return "", events.Event{}, fmt.Errorf("data %s tidak valid: %w", c.Table, err)
+	}
+
+	ctx = events.WithCausationID(ctx, fmt.Sprintf("db_changes/%d", c.ID))
+	event, err := events.New(ctx, name, fmt.Sprintf("%s/%d", mapping.aggregate, c.RowID), before, after)
+	return mapping.aggregate, event, err
+}
+
+// decodeRow membaca to_jsonb baris; data kosong menghasilkan nil (bukan pointer nil bertipe)
+func decodeRow[T any](raw json.RawMessage) (interface{}, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	row := new(T)
+	if err := json.Unmarshal(raw, row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// backoff eksponensial: MinBackoff * 2^(failures-1), maksimal MaxBackoff
+func (f *ChangeFeed) backoff(failures int) time.Duration {
+	d := f.MinBackoff
+	for i := 1; i < failures && d < f.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, f.MaxBackoff)
+}
+
+func (f *ChangeFeed) defaults() {
+	if f.PollInterval <= 0 {
+		f.PollInterval = 30 * time.Second
+	}
+	if f.BatchSize <= 0 {
+		f.BatchSize = 100
+	}
+	if f.MinBackoff <= 0 {
+		f.MinBackoff = time.Second
+	}
+	if f.MaxBackoff <= 0 {
+		f.MaxBackoff = 30 * time.Second
+	}
+	if f.Retention <= 0 {
+		f.Retention = 24 * time.Hour
+	}
+	if f.CleanupInterval <= 0 {
+		f.CleanupInterval = 10 * time.Minute
+	}
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"Task-CRUD/internal/events"
+	interfaces "Task-CRUD/internal/interfaces"
+)
+
+// EventPipeline adalah jalur pengiriman domain event yang dipakai bersama oleh
+// usecase repository dan user. Dengan outbox, event ditulis di transaksi yang
+// sama dengan perubahan data lalu dikirim oleh OutboxRelay; tanpa outbox, event
+// dikirim langsung lewat publisher setelah commit. Pipeline nil berarti event dimatikan.
+type EventPipeline struct {
+	tx        interfaces.Transactor
+	outbox    interfaces.OutboxRepositoryInterface
+	publisher interfaces.EventPublisher
+}
+
+// NewEventPipeline mengirim event langsung lewat publisher (dipakai CLI dan test)
+func NewEventPipeline(publisher interfaces.EventPublisher) *EventPipeline {
+	return &EventPipeline{publisher: publisher}
+}
+
+// NewOutboxPipeline menulis event ke outbox di dalam transaksi tx (dipakai server)
+func NewOutboxPipeline(tx interfaces.Transactor, outbox interfaces.OutboxRepositoryInterface) *EventPipeline {
+	return &EventPipeline{tx: tx, outbox: outbox}
+}
+
+// Atomically menjalankan fn dalam satu transaksi jika outbox aktif
+func (p *EventPipeline) Atomically(ctx context.Context, fn func(ctx context.Context) error) error {
+	if p == nil || p.outbox == nil || p.tx == nil {
+		return fn(ctx)
+	}
+	return p.tx.WithinTransaction(ctx, fn)
+}
+
+// Enabled melaporkan apakah event benar-benar dikirim (outbox atau publisher);
+// tanpa keduanya usecase tidak perlu membaca before/after untuk event
+func (p *EventPipeline) Enabled() bool {
+	return p != nil && (p.outbox != nil || p.publisher != nil)
+}
+
+// Stage menulis event ke outbox dengan ctx dari Atomically (no-op tanpa outbox)
+func (p *EventPipeline) Stage(ctx context.Context, aggregateType string, id uint, event events.Event) error {
+	if p == nil || p.outbox == nil {
+		return nil
+	}
+	row, err := newOutboxEvent(aggregateType, id, event)
+	if err != nil {
+		return err
+	}
+	return p.outbox.Enqueue(ctx, row)
+}
+
+// Publish dipanggil setelah commit; event langsung dikirim hanya jika outbox tidak aktif.
+// Data sudah tersimpan, jadi kegagalan broker hanya dicatat di log dan tidak
+// dikembalikan ke pemanggil (yang bisa mengira operasi CRUD-nya gagal lalu mengulang).
+func (p *EventPipeline) Publish(ctx context.Context, event events.Event) error {
+	if p == nil || p.outbox != nil || p.publisher == nil {
+		return nil
+	}
+	err := p.publisher.Publish(ctx, event)
+	switch {
+	case errors.Is(err, events.ErrPublisherUnavailable):
+		log.Printf("⚠️ Kafka tidak tersedia, event dilewati: %s", event.Name())
+	case err != nil:
+		log.Printf("❌ Gagal mengirim event %s (%s): %v", event.Name(), event.Subject, err)
+	}
+	return nil
+}
@@ -0,0 +1,440 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Task-CRUD/internal/events"
+	appKafka "Task-CRUD/kafka"
+
+	"github.com/segmentio/kafka-go"
+)
+
+var (
+	// ErrInvalidStreamCursor dikembalikan untuk Last-Event-ID yang tidak bisa dibaca
+	ErrInvalidStreamCursor = errors.New("Last-Event-ID tidak valid")
+	// ErrStreamLagging dikembalikan saat klien terlalu lambat dan antreannya penuh.
+	// Klien cukup tersambung lagi dengan Last-Event-ID terakhir.
+	ErrStreamLagging = errors.New("klien stream tertinggal")
+	// ErrStreamClosed dikembalikan setelah EventStream ditutup (server berhenti)
+	ErrStreamClosed = errors.New("event stream ditutup")
+)
+
+// StreamSource membaca ulang pesan Kafka yang sudah keluar dari buffer, untuk
+// klien yang melanjutkan stream dari cursor lama
+type StreamSource interface {
+	ReadRange(ctx context.Context, topic string, partition int, from, to int64, fn func(kafka.Message) error) error
+}
+
+// StreamFilter memilih event untuk satu klien. Field kosong berarti semua.
+type StreamFilter struct {
+	Types []string // tipe aggregate: repository, user
+	ID    string   // ID aggregate
+	Owner string   // ID user pemilik: user_id repository, atau user itu sendiri
+}
+
+// StreamEvent adalah event untuk klien. ID adalah cursor yang dikirim balik
+// sebagai Last-Event-ID untuk melanjutkan stream dari titik ini.
+type StreamEvent struct {
+	ID    string
+	Event events.Event
+}
+
+// EventStream menyebarkan event dari Kafka ke klien SSE/WebSocket di replica
+// ini. Setiap replica membaca semua partisi (lihat kafka.Tail), jadi klien
+// boleh tersambung ke replica mana pun. Cursor berisi offset terakhir per
+// partisi, sehingga bisa dilanjutkan di replica lain: pesan yang masih ada di
+// buffer dikirim dari memori, yang lebih lama dibaca ulang dari Kafka.
+type EventStream struct {
+	source     StreamSource
+	bufferSize int
+	maxReplay  int64
+
+	mu     sync.Mutex
+	head   streamCursor // offset terakhir yang diterima per partisi
+	start  streamCursor // offset terkecil per partisi yang masih lengkap di buffer
+	buffer []streamItem // ring buffer pesan terbaru
+	subs   map[*StreamSubscription]struct{}
+	closed bool
+}
+
+// NewEventStream membuat hub stream. bufferSize adalah jumlah pesan terbaru
+// yang disimpan di memori; maxReplay batas pesan yang dibaca ulang saat resume.
+func NewEventStream(source StreamSource, bufferSize, maxReplay int) *EventStream {
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+	if maxReplay <= 0 {
+		maxReplay = 10000
+	}
+	return &EventStream{
+		source:     source,
+		bufferSize: bufferSize,
+		maxReplay:  int64(maxReplay),
+		head:       make(streamCursor),
+		start:      make(streamCursor),
+		subs:       make(map[*StreamSubscription]struct{}),
+	}
+}
+
+// Assign mencatat partisi baru; next adalah offset pesan pertama yang akan diterima
+func (s *EventStream) Assign(topic string, partition int, next int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := streamPartition{topic, partition}
+	if _, ok := s.head[p]; !ok {
+		s.head[p] = next - 1
+		s.start[p] = next
+	}
+}
+
+// Publish menerima satu pesan Kafka dan meneruskannya ke semua klien
+func (s *EventStream) Publish(m kafka.Message) {
+	item := newStreamItem(context.Background(), m)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.head[item.pos] = m.Offset
+	if _, ok := s.start[item.pos]; !ok {
+		s.start[item.pos] = m.Offset
+	}
+	s.buffer = append(s.buffer, item)
+	if len(s.buffer) > s.bufferSize {
+		evicted := s.buffer[0]
+		s.start[evicted.pos] = evicted.offset + 1
+		s.buffer = s.buffer[1:]
+	}
+
+	for sub := range s.subs {
+		select {
+		case sub.live <- item:
+		default:
+			// Klien lambat diputus; ia melanjutkan dari cursor terakhirnya
+			delete(s.subs, sub)
+			close(sub.live)
+		}
+	}
+}
+
+// Close memutus semua klien dan menolak langganan baru
+func (s *EventStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for sub := range s.subs {
+		delete(s.subs, sub)
+		close(sub.live)
+	}
+}
+
+// Subscribe membuka langganan dari posisi terkini, atau dari lastEventID jika
+// diisi. Reset pada hasil bernilai true jika cursor terlalu lama untuk
+// dilanjutkan; klien sebaiknya memuat ulang datanya.
+func (s *EventStream) Subscribe(ctx context.Context, filter StreamFilter, lastEventID string) (*StreamSubscription, error) {
+	var from streamCursor
+	if lastEventID != "" {
+		var err error
+		if from, err = parseStreamCursor(lastEventID); err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &StreamSubscription{
+		stream: s,
+		filter: filter,
+		live:   make(chan streamItem, 256),
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrStreamClosed
+	}
+	sub.cursor = s.head.clone()
+	head, start := s.head.clone(), s.start.clone()
+	buffered := append([]streamItem(nil), s.buffer...)
+	s.subs[sub] = struct{}{}
+	s.mu.Unlock()
+
+	if from == nil {
+		return sub, nil
+	}
+
+	// Hitung pesan yang terlewat; partisi yang tidak dikenal klien dimulai dari sekarang
+	var missed int64
+	for p, offset := range head {
+		if last, ok := from[p]; ok && last < offset {
+			missed += offset - last
+		}
+	}
+	if missed > s.maxReplay || (missed > 0 && s.source == nil && !coveredByBuffer(from, start)) {
+		sub.Reset = true
+		return sub, nil
+	}
+
+	// Cursor klien bisa lebih maju dari replica ini; pesan yang sudah dikirim dilewati
+	for p, last := range from {
+		sub.cursor[p] = last
+	}
+	for p := range head {
+		if _, ok := from[p]; !ok {
+			sub.cursor[p] = head[p]
+		}
+	}
+
+	if err := s.replay(ctx, sub, from, head, start); err != nil {
+		// Kafka tidak bisa dibaca; klien diminta memuat ulang daripada kehilangan event diam-diam
+		log.Printf("⚠️ Stream: %v", err)
+		sub.pending = nil
+		sub.cursor = head
+		sub.Reset = true
+		return sub, nil
+	}
+	sub.pending = append(sub.pending, buffered...)
+	return sub, nil
+}
+
+// replay membaca dari Kafka pesan yang terlewat klien tetapi sudah keluar dari buffer
+func (s *EventStream) replay(ctx context.Context, sub *StreamSubscription, from, head, start streamCursor) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	partitions := make([]streamPartition, 0, len(head))
+	for p := range head {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].less(partitions[j]) })
+	for _, p := range partitions {
+		last, ok := from[p]
+		if !ok || last+1 >= start[p] {
+			continue
+		}
+		err := s.source.ReadRange(ctx, p.topic, p.partition, last+1, start[p]-1, func(m kafka.Message) error {
+			sub.pending = append(sub.pending, newStreamItem(ctx, m))
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("gagal membaca ulang %s/%d: %w", p.topic, p.partition, err)
+		}
+	}
+	return nil
+}
+
+func coveredByBuffer(from, start streamCursor) bool {
+	for p, last := range from {
+		if first, ok := start[p]; ok && last+1 < first {
+			return false
+		}
+	}
+	return true
+}
+
+// StreamSubscription adalah langganan satu klien. Next dan Cursor hanya boleh
+// dipanggil dari satu goroutine.
+type StreamSubscription struct {
+	Reset bool // cursor klien tidak bisa dilanjutkan, stream dimulai dari sekarang
+
+	stream  *EventStream
+	filter  StreamFilter
+	cursor  streamCursor
+	pending []streamItem
+	live    chan streamItem
+}
+
+// Next menunggu event berikutnya yang lolos filter
+func (sub *StreamSubscription) Next(ctx context.Context) (StreamEvent, error) {
+	for {
+		var item streamItem
+		if len(sub.pending) > 0 {
+			item, sub.pending = sub.pending[0], sub.pending[1:]
+		} else {
+			select {
+			case <-ctx.Done():
+				return StreamEvent{}, ctx.Err()
+			case it, ok := <-sub.live:
+				if !ok {
+					sub.stream.mu.Lock()
+					closed := sub.stream.closed
+					sub.stream.mu.Unlock()
+					if closed {
+						return StreamEvent{}, ErrStreamClosed
+					}
+					return StreamEvent{}, ErrStreamLagging
+				}
+				item = it
+			}
+		}
+
+		if last, ok := sub.cursor[item.pos]; ok && item.offset <= last {
+			continue
+		}
+		sub.cursor[item.pos] = item.offset
+		if item.event == nil || !sub.filter.matches(item) {
+			continue
+		}
+		return StreamEvent{ID: sub.cursor.String(), Event: *item.event}, nil
+	}
+}
+
+// Cursor mengembalikan posisi terakhir klien, termasuk pesan yang tidak lolos filter
+func (sub *StreamSubscription) Cursor() string {
+	return sub.cursor.String()
+}
+
+// Close berhenti berlangganan
+func (sub *StreamSubscription) Close() {
+	s := sub.stream
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[sub]; ok {
+		delete(s.subs, sub)
+		close(sub.live)
+	}
+}
+
+func (f StreamFilter) matches(item streamItem) bool {
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			if t == item.aggregateType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.ID != "" && f.ID != item.aggregateID {
+		return false
+	}
+	if f.Owner != "" {
+		for _, owner := range item.owners {
+			if owner == f.Owner {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// streamItem adalah pesan Kafka yang sudah di-decode beserta atribut filternya.
+// event nil untuk pesan yang bukan CloudEvent; posisinya tetap dicatat.
+type streamItem struct {
+	pos           streamPartition
+	offset        int64
+	event         *events.Event
+	aggregateType string
+	aggregateID   string
+	owners        []string
+}
+
+func newStreamItem(ctx context.Context, m kafka.Message) streamItem {
+	item := streamItem{pos: streamPartition{m.Topic, m.Partition}, offset: m.Offset}
+	event, err := appKafka.DecodeEvent(ctx, m)
+	if err != nil {
+		log.Printf("⚠️ Stream: pesan %s/%d@%d bukan CloudEvent: %v", m.Topic, m.Partition, m.Offset, err)
+		return item
+	}
+	item.event = &event
+	item.aggregateType, item.aggregateID = event.Aggregate()
+	item.owners = eventOwners(event, item.aggregateType, item.aggregateID)
+	return item
+}
+
+// eventOwners mengembalikan ID user pemilik entity di event: user itu sendiri
+// untuk event user, user_id sebelum/sesudah perubahan untuk entity lain
+func eventOwners(event events.Event, aggregateType, aggregateID string) []string {
+	if aggregateType == "user" {
+		return []string{aggregateID}
+	}
+	var change struct {
+		Before *struct {
+			UserID json.Number `json:"user_id"`
+		} `json:"before"`
+		After *struct {
+			UserID json.Number `json:"user_id"`
+		} `json:"after"`
+	}
+	if err := json.Unmarshal(event.Data, &change); err != nil {
+		return nil
+	}
+	var owners []string
+	if change.Before != nil && change.Before.UserID != "" {
+		owners = append(owners, change.Before.UserID.String())
+	}
+	if change.After != nil && change.After.UserID != "" && (len(owners) == 0 || owners[0] != change.After.UserID.String()) {
+		owners = append(owners, change.After.UserID.String())
+	}
+	return owners
+}
+
+type streamPartition struct {
+	topic     string
+	partition int
+}
+
+func (p streamPartition) less(o streamPartition) bool {
+	if p.topic != o.topic {
+		return p.topic < o.topic
+	}
+	return p.partition < o.partition
+}
+
+// streamCursor menyimpan offset terakhir per partisi. Bentuk teksnya
+// "<topic>:<partition>:<offset>,..." terurut, mis. "repository-topic:0:41,user-topic:2:7".
+type streamCursor map[streamPartition]int64
+
+func (c streamCursor) clone() streamCursor {
+	out := make(streamCursor, len(c))
+	for p, o := range c {
+		out[p] = o
+	}
+	return out
+}
+
+func (c streamCursor) String() string {
+	partitions := make([]streamPartition, 0, len(c))
+	for p := range c {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].less(partitions[j]) })
+
+	parts := make([]string, len(partitions))
+	for i, p := range partitions {
+		parts[i] = p.topic + ":" + strconv.Itoa(p.partition) + ":" + strconv.FormatInt(c[p], 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseStreamCursor(s string) (streamCursor, error) {
+	c := make(streamCursor)
+	for _, part := range strings.Split(s, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ":")
+		if len(fields) != 3 || fields[0] == "" {
+			return nil, ErrInvalidStreamCursor
+		}
+		partition, err := strconv.Atoi(fields[1])
+		if err != nil || partition < 0 {
+			return nil, ErrInvalidStreamCursor
+		}
+		offset, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil || offset < -1 {
+			return nil, ErrInvalidStreamCursor
+		}
+		c[streamPartition{fields[0], partition}] = offset
+	}
+	return c, nil
+}
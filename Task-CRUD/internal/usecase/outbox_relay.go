@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"Task-CRUD/internal/entity"
+	"Task-CRUD/internal/events"
+	interfaces "Task-CRUD/internal/interfaces"
+	appKafka "Task-CRUD/kafka"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// OutboxRelay mengirim event dari tabel outbox ke Kafka. Aman dijalankan di
+// beberapa replica sekaligus (lihat OutboxRepositoryInterface.ProcessPending).
+// Event yang gagal dicoba lagi dengan backoff; event berikutnya dari aggregate
+// yang sama ditahan supaya urutannya tetap. Pengiriman bersifat at-least-once.
+type OutboxRelay struct {
+	Outbox    interfaces.OutboxRepositoryInterface
+	Publisher *appKafka.Publisher
+
+	Interval        time.Duration // jeda polling saat outbox kosong (default 1s)
+	BatchSize       int           // default 100
+	MinBackoff      time.Duration // default 1s
+	MaxBackoff      time.Duration // default 5m
+	Retention       time.Duration // umur event terkirim sebelum dihapus (default 24h)
+	CleanupInterval time.Duration // default 10m
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Start menjalankan relay di background sampai Stop
+func (r *OutboxRelay) Start() {
+	r.defaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.run(ctx)
+}
+
+// Stop menghentikan relay setelah batch yang sedang berjalan selesai
+func (r *OutboxRelay) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+}
+
+func (r *OutboxRelay) run(ctx context.Context) {
+	defer close(r.done)
+
+	lastCleanup := time.Now()
+	for {
+		claimed, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Outbox relay gagal: %v", err)
+		}
+
+		if time.Since(lastCleanup) >= r.CleanupInterval {
+			lastCleanup = time.Now()
+			if n, err := r.Outbox.DeletePublished(ctx, time.Now().Add(-r.Retention)); err != nil {
+				log.Printf("⚠️ Gagal membersihkan outbox: %v", err)
+			} else if n > 0 {
+				log.Printf("🧹 %d event outbox terkirim dibersihkan", n)
+			}
+		}
+
+		// Batch penuh berarti masih ada antrean, langsung lanjut tanpa menunggu
+		wait := r.Interval
+		if err == nil && claimed >= r.BatchSize {
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// RunOnce mengirim satu batch event dan mengembalikan jumlah event yang diproses.
+// Selama Kafka tidak tersedia, outbox tidak disentuh sama sekali.
+func (r *OutboxRelay) RunOnce(ctx context.Context) (int, error) {
+	r.defaults()
+	writer := r.Publisher.Writer()
+	if writer == nil {
+		return 0, nil
+	}
+
+	return r.Outbox.ProcessPending(ctx, r.BatchSize, func(batch []entity.OutboxEvent) error {
+		// Event yang gagal di-encode (mis. schema registry mati) dianggap gagal kirim
+		errs := make([]error, len(batch))
+		msgs := make([]kafka.Message, 0, len(batch))
+		sent := make([]int, 0, len(batch))
+		for i, e := range batch {
+			value, contentType, err := encodeOutboxEvent(ctx, e)
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			headers := append(appKafka.EventHeaders(e.EventType, contentType), kafka.Header{Key: "outbox-id", Value: []byte(strconv.FormatUint(e.ID, 10))})
+			msgs = append(msgs, kafka.Message{
+				Topic:   e.Topic,
+				Key:     []byte(e.Key),
+				Value:   value,
+				Headers: headers,
+			})
+			sent = append(sent, i)
+		}
+
+		if len(msgs) > 0 {
+			if err := writer.WriteMessages(ctx, msgs...); err != nil {
+				var writeErrs kafka.WriteErrors
+				for j, i := range sent {
+					if errors.As(err, &writeErrs) && len(writeErrs) == len(msgs) {
+						errs[i] = writeErrs[j]
+					} else {
+						errs[i] = err
+					}
+				}
+			}
+		}
+
+		now := time.Now()
+		failed := make(map[string]error)
+		for i := range batch {
+			e := &batch[i]
+			aggregate := e.AggregateType + ":" + e.AggregateID
+			err := errs[i]
+			if prev, ok := failed[aggregate]; ok && err == nil {
+				// Event lebih lama dari aggregate ini gagal; kirim ulang bersamanya supaya urutan tetap
+				err = fmt.Errorf("menunggu event sebelumnya: %w", prev)
+			}
+			if err == nil {
+				e.PublishedAt = &now
+				continue
+			}
+			failed[aggregate] = err
+			e.Attempts++
+			e.LastError = err.Error()
+			e.NextAttemptAt = now.Add(r.backoff(e.Attempts))
+		}
+		if len(failed) > 0 {
+			log.Printf("⚠️ %d aggregate gagal dikirim dari outbox, dicoba lagi nanti", len(failed))
+		} else {
+			fmt.Println("📤 Outbox terkirim:", len(batch), "event")
+		}
+		return nil
+	})
+}
+
+// backoff eksponensial: MinBackoff * 2^(attempts-1), maksimal MaxBackoff
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	d := r.MinBackoff
+	for i := 1; i < attempts && d < r.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.MaxBackoff)
+}
+
+func (r *OutboxRelay) defaults() {
+	if r.Interval <= 0 {
+		r.Interval = time.Second
+	}
+	if r.BatchSize <= 0 {
+		r.BatchSize = 100
+	}
+	if r.MinBackoff <= 0 {
+		r.MinBackoff = time.Second
+	}
+	if r.MaxBackoff <= 0 {
+		r.MaxBackoff = 5 * time.Minute
+	}
+	if r.Retention <= 0 {
+		r.Retention = 24 * time.Hour
+	}
+	if r.CleanupInterval <= 0 {
+		r.CleanupInterval = 10 * time.Minute
+	}
+}
+
+// encodeOutboxEvent mengubah payload outbox (selalu JSON) ke encoding topic tujuannya
+func encodeOutboxEvent(ctx context.Context, e entity.OutboxEvent) ([]byte, string, error) {
+	if appKafka.Encodings.Encoding(e.Topic) == appKafka.EncodingJSON {
+		return e.Payload, events.ContentType, nil
+	}
+	event, err := events.Decode(e.Payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("payload outbox %d tidak valid: %w", e.ID, err)
+	}
+	return appKafka.EncodeEvent(ctx, e.Topic, event)
+}
+
+// eventTopic mengembalikan topic Kafka untuk event (lihat events.Routes)
+func eventTopic(event events.Event) string {
+	return events.Topic(event.Name())
+}
+
+// newOutboxEvent membuat event outbox dengan key pesan = ID aggregate, sehingga
+// event aggregate yang sama masuk partisi yang sama di Kafka. Topic ditentukan
+// saat event ditulis, jadi perubahan routing hanya berlaku untuk event baru.
+func newOutboxEvent(aggregateType string, id uint, event events.Event) (entity.OutboxEvent, error) {
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return entity.OutboxEvent{}, fmt.Errorf("marshal outbox payload failed: %w", err)
+	}
+	aggregateID := strconv.FormatUint(uint64(id), 10)
+	return entity.OutboxEvent{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Topic:         eventTopic(event),
+		EventType:     event.Type,
+		Key:           aggregateID,
+		Payload:       raw,
+	}, nil
+}
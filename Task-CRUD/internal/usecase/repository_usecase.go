@@ -2,113 +2,129 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
-	"time"
+	"strings"
 
 	"Task-CRUD/internal/cbreaker"
 	"Task-CRUD/internal/entity"
+	"Task-CRUD/internal/events"
 	interfaces "Task-CRUD/internal/interfaces"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/log"
-	"github.com/redis/go-redis/v9"
-	"github.com/segmentio/kafka-go"
 	"github.com/sony/gobreaker"
 )
 
 type RepoUseCase struct {
-	repoRepo interfaces.RepoRepositoryInterfaceGorm
-	redis    *redis.Client
-	breaker  *gobreaker.CircuitBreaker
-	kafka    *kafka.Writer
+	repoRepo  interfaces.RepoRepositoryInterfaceGorm
+	caches    *Caches
+	breaker   *gobreaker.CircuitBreaker
+	publisher interfaces.EventPublisher // dipakai ReplayRepoEvents
+	pipeline  *EventPipeline            // jalur event CRUD
 }
 
-func NewRepoUseCaseFull(
+// NewRepoUseCaseWithEvents mengirim event CRUD langsung lewat publisher setelah
+// commit (dipakai CLI dan test). publisher nil berarti event dimatikan.
+func NewRepoUseCaseWithEvents(
 	repoRepo interfaces.RepoRepositoryInterfaceGorm,
-	redisClient *redis.Client,
-	kafkaWriter *kafka.Writer,
+	caches *Caches,
+	publisher interfaces.EventPublisher,
 ) interfaces.RepoUseCaseInterface {
+	if caches == nil {
+		caches = NoCaches()
+	}
 	return &RepoUseCase{
-		repoRepo: repoRepo,
-		redis:    redisClient,
-		breaker:  cbreaker.Breaker,
-		kafka:    kafkaWriter,
+		repoRepo:  repoRepo,
+		caches:    caches,
+		breaker:   cbreaker.Breaker,
+		publisher: publisher,
+		pipeline:  NewEventPipeline(publisher),
 	}
 }
 
+// NewRepoUseCaseWithOutbox dipakai server: event CRUD lewat pipeline (outbox)
+// sehingga tidak hilang saat Kafka mati. publisher tetap dipakai untuk ReplayRepoEvents.
+func NewRepoUseCaseWithOutbox(
+	repoRepo interfaces.RepoRepositoryInterfaceGorm,
+	caches *Caches,
+	publisher interfaces.EventPublisher,
+	pipeline *EventPipeline,
+) interfaces.RepoUseCaseInterface {
+	uc := NewRepoUseCaseWithEvents(repoRepo, caches, publisher).(*RepoUseCase)
+	uc.pipeline = pipeline
+	return uc
+}
+
 // --- GET ALL
 func (uc *RepoUseCase) GetAllRepos(ctx context.Context) ([]entity.Repository, error) {
+	repos, _, err := uc.GetAllReposWithETag(ctx)
+	return repos, err
+}
+
+func (uc *RepoUseCase) GetAllReposWithETag(ctx context.Context) ([]entity.Repository, string, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "RepoUseCase.GetAllRepos")
 	defer span.Finish()
 
-	cacheKey := "repositories:all"
-
-	if uc.redis != nil {
-		if cached, err := uc.redis.Get(ctx, cacheKey).Result(); err == nil {
-			var repos []entity.Repository
-			if err := json.Unmarshal([]byte(cached), &repos); err == nil {
-				span.LogFields(log.String("cache", "hit"))
-				fmt.Println("✅ Data repositories diambil dari Redis")
-				return repos, nil
-			}
+	item, _, err := uc.caches.Repositories.FetchItem(ctx, cacheKeyAll, func(ctx context.Context) ([]entity.Repository, bool, error) {
+		result, err := uc.breaker.Execute(func() (interface{}, error) {
+			return uc.repoRepo.GetAllRepositories(ctx)
+		})
+		if err != nil {
+			return nil, false, err
 		}
-	}
-
-	result, err := uc.breaker.Execute(func() (interface{}, error) {
-		return uc.repoRepo.GetAllRepositories(ctx)
+		return result.([]entity.Repository), true, nil
 	})
 	if err != nil {
 		span.LogFields(log.Error(err))
-		return nil, fmt.Errorf("get all repositories failed: %w", err)
-	}
-
-	repos := result.([]entity.Repository)
-
-	if uc.redis != nil {
-		bytes, _ := json.Marshal(repos)
-		_ = uc.redis.Set(ctx, cacheKey, bytes, 10*time.Minute).Err()
+		return nil, "", fmt.Errorf("get all repositories failed: %w", err)
 	}
 
-	return repos, nil
+	return item.Value, item.ETag, nil
 }
 
 // --- GET BY ID
 func (uc *RepoUseCase) GetRepositoryByID(ctx context.Context, id uint) (*entity.Repository, error) {
+	repo, _, err := uc.GetRepositoryByIDWithETag(ctx, id)
+	return repo, err
+}
+
+func (uc *RepoUseCase) GetRepositoryByIDWithETag(ctx context.Context, id uint) (*entity.Repository, string, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "RepoUseCase.GetRepositoryByID")
 	defer span.Finish()
 
-	cacheKey := fmt.Sprintf("repository:%d", id)
-
-	if uc.redis != nil {
-		if cached, err := uc.redis.Get(ctx, cacheKey).Result(); err == nil {
-			var repo entity.Repository
-			if err := json.Unmarshal([]byte(cached), &repo); err == nil {
-				span.LogFields(log.String("cache", "hit"))
-				fmt.Println("✅ Repository ditemukan di Redis")
-				return &repo, nil
+	item, found, err := uc.caches.Repository.FetchItem(ctx, idKey(id), func(ctx context.Context) (entity.Repository, bool, error) {
+		result, err := uc.breaker.Execute(func() (interface{}, error) {
+			repo, err := uc.repoRepo.GetRepositoryByID(ctx, id)
+			if errors.Is(err, entity.ErrNotFound) {
+				// Data tidak ada bukan kegagalan database, jangan dihitung oleh breaker
+				return (*entity.Repository)(nil), nil
 			}
+			return repo, err
+		})
+		if err != nil {
+			return entity.Repository{}, false, err
 		}
-	}
-
-	result, err := uc.breaker.Execute(func() (interface{}, error) {
-		return uc.repoRepo.GetRepositoryByID(ctx, id)
+		repo := result.(*entity.Repository)
+		if repo == nil {
+			return entity.Repository{}, false, nil
+		}
+		// Catat dependency sebelum disimpan supaya update user berikutnya ikut menghapus entry ini
+		if err := uc.caches.TrackRepository(ctx, repo); err != nil {
+			span.LogFields(log.Error(err))
+		}
+		return *repo, true, nil
 	})
 	if err != nil {
 		span.LogFields(log.Error(err))
-		return nil, fmt.Errorf("get repository by ID failed: %w", err)
+		return nil, "", fmt.Errorf("get repository by ID failed: %w", err)
 	}
-
-	repo := result.(*entity.Repository)
-
-	if uc.redis != nil {
-		bytes, _ := json.Marshal(repo)
-		_ = uc.redis.Set(ctx, cacheKey, bytes, 10*time.Minute).Err()
+	if !found {
+		return nil, "", entity.ErrNotFound
 	}
 
-	return repo, nil
+	return &item.Value, item.ETag, nil
 }
 
 // --- CREATE
@@ -121,21 +137,32 @@ func (uc *RepoUseCase) CreateRepo(ctx context.Context, repo *entity.Repository)
 		return err
 	}
 
-	_, err := uc.breaker.Execute(func() (interface{}, error) {
-		return nil, uc.repoRepo.CreateRepository(ctx, repo)
+	var event events.Event
+	err := uc.pipeline.Atomically(ctx, func(ctx context.Context) error {
+		_, err := uc.breaker.Execute(func() (interface{}, error) {
+			return nil, uc.repoRepo.CreateRepository(ctx, repo)
+		})
+		if err != nil || !uc.pipeline.Enabled() {
+			return err
+		}
+		after, err := uc.loadForEvent(ctx, repo.ID)
+		if err != nil {
+			return err
+		}
+		if event, err = events.New(ctx, events.RepositoryCreated, repoSubject(repo.ID), nil, after); err != nil {
+			return err
+		}
+		return uc.pipeline.Stage(ctx, "repository", repo.ID, event)
 	})
 	if err != nil {
 		span.LogFields(log.Error(err))
 		return fmt.Errorf("create repository failed: %w", err)
 	}
 
-	if uc.redis != nil {
-		pipeline := uc.redis.TxPipeline()
-		pipeline.Del(ctx, "repositories:all")
-		_, _ = pipeline.Exec(ctx)
-	}
+	// Sertakan ID baru supaya entry negatif untuk ID ini ikut terhapus
+	uc.invalidate(ctx, span, repo.ID)
 
-	return uc.sendKafkaMessage(ctx, "repository_created", repo)
+	return uc.pipeline.Publish(ctx, event)
 }
 
 // --- UPDATE
@@ -148,22 +175,38 @@ func (uc *RepoUseCase) UpdateRepo(ctx context.Context, id uint, repo *entity.Rep
 		return err
 	}
 
-	_, err := uc.breaker.Execute(func() (interface{}, error) {
-		return nil, uc.repoRepo.UpdateRepository(ctx, id, repo)
+	var event events.Event
+	err := uc.pipeline.Atomically(ctx, func(ctx context.Context) error {
+		var before *entity.Repository
+		if uc.pipeline.Enabled() {
+			var err error
+			if before, err = uc.loadForEvent(ctx, id); err != nil {
+				return err
+			}
+		}
+		_, err := uc.breaker.Execute(func() (interface{}, error) {
+			return nil, uc.repoRepo.UpdateRepository(ctx, id, repo)
+		})
+		if err != nil || !uc.pipeline.Enabled() {
+			return err
+		}
+		after, err := uc.loadForEvent(ctx, id)
+		if err != nil {
+			return err
+		}
+		if event, err = events.New(ctx, events.RepositoryUpdated, repoSubject(id), before, after); err != nil {
+			return err
+		}
+		return uc.pipeline.Stage(ctx, "repository", id, event)
 	})
 	if err != nil {
 		span.LogFields(log.Error(err))
 		return fmt.Errorf("update repository failed: %w", err)
 	}
 
-	if uc.redis != nil {
-		pipeline := uc.redis.TxPipeline()
-		pipeline.Del(ctx, "repositories:all")
-		pipeline.Del(ctx, fmt.Sprintf("repository:%d", id))
-		_, _ = pipeline.Exec(ctx)
-	}
+	uc.invalidate(ctx, span, id)
 
-	return uc.sendKafkaMessage(ctx, "repository_updated", repo)
+	return uc.pipeline.Publish(ctx, event)
 }
 
 // --- DELETE
@@ -171,44 +214,121 @@ func (uc *RepoUseCase) DeleteRepo(ctx context.Context, id uint) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "RepoUseCase.DeleteRepo")
 	defer span.Finish()
 
-	_, err := uc.breaker.Execute(func() (interface{}, error) {
-		return nil, uc.repoRepo.DeleteRepository(ctx, id)
+	var event events.Event
+	err := uc.pipeline.Atomically(ctx, func(ctx context.Context) error {
+		var before *entity.Repository
+		if uc.pipeline.Enabled() {
+			var err error
+			if before, err = uc.loadForEvent(ctx, id); err != nil {
+				return err
+			}
+		}
+		_, err := uc.breaker.Execute(func() (interface{}, error) {
+			return nil, uc.repoRepo.DeleteRepository(ctx, id)
+		})
+		if err != nil || !uc.pipeline.Enabled() {
+			return err
+		}
+		if event, err = events.New(ctx, events.RepositoryDeleted, repoSubject(id), before, nil); err != nil {
+			return err
+		}
+		return uc.pipeline.Stage(ctx, "repository", id, event)
 	})
 	if err != nil {
 		span.LogFields(log.Error(err))
 		return fmt.Errorf("delete repository failed: %w", err)
 	}
 
-	if uc.redis != nil {
-		pipeline := uc.redis.TxPipeline()
-		pipeline.Del(ctx, "repositories:all")
-		pipeline.Del(ctx, fmt.Sprintf("repository:%d", id))
-		_, _ = pipeline.Exec(ctx)
+	uc.invalidate(ctx, span, id)
+
+	return uc.pipeline.Publish(ctx, event)
+}
+
+// --- REPLAY EVENT
+// ReplayRepoEvents mengirim ulang kondisi repository saat ini ke topic Kafka.
+// topic memakai nama per event ("repository_created"); topic tujuan sebenarnya
+// mengikuti events.Routes. Tanpa ids, semua repository dikirim ulang.
+func (uc *RepoUseCase) ReplayRepoEvents(ctx context.Context, topic string, ids ...uint) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RepoUseCase.ReplayRepoEvents")
+	defer span.Finish()
+
+	if topic != "repository_created" && topic != "repository_updated" {
+		return 0, fmt.Errorf("topic replay tidak didukung: %s", topic)
+	}
+	if uc.publisher == nil {
+		return 0, events.ErrPublisherUnavailable
+	}
+
+	var repos []entity.Repository
+	if len(ids) == 0 {
+		result, err := uc.breaker.Execute(func() (interface{}, error) {
+			return uc.repoRepo.GetAllRepositories(ctx)
+		})
+		if err != nil {
+			span.LogFields(log.Error(err))
+			return 0, fmt.Errorf("get all repositories failed: %w", err)
+		}
+		repos = result.([]entity.Repository)
+	} else {
+		for _, id := range ids {
+			result, err := uc.breaker.Execute(func() (interface{}, error) {
+				return uc.repoRepo.GetRepositoryByID(ctx, id)
+			})
+			if err != nil {
+				span.LogFields(log.Error(err))
+				return 0, fmt.Errorf("get repository by ID failed: %w", err)
+			}
+			repos = append(repos, *result.(*entity.Repository))
+		}
 	}
 
-	return uc.sendKafkaMessage(ctx, "repository_deleted", map[string]uint{"id": id})
+	// Replay mengirim kondisi saat ini sebagai "after" tanpa "before"
+	name := strings.ReplaceAll(topic, "_", ".")
+	sent := 0
+	for i := range repos {
+		event, err := events.New(ctx, name, repoSubject(repos[i].ID), nil, &repos[i])
+		if err != nil {
+			return sent, err
+		}
+		if err := uc.publisher.Publish(ctx, event); err != nil {
+			span.LogFields(log.Error(err))
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
 }
 
-// --- KIRIM PESAN KAFKA
-func (uc *RepoUseCase) sendKafkaMessage(ctx context.Context, topic string, payload interface{}) error {
-	if uc.kafka == nil {
-		return nil
+// invalidate menghapus cache list dan cache repository dengan ID yang diberikan
+func (uc *RepoUseCase) invalidate(ctx context.Context, span opentracing.Span, ids ...uint) {
+	if err := uc.caches.InvalidateRepositories(ctx, ids...); err != nil {
+		span.LogFields(log.Error(err))
 	}
-	bytes, err := json.Marshal(payload)
+}
+
+// loadForEvent membaca kondisi repository (beserta user) untuk before/after event
+func (uc *RepoUseCase) loadForEvent(ctx context.Context, id uint) (*entity.Repository, error) {
+	result, err := uc.breaker.Execute(func() (interface{}, error) {
+		repo, err := uc.repoRepo.GetRepositoryByID(ctx, id)
+		if errors.Is(err, entity.ErrNotFound) {
+			// Data tidak ada bukan kegagalan database, jangan dihitung oleh breaker
+			return (*entity.Repository)(nil), nil
+		}
+		return repo, err
+	})
 	if err != nil {
-		return fmt.Errorf("marshal kafka payload failed: %w", err)
-	}
-	msg := kafka.Message{
-		Topic: topic,
-		Key:   []byte(topic),
-		Value: bytes,
+		return nil, err
 	}
-	if err := uc.kafka.WriteMessages(ctx, msg); err != nil {
-		fmt.Println("❌ Kafka send failed:", err)
-		return err
+	repo := result.(*entity.Repository)
+	if repo == nil {
+		return nil, entity.ErrNotFound
 	}
-	fmt.Println("📤 Kafka event terkirim:", topic)
-	return nil
+	return repo, nil
+}
+
+// repoSubject mengisi atribut "subject" CloudEvents, mis. "repository/12"
+func repoSubject(id uint) string {
+	return fmt.Sprintf("repository/%d", id)
 }
 
 // --- VALIDASI
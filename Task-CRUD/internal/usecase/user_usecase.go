@@ -2,99 +2,112 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
 	"strings"
-	"time"
 
 	"Task-CRUD/internal/cbreaker"
 	"Task-CRUD/internal/entity"
+	"Task-CRUD/internal/events"
 	interfaces "Task-CRUD/internal/interfaces"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/log"
-	"github.com/redis/go-redis/v9"
 	"github.com/sony/gobreaker"
 )
 
 type UserUseCase struct {
 	userRepo interfaces.UserRepositoryInterfaceGorm
-	redis    *redis.Client
+	caches   *Caches
 	breaker  *gobreaker.CircuitBreaker
+	pipeline *EventPipeline // nil: event user tidak dikirim
 }
 
 func NewUserUseCase(userRepo interfaces.UserRepositoryInterfaceGorm) interfaces.UserUseCaseInterface {
-	return &UserUseCase{
-		userRepo: userRepo,
-		breaker:  cbreaker.Breaker,
-	}
+	return NewUserUseCaseWithEvents(userRepo, nil, nil)
 }
 
-func NewUserUseCaseWithCache(userRepo interfaces.UserRepositoryInterfaceGorm, redisClient *redis.Client) interfaces.UserUseCaseInterface {
+func NewUserUseCaseWithCache(userRepo interfaces.UserRepositoryInterfaceGorm, caches *Caches) interfaces.UserUseCaseInterface {
+	return NewUserUseCaseWithEvents(userRepo, caches, nil)
+}
+
+// NewUserUseCaseWithEvents mengirim user_created/user_updated/user_deleted lewat
+// pipeline yang sama dengan event repository; caches dan pipeline boleh nil
+func NewUserUseCaseWithEvents(userRepo interfaces.UserRepositoryInterfaceGorm, caches *Caches, pipeline *EventPipeline) interfaces.UserUseCaseInterface {
+	if caches == nil {
+		caches = NoCaches()
+	}
 	return &UserUseCase{
 		userRepo: userRepo,
-		redis:    redisClient,
+		caches:   caches,
 		breaker:  cbreaker.Breaker,
+		pipeline: pipeline,
 	}
 }
 
 func (uc *UserUseCase) GetUsers(ctx context.Context) ([]entity.User, error) {
+	users, _, err := uc.GetUsersWithETag(ctx)
+	return users, err
+}
+
+func (uc *UserUseCase) GetUsersWithETag(ctx context.Context) ([]entity.User, string, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.GetUsers")
 	defer span.Finish()
 
-	cacheKey := "users:all"
-
-	if uc.redis != nil {
-		cached, err := uc.redis.Get(ctx, cacheKey).Result()
-		if err == nil {
-			var users []entity.User
-			if err := json.Unmarshal([]byte(cached), &users); err == nil {
-				span.LogFields(log.String("cache", "hit"))
-				fmt.Println("✅ Data users diambil dari Redis")
-				return users, nil
-			}
-			span.LogFields(log.Error(err))
-			fmt.Printf("⚠️ Gagal unmarshal data users dari Redis: %v\n", err)
-		} else if err != redis.Nil {
-			span.LogFields(log.Error(err))
-			fmt.Printf("⚠️ Redis error: %v\n", err)
+	item, _, err := uc.caches.Users.FetchItem(ctx, cacheKeyAll, func(ctx context.Context) ([]entity.User, bool, error) {
+		result, err := uc.breaker.Execute(func() (interface{}, error) {
+			return uc.userRepo.GetAllUsers(ctx)
+		})
+		if err != nil {
+			return nil, false, err
 		}
-	}
-
-	result, err := uc.breaker.Execute(func() (interface{}, error) {
-		return uc.userRepo.GetAllUsers(ctx)
+		return result.([]entity.User), true, nil
 	})
 	if err != nil {
 		span.LogFields(log.Error(err))
-		return nil, err
+		return nil, "", err
 	}
-	users := result.([]entity.User)
 
-	if uc.redis != nil {
-		data, _ := json.Marshal(users)
-		if err := uc.redis.Set(ctx, cacheKey, data, 10*time.Minute).Err(); err != nil {
-			span.LogFields(log.Error(err))
-			fmt.Printf("⚠️ Gagal set Redis users: %v\n", err)
-		}
-	}
-
-	return users, nil
+	return item.Value, item.ETag, nil
 }
 
 func (uc *UserUseCase) GetUserByID(ctx context.Context, id uint) (*entity.User, error) {
+	user, _, err := uc.GetUserByIDWithETag(ctx, id)
+	return user, err
+}
+
+func (uc *UserUseCase) GetUserByIDWithETag(ctx context.Context, id uint) (*entity.User, string, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.GetUserByID")
 	defer span.Finish()
 
-	result, err := uc.breaker.Execute(func() (interface{}, error) {
-		return uc.userRepo.GetUserByID(ctx, id)
+	item, found, err := uc.caches.User.FetchItem(ctx, idKey(id), func(ctx context.Context) (entity.User, bool, error) {
+		result, err := uc.breaker.Execute(func() (interface{}, error) {
+			user, err := uc.userRepo.GetUserByID(ctx, id)
+			if errors.Is(err, entity.ErrNotFound) {
+				// Data tidak ada bukan kegagalan database, jangan dihitung oleh breaker
+				return (*entity.User)(nil), nil
+			}
+			return user, err
+		})
+		if err != nil {
+			return entity.User{}, false, err
+		}
+		user := result.(*entity.User)
+		if user == nil {
+			return entity.User{}, false, nil
+		}
+		return *user, true, nil
 	})
 	if err != nil {
 		span.LogFields(log.Error(err))
-		return nil, err
+		return nil, "", err
 	}
-	return result.(*entity.User), nil
+	if !found {
+		return nil, "", entity.ErrNotFound
+	}
+
+	return &item.Value, item.ETag, nil
 }
 
 func (uc *UserUseCase) CreateUser(ctx context.Context, user *entity.User) error {
@@ -106,22 +119,34 @@ func (uc *UserUseCase) CreateUser(ctx context.Context, user *entity.User) error
 		return err
 	}
 
-	_, err := uc.breaker.Execute(func() (interface{}, error) {
-		return nil, uc.userRepo.CreateUser(ctx, user)
+	var event events.Event
+	err := uc.pipeline.Atomically(ctx, func(ctx context.Context) error {
+		_, err := uc.breaker.Execute(func() (interface{}, error) {
+			return nil, uc.userRepo.CreateUser(ctx, user)
+		})
+		if err != nil || !uc.pipeline.Enabled() {
+			return err
+		}
+		after, err := uc.loadForEvent(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if event, err = events.New(ctx, events.UserCreated, userSubject(user.ID), nil, after); err != nil {
+			return err
+		}
+		return uc.pipeline.Stage(ctx, "user", user.ID, event)
 	})
 	if err != nil {
 		span.LogFields(log.Error(err))
 		return err
 	}
 
-	if uc.redis != nil {
-		if err := uc.redis.Del(ctx, "users:all").Err(); err != nil {
-			span.LogFields(log.Error(err))
-			fmt.Printf("⚠️ Gagal hapus cache users setelah Create: %v\n", err)
-		}
+	// User baru: cukup hapus list dan entry negatif untuk ID ini
+	if err := uc.caches.InvalidateNewUser(ctx, user.ID); err != nil {
+		span.LogFields(log.Error(err))
 	}
 
-	return nil
+	return uc.pipeline.Publish(ctx, event)
 }
 
 func (uc *UserUseCase) UpdateUser(ctx context.Context, id uint, user *entity.User) error {
@@ -133,44 +158,105 @@ func (uc *UserUseCase) UpdateUser(ctx context.Context, id uint, user *entity.Use
 		return err
 	}
 
-	_, err := uc.breaker.Execute(func() (interface{}, error) {
-		return nil, uc.userRepo.UpdateUser(ctx, id, user)
+	var event events.Event
+	err := uc.pipeline.Atomically(ctx, func(ctx context.Context) error {
+		var before *entity.User
+		if uc.pipeline.Enabled() {
+			var err error
+			if before, err = uc.loadForEvent(ctx, id); err != nil {
+				return err
+			}
+		}
+		_, err := uc.breaker.Execute(func() (interface{}, error) {
+			return nil, uc.userRepo.UpdateUser(ctx, id, user)
+		})
+		if err != nil || !uc.pipeline.Enabled() {
+			return err
+		}
+		after, err := uc.loadForEvent(ctx, id)
+		if err != nil {
+			return err
+		}
+		if event, err = events.New(ctx, events.UserUpdated, userSubject(id), before, after); err != nil {
+			return err
+		}
+		return uc.pipeline.Stage(ctx, "user", id, event)
 	})
 	if err != nil {
 		span.LogFields(log.Error(err))
 		return err
 	}
 
-	if uc.redis != nil {
-		if err := uc.redis.Del(ctx, "users:all").Err(); err != nil {
-			span.LogFields(log.Error(err))
-			fmt.Printf("⚠️ Gagal hapus cache users setelah Update: %v\n", err)
-		}
-	}
+	uc.invalidate(ctx, span, id)
 
-	return nil
+	return uc.pipeline.Publish(ctx, event)
 }
 
 func (uc *UserUseCase) DeleteUser(ctx context.Context, id uint) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.DeleteUser")
 	defer span.Finish()
 
-	_, err := uc.breaker.Execute(func() (interface{}, error) {
-		return nil, uc.userRepo.DeleteUser(ctx, id)
+	var event events.Event
+	err := uc.pipeline.Atomically(ctx, func(ctx context.Context) error {
+		var before *entity.User
+		if uc.pipeline.Enabled() {
+			var err error
+			if before, err = uc.loadForEvent(ctx, id); err != nil {
+				return err
+			}
+		}
+		_, err := uc.breaker.Execute(func() (interface{}, error) {
+			return nil, uc.userRepo.DeleteUser(ctx, id)
+		})
+		if err != nil || !uc.pipeline.Enabled() {
+			return err
+		}
+		if event, err = events.New(ctx, events.UserDeleted, userSubject(id), before, nil); err != nil {
+			return err
+		}
+		return uc.pipeline.Stage(ctx, "user", id, event)
 	})
 	if err != nil {
 		span.LogFields(log.Error(err))
 		return err
 	}
 
-	if uc.redis != nil {
-		if err := uc.redis.Del(ctx, "users:all").Err(); err != nil {
-			span.LogFields(log.Error(err))
-			fmt.Printf("⚠️ Gagal hapus cache users setelah Delete: %v\n", err)
+	uc.invalidate(ctx, span, id)
+
+	return uc.pipeline.Publish(ctx, event)
+}
+
+// loadForEvent membaca kondisi user untuk before/after event
+func (uc *UserUseCase) loadForEvent(ctx context.Context, id uint) (*entity.User, error) {
+	result, err := uc.breaker.Execute(func() (interface{}, error) {
+		user, err := uc.userRepo.GetUserByID(ctx, id)
+		if errors.Is(err, entity.ErrNotFound) {
+			// Data tidak ada bukan kegagalan database, jangan dihitung oleh breaker
+			return (*entity.User)(nil), nil
 		}
+		return user, err
+	})
+	if err != nil {
+		return nil, err
 	}
+	user := result.(*entity.User)
+	if user == nil {
+		return nil, entity.ErrNotFound
+	}
+	return user, nil
+}
 
-	return nil
+// userSubject mengisi atribut "subject" CloudEvents, mis. "user/3"
+func userSubject(id uint) string {
+	return fmt.Sprintf("user/%d", id)
+}
+
+// invalidate menghapus cache list user, cache user dengan ID yang diberikan,
+// dan cache repository yang menyimpan salinan user tersebut
+func (uc *UserUseCase) invalidate(ctx context.Context, span opentracing.Span, ids ...uint) {
+	if err := uc.caches.InvalidateUser(ctx, ids...); err != nil {
+		span.LogFields(log.Error(err))
+	}
 }
 
 // ✅ Validasi data user sebelum masuk ke repo
@@ -0,0 +1,328 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"Task-CRUD/internal/entity"
+	interfaces "Task-CRUD/internal/interfaces"
+)
+
+// webhookBodyLimit membatasi body request/response yang disimpan di log delivery
+const webhookBodyLimit = 4 << 10
+
+// Header yang dikirim bersama setiap delivery webhook
+const (
+	WebhookIDHeader        = "X-Webhook-ID"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookDispatcher mengirim delivery webhook yang jatuh tempo. Aman dijalankan
+// di beberapa replica (lihat WebhookRepositoryInterface.ClaimDue). Delivery
+// yang gagal dicoba lagi dengan backoff eksponensial sampai MaxAttempts; webhook
+// yang gagal DisableAfter kali berturut-turut dinonaktifkan otomatis.
+type WebhookDispatcher struct {
+	Webhooks interfaces.WebhookRepositoryInterface
+	Client   *http.Client // default: Timeout, tanpa redirect, proxy, dan alamat privat
+
+	// AllowPrivateTargets mengizinkan URL webhook ke alamat loopback/privat
+	// (hanya untuk pengembangan lokal); berlaku untuk Client default
+	AllowPrivateTargets bool
+
+	Interval        time.Duration // jeda polling saat antrean kosong (default 1s)
+	BatchSize       int           // default 20
+	Timeout         time.Duration // timeout per request (default 10s)
+	Lease           time.Duration // lama delivery yang diklaim tidak diambil replica lain (default 3 × Timeout)
+	MaxAttempts     int           // default 8
+	MinBackoff      time.Duration // default 10s
+	MaxBackoff      time.Duration // default 1h
+	DisableAfter    int           // kegagalan berturut-turut sebelum webhook dinonaktifkan (default 20)
+	Retention       time.Duration // umur log delivery selesai sebelum dihapus (default 7 hari)
+	CleanupInterval time.Duration // default 1h
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Start menjalankan dispatcher di background sampai Stop
+func (d *WebhookDispatcher) Start() {
+	d.defaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+	go d.run(ctx)
+}
+
+// Stop menghentikan dispatcher; request yang sedang berjalan dibatalkan dan
+// delivery-nya dicoba lagi setelah lease habis
+func (d *WebhookDispatcher) Stop() {
+	if d.cancel == nil {
+		return
+	}
+	d.cancel()
+	<-d.done
+}
+
+func (d *WebhookDispatcher) run(ctx context.Context) {
+	defer close(d.done)
+
+	lastCleanup := time.Now()
+	for {
+		claimed, err := d.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Webhook dispatcher gagal: %v", err)
+		}
+
+		if time.Since(lastCleanup) >= d.CleanupInterval {
+			lastCleanup = time.Now()
+			if n, err := d.Webhooks.DeleteDeliveriesBefore(ctx, time.Now().Add(-d.Retention)); err != nil {
+				log.Printf("⚠️ Gagal membersihkan log webhook: %v", err)
+			} else if n > 0 {
+				log.Printf("🧹 %d log delivery webhook dibersihkan", n)
+			}
+		}
+
+		wait := d.Interval
+		if err == nil && claimed >= d.BatchSize {
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// RunOnce mengirim satu batch delivery dan mengembalikan jumlah delivery yang diklaim.
+// Request dalam satu batch dikirim bersamaan di luar transaksi database; jika ctx
+// dibatalkan di tengah jalan, hasilnya tidak disimpan dan delivery dicoba lagi
+// setelah lease habis.
+func (d *WebhookDispatcher) RunOnce(ctx context.Context) (int, error) {
+	d.defaults()
+	deliveries, webhooks, err := d.Webhooks.ClaimDue(ctx, d.BatchSize, d.Lease)
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		w := webhooks[deliveries[i].WebhookID]
+		if w == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(delivery *entity.WebhookDelivery) {
+			defer wg.Done()
+			d.send(ctx, w, delivery)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return len(deliveries), err
+	}
+
+	now := time.Now()
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		if delivery.Status == entity.DeliverySucceeded {
+			continue
+		}
+		if delivery.Attempts >= d.MaxAttempts {
+			delivery.Status = entity.DeliveryFailed
+		} else {
+			delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+		}
+	}
+
+	err = d.Webhooks.CompleteDeliveries(ctx, deliveries, func(completed []entity.WebhookDelivery, webhooks map[uint]*entity.Webhook) error {
+		// Penghitung diterapkan berurutan karena beberapa delivery bisa milik webhook yang sama
+		succeeded, failed := 0, 0
+		for i := range completed {
+			delivery := &completed[i]
+			w := webhooks[delivery.WebhookID]
+			if w == nil {
+				continue
+			}
+			if delivery.Status == entity.DeliverySucceeded {
+				succeeded++
+				w.Failures = 0
+				continue
+			}
+			failed++
+			w.Failures++
+			if w.Active && w.Failures >= d.DisableAfter {
+				w.Active = false
+				w.DisabledAt = &now
+				w.DisabledWhy = fmt.Sprintf("%d pengiriman gagal berturut-turut: %s", w.Failures, delivery.Error)
+				log.Printf("🚫 Webhook %d dinonaktifkan setelah %d kegagalan berturut-turut", w.ID, w.Failures)
+			}
+		}
+		if failed > 0 {
+			log.Printf("⚠️ Webhook: %d delivery terkirim, %d gagal", succeeded, failed)
+		} else {
+			log.Printf("📨 Webhook terkirim: %d delivery", succeeded)
+		}
+		return nil
+	})
+	return len(deliveries), err
+}
+
+// send mengirim satu delivery dan mencatat request/response ke delivery
+func (d *WebhookDispatcher) send(ctx context.Context, w *entity.Webhook, delivery *entity.WebhookDelivery) {
+	delivery.Attempts++
+	delivery.Error = ""
+	delivery.ResponseStatus = 0
+	delivery.ResponseHeaders = ""
+	delivery.ResponseBody = ""
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		delivery.Error = err.Error()
+		return
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json")
+	req.Header.Set("User-Agent", "Task-CRUD-Webhook/1.0")
+	req.Header.Set(WebhookIDHeader, strconv.FormatUint(uint64(w.ID), 10))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(w.Secret, timestamp, delivery.Payload))
+
+	delivery.RequestURL = w.URL
+	delivery.RequestHeaders = headersJSON(req.Header)
+	delivery.RequestBody = truncateBody(delivery.Payload)
+
+	start := time.Now()
+	resp, err := d.Client.Do(req)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookBodyLimit+1))
+	delivery.ResponseStatus = resp.StatusCode
+	delivery.ResponseHeaders = headersJSON(resp.Header)
+	delivery.ResponseBody = truncateBody(body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		delivery.Error = "endpoint membalas " + resp.Status
+		return
+	}
+	now := time.Now()
+	delivery.Status = entity.DeliverySucceeded
+	delivery.DeliveredAt = &now
+}
+
+// backoff eksponensial: MinBackoff * 2^(attempts-1), maksimal MaxBackoff
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	b := d.MinBackoff
+	for i := 1; i < attempts && b < d.MaxBackoff; i++ {
+		b *= 2
+	}
+	return min(b, d.MaxBackoff)
+}
+
+func (d *WebhookDispatcher) defaults() {
+	if d.Interval <= 0 {
+		d.Interval = time.Second
+	}
+	if d.BatchSize <= 0 {
+		d.BatchSize = 20
+	}
+	if d.Timeout <= 0 {
+		d.Timeout = 10 * time.Second
+	}
+	if d.Lease <= 0 {
+		d.Lease = 3 * d.Timeout
+	}
+	if d.MaxAttempts <= 0 {
+		d.MaxAttempts = 8
+	}
+	if d.MinBackoff <= 0 {
+		d.MinBackoff = 10 * time.Second
+	}
+	if d.MaxBackoff <= 0 {
+		d.MaxBackoff = time.Hour
+	}
+	if d.DisableAfter <= 0 {
+		d.DisableAfter = 20
+	}
+	if d.Retention <= 0 {
+		d.Retention = 7 * 24 * time.Hour
+	}
+	if d.CleanupInterval <= 0 {
+		d.CleanupInterval = time.Hour
+	}
+	if d.Client == nil {
+		dialer := &net.Dialer{Timeout: d.Timeout, KeepAlive: 30 * time.Second}
+		if !d.AllowPrivateTargets {
+			dialer.Control = blockPrivateTargets
+		}
+		d.Client = &http.Client{
+			Timeout: d.Timeout,
+			// Tanpa proxy: alamat yang dicek dialer harus alamat endpoint sebenarnya
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: d.Timeout,
+				MaxIdleConnsPerHost: 4,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			// Redirect tidak diikuti; 3xx dianggap gagal supaya payload tidak bocor ke host lain
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+}
+
+// ErrWebhookTargetBlocked dicatat di delivery yang URL-nya mengarah ke alamat internal
+var ErrWebhookTargetBlocked = errors.New("alamat tujuan webhook tidak diizinkan")
+
+// blockPrivateTargets menolak koneksi ke alamat loopback, privat, link-local,
+// multicast, dan unspecified. Pemeriksaan dilakukan pada IP hasil resolve DNS
+// tepat sebelum connect, sehingga tidak bisa dilewati dengan DNS rebinding.
+func blockPrivateTargets(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w: %s", ErrWebhookTargetBlocked, host)
+	}
+	return nil
+}
+
+func headersJSON(h http.Header) string {
+	raw, err := json.Marshal(h)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+func truncateBody(body []byte) string {
+	if len(body) > webhookBodyLimit {
+		return string(body[:webhookBodyLimit]) + "…(dipotong)"
+	}
+	return string(body)
+}
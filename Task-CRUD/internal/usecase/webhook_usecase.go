@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"Task-CRUD/internal/entity"
+	"Task-CRUD/internal/events"
+	interfaces "Task-CRUD/internal/interfaces"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// ErrInvalidWebhook dikembalikan untuk URL atau tipe event webhook yang tidak valid
+var ErrInvalidWebhook = errors.New("webhook tidak valid")
+
+// WebhookAllEvents berlangganan semua tipe event
+const WebhookAllEvents = "*"
+
+type WebhookUseCase struct {
+	repo interfaces.WebhookRepositoryInterface
+}
+
+func NewWebhookUseCase(repo interfaces.WebhookRepositoryInterface) interfaces.WebhookUseCaseInterface {
+	return &WebhookUseCase{repo: repo}
+}
+
+// CreateWebhook menyimpan webhook aktif. Secret dibuat acak jika kosong.
+func (uc *WebhookUseCase) CreateWebhook(ctx context.Context, webhook *entity.Webhook) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookUseCase.CreateWebhook")
+	defer span.Finish()
+
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = newWebhookSecret()
+	}
+	webhook.Active = true
+	webhook.Failures = 0
+	webhook.DisabledAt = nil
+	webhook.DisabledWhy = ""
+
+	if err := uc.repo.Create(ctx, webhook); err != nil {
+		span.LogFields(log.Error(err))
+		return fmt.Errorf("create webhook failed: %w", err)
+	}
+	return nil
+}
+
+func (uc *WebhookUseCase) GetWebhook(ctx context.Context, id uint) (*entity.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookUseCase.GetWebhook")
+	defer span.Finish()
+
+	return uc.repo.GetByID(ctx, id)
+}
+
+func (uc *WebhookUseCase) ListWebhooks(ctx context.Context) ([]entity.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookUseCase.ListWebhooks")
+	defer span.Finish()
+
+	return uc.repo.List(ctx)
+}
+
+// UpdateWebhook mengganti URL, tipe event dan status aktif. Secret hanya diganti
+// jika diisi. Mengaktifkan kembali webhook yang dinonaktifkan otomatis akan
+// mereset penghitung kegagalannya.
+func (uc *WebhookUseCase) UpdateWebhook(ctx context.Context, id uint, webhook *entity.Webhook) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookUseCase.UpdateWebhook")
+	defer span.Finish()
+
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+	existing, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	existing.URL = webhook.URL
+	existing.EventTypes = webhook.EventTypes
+	if webhook.Secret != "" {
+		existing.Secret = webhook.Secret
+	}
+	if webhook.Active && !existing.Active {
+		existing.Failures = 0
+		existing.DisabledAt = nil
+		existing.DisabledWhy = ""
+	}
+	existing.Active = webhook.Active
+
+	if err := uc.repo.Update(ctx, existing); err != nil {
+		span.LogFields(log.Error(err))
+		return err
+	}
+	*webhook = *existing
+	return nil
+}
+
+func (uc *WebhookUseCase) DeleteWebhook(ctx context.Context, id uint) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookUseCase.DeleteWebhook")
+	defer span.Finish()
+
+	return uc.repo.Delete(ctx, id)
+}
+
+// ListDeliveries mengembalikan log pengiriman webhook, terbaru lebih dulu
+func (uc *WebhookUseCase) ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]entity.WebhookDelivery, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookUseCase.ListDeliveries")
+	defer span.Finish()
+
+	if _, err := uc.repo.GetByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	return uc.repo.ListDeliveries(ctx, webhookID, limit)
+}
+
+// Redeliver menjadwalkan ulang payload delivery sebagai delivery baru
+func (uc *WebhookUseCase) Redeliver(ctx context.Context, webhookID uint, deliveryID uint64) (*entity.WebhookDelivery, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookUseCase.Redeliver")
+	defer span.Finish()
+
+	original, err := uc.repo.GetDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	origin := original.ID
+	if original.RedeliveryOf != nil {
+		origin = *original.RedeliveryOf
+	}
+	batch := []entity.WebhookDelivery{{
+		WebhookID:     original.WebhookID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		RedeliveryOf:  &origin,
+		Status:        entity.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}}
+	// Slice diteruskan apa adanya supaya ID hasil insert terisi di batch[0]
+	if err := uc.repo.EnqueueDeliveries(ctx, batch...); err != nil {
+		span.LogFields(log.Error(err))
+		return nil, fmt.Errorf("redeliver webhook failed: %w", err)
+	}
+	return &batch[0], nil
+}
+
+// EnqueueEvent dipanggil consumer Kafka untuk setiap event. Delivery ditulis
+// memakai transaksi di context, jadi ikut atomik bersama tanda dedup consumer.
+func (uc *WebhookUseCase) EnqueueEvent(ctx context.Context, event events.Event) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WebhookUseCase.EnqueueEvent")
+	defer span.Finish()
+
+	webhooks, err := uc.repo.ListActive(ctx)
+	if err != nil {
+		span.LogFields(log.Error(err))
+		return 0, err
+	}
+
+	var payload []byte
+	var deliveries []entity.WebhookDelivery
+	now := time.Now()
+	for _, w := range webhooks {
+		if !webhookMatches(&w, event.Name()) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return 0, fmt.Errorf("marshal webhook payload failed: %w", err)
+			}
+		}
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			WebhookID:     w.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        entity.DeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	if err := uc.repo.EnqueueDeliveries(ctx, deliveries...); err != nil {
+		span.LogFields(log.Error(err))
+		return 0, err
+	}
+	return len(deliveries), nil
+}
+
+// webhookMatches mengecek apakah webhook berlangganan event dengan nama tersebut
+func webhookMatches(webhook *entity.Webhook, name string) bool {
+	if len(webhook.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range webhook.EventTypes {
+		if t == WebhookAllEvents || t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func validateWebhook(webhook *entity.Webhook) error {
+	webhook.URL = strings.TrimSpace(webhook.URL)
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url harus berupa URL http/https absolut", ErrInvalidWebhook)
+	}
+
+	types := make([]string, 0, len(webhook.EventTypes))
+	for _, t := range webhook.EventTypes {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if _, ok := events.Lookup(t); !ok && t != WebhookAllEvents {
+			return fmt.Errorf("%w: tipe event tidak dikenal: %s", ErrInvalidWebhook, t)
+		}
+		types = append(types, t)
+	}
+	webhook.EventTypes = types
+	return nil
+}
+
+func newWebhookSecret() string {
+	var b [32]byte
+	_, _ = rand.Read(b[:])
+	return "whsec_" + hex.EncodeToString(b[:])
+}
+
+// SignWebhookPayload menghitung tanda tangan header X-Webhook-Signature:
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)). Penerima
+// menghitung ulang dengan secret yang sama dan menolak timestamp yang terlalu lama.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
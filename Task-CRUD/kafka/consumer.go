@@ -0,0 +1,14 @@
+package kafka
+
+import (
+	"context"
+)
+
+// StartConsumer menjalankan Consumer untuk semua event di registry sampai ctx
+// dibatalkan. Offset di-commit setelah handler sukses (at-least-once).
+func StartConsumer(ctx context.Context, cfg KafkaConfig, groupID string, registry *Registry) error {
+	return NewConsumer(ConsumerConfig{
+		Brokers: cfg.Brokers,
+		GroupID: groupID,
+	}, registry).Run(ctx)
+}
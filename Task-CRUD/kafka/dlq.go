@@ -0,0 +1,222 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DeadLetter adalah ringkasan satu pesan di topic DLQ
+type DeadLetter struct {
+	Partition int       `json:"partition"`
+	Offset    int64     `json:"offset"`
+	Key       string    `json:"key"`
+	EventID   string    `json:"event_id,omitempty"`
+	EventType string    `json:"event_type,omitempty"`
+	Attempts  int       `json:"attempts"`
+	Error     string    `json:"error"`
+	FailedAt  time.Time `json:"failed_at"`
+
+	OriginalTopic     string `json:"original_topic"`
+	OriginalPartition int    `json:"original_partition"`
+	OriginalOffset    int64  `json:"original_offset"`
+}
+
+// Position menunjuk satu pesan di topic: partisi dan offset
+type Position struct {
+	Partition int
+	Offset    int64
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Partition, p.Offset)
+}
+
+// ParsePosition membaca posisi dengan format "<partition>:<offset>", mis. "0:42"
+func ParsePosition(s string) (Position, error) {
+	part, off, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return Position{}, fmt.Errorf("posisi tidak valid %q (format: partition:offset)", s)
+	}
+	p, err := strconv.Atoi(part)
+	if err != nil || p < 0 {
+		return Position{}, fmt.Errorf("partition tidak valid: %q", part)
+	}
+	o, err := strconv.ParseInt(off, 10, 64)
+	if err != nil || o < 0 {
+		return Position{}, fmt.Errorf("offset tidak valid: %q", off)
+	}
+	return Position{Partition: p, Offset: o}, nil
+}
+
+// DeadLetters membaca topic DLQ dan mengirim ulang pesan pilihan ke topic asalnya
+type DeadLetters struct {
+	brokers []string
+	dialer  *kafka.Dialer
+	writer  *kafka.Writer
+}
+
+func NewDeadLetters(brokers []string, dialer *kafka.Dialer, writer *kafka.Writer) *DeadLetters {
+	if dialer == nil {
+		dialer = &kafka.Dialer{Timeout: 10 * time.Second}
+	}
+	return &DeadLetters{brokers: brokers, dialer: dialer, writer: writer}
+}
+
+// List mengembalikan paling banyak limit pesan DLQ, dari yang terlama per partisi
+func (d *DeadLetters) List(ctx context.Context, topic string, limit int) ([]DeadLetter, error) {
+	var out []DeadLetter
+	err := d.scan(ctx, topic, func(m kafka.Message) bool {
+		out = append(out, DeadLetterOf(ctx, m))
+		return limit <= 0 || len(out) < limit
+	})
+	return out, err
+}
+
+// Replay mengirim pesan DLQ pada posisi yang dipilih kembali ke topic asalnya.
+// Header kegagalan dibuang sehingga pesan diproses dari awal, ditambah header
+// x-replayed-from. Tanpa posisi, semua pesan di DLQ dikirim ulang.
+func (d *DeadLetters) Replay(ctx context.Context, topic string, positions ...Position) (int, error) {
+	if d.writer == nil {
+		return 0, errors.New("replay DLQ membutuhkan Kafka writer")
+	}
+
+	wanted := make(map[Position]bool, len(positions))
+	for _, p := range positions {
+		wanted[p] = true
+	}
+
+	var msgs []kafka.Message
+	err := d.scan(ctx, topic, func(m kafka.Message) bool {
+		pos := Position{m.Partition, m.Offset}
+		if len(wanted) > 0 && !wanted[pos] {
+			return true
+		}
+		delete(wanted, pos)
+
+		headers := withoutFailureHeaders(m.Headers, false)
+		headers = append(headers, kafka.Header{Key: HeaderReplayedFrom, Value: []byte(topic + "/" + pos.String())})
+		msgs = append(msgs, kafka.Message{Topic: originalTopic(m), Key: m.Key, Value: m.Value, Headers: headers})
+		return len(positions) == 0 || len(wanted) > 0
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(wanted) > 0 {
+		missing := make([]string, 0, len(wanted))
+		for p := range wanted {
+			missing = append(missing, p.String())
+		}
+		sort.Strings(missing)
+		return 0, fmt.Errorf("pesan tidak ditemukan di %s: %s", topic, strings.Join(missing, ", "))
+	}
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+
+	if err := d.writer.WriteMessages(ctx, msgs...); err != nil {
+		return 0, fmt.Errorf("gagal mengirim ulang pesan DLQ: %w", err)
+	}
+	return len(msgs), nil
+}
+
+// scan membaca semua pesan yang ada di topic saat ini, partisi demi partisi,
+// sampai fn mengembalikan false
+func (d *DeadLetters) scan(ctx context.Context, topic string, fn func(kafka.Message) bool) error {
+	if len(d.brokers) == 0 {
+		return errors.New("broker Kafka belum dikonfigurasi")
+	}
+	conn, err := d.dialer.DialContext(ctx, "tcp", d.brokers[0])
+	if err != nil {
+		return fmt.Errorf("gagal terhubung ke Kafka: %w", err)
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	conn.Close()
+	if err != nil {
+		return fmt.Errorf("gagal membaca partisi %s: %w", topic, err)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].ID < partitions[j].ID })
+
+	for _, p := range partitions {
+		more, err := d.scanPartition(ctx, topic, p.ID, fn)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (d *DeadLetters) scanPartition(ctx context.Context, topic string, partition int, fn func(kafka.Message) bool) (bool, error) {
+	leader, err := d.dialer.DialLeader(ctx, "tcp", d.brokers[0], topic, partition)
+	if err != nil {
+		return false, fmt.Errorf("gagal terhubung ke leader %s/%d: %w", topic, partition, err)
+	}
+	first, last, err := leader.ReadOffsets()
+	leader.Close()
+	if err != nil {
+		return false, fmt.Errorf("gagal membaca offset %s/%d: %w", topic, partition, err)
+	}
+	if first >= last {
+		return true, nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   d.brokers,
+		Topic:     topic,
+		Partition: partition,
+		Dialer:    d.dialer,
+		MaxWait:   500 * time.Millisecond,
+	})
+	defer reader.Close()
+	if err := reader.SetOffset(first); err != nil {
+		return false, err
+	}
+
+	for {
+		m, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return false, fmt.Errorf("gagal membaca %s/%d: %w", topic, partition, err)
+		}
+		if !fn(m) {
+			return false, nil
+		}
+		if m.Offset >= last-1 {
+			return true, nil
+		}
+	}
+}
+
+// DeadLetterOf meringkas pesan DLQ dari header kegagalan dan isi event-nya
+func DeadLetterOf(ctx context.Context, m kafka.Message) DeadLetter {
+	dl := DeadLetter{
+		Partition:     m.Partition,
+		Offset:        m.Offset,
+		Key:           string(m.Key),
+		Attempts:      attempts(m),
+		OriginalTopic: originalTopic(m),
+	}
+	dl.Error, _ = header(m, HeaderError)
+	if v, ok := header(m, HeaderOriginalPartition); ok {
+		dl.OriginalPartition, _ = strconv.Atoi(v)
+	}
+	if v, ok := header(m, HeaderOriginalOffset); ok {
+		dl.OriginalOffset, _ = strconv.ParseInt(v, 10, 64)
+	}
+	if v, ok := header(m, HeaderFailedAt); ok {
+		dl.FailedAt, _ = time.Parse(time.RFC3339, v)
+	}
+	if event, err := DecodeEvent(ctx, m); err == nil {
+		dl.EventID = event.ID
+		dl.EventType = event.Type
+	}
+	return dl
+}
@@ -0,0 +1,365 @@
+package kafka
+
+import (
+	"context"
+	"embed"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"Task-CRUD/internal/events"
+	"Task-CRUD/internal/schemaregistry"
+
+	"github.com/hamba/avro/v2"
+	"github.com/segmentio/kafka-go"
+)
+
+// Encoding value pesan event di Kafka, dipilih per topic
+const (
+	EncodingJSON = "json"
+	EncodingAvro = "avro"
+
+	// ContentTypeAvro menandai CloudEvent Avro dalam wire format Confluent:
+	// magic byte 0, schema ID 4 byte big-endian, lalu body Avro
+	ContentTypeAvro = "application/cloudevents+avro"
+)
+
+// avroMagicByte adalah byte pertama wire format Confluent
+const avroMagicByte = 0
+
+// ErrInvalidEvent menandai pesan yang tidak akan pernah bisa di-decode
+// (bukan CloudEvent, schema tidak dikenal, body rusak). Error decode lain,
+// mis. registry tidak bisa dihubungi, bersifat sementara.
+var ErrInvalidEvent = errors.New("pesan bukan CloudEvent yang valid")
+
+//go:embed avro/*.avsc
+var avroFiles embed.FS
+
+// avroTypes memetakan tipe aggregate ke schema Avro envelope-nya
+var avroTypes = map[string]avroType{
+	"repository": newAvroType[avroRepository]("avro/repository.avsc"),
+	"user":       newAvroType[avroUser]("avro/user.avsc"),
+}
+
+// Encodings dipakai Publisher, outbox relay dan consumer; diisi dari
+// konfigurasi KAFKA_ENCODING/KAFKA_TOPIC_ENCODINGS saat startup
+var Encodings = NewCodec(EncodingJSON, nil, nil)
+
+// Codec meng-encode event menjadi value pesan Kafka sesuai encoding topic
+// tujuan, dan men-decode pesan dari format apa pun lewat header content-type.
+// Schema Avro didaftarkan di registry dengan subject "<topic>-value"
+// (TopicNameStrategy); ID hasil register dan schema hasil lookup di-cache.
+type Codec struct {
+	Default  string            // json | avro
+	Topics   map[string]string // topic -> encoding
+	Registry schemaregistry.Registry
+
+	mu      sync.RWMutex
+	ids     map[string]int      // subject + aggregate -> schema ID
+	schemas map[int]avro.Schema // schema ID -> schema penulis
+	types   map[int]avroType    // schema ID -> tipe Go untuk decode
+}
+
+func NewCodec(defaultEncoding string, topics map[string]string, registry schemaregistry.Registry) *Codec {
+	return &Codec{
+		Default:  defaultEncoding,
+		Topics:   topics,
+		Registry: registry,
+		ids:      make(map[string]int),
+		schemas:  make(map[int]avro.Schema),
+		types:    make(map[int]avroType),
+	}
+}
+
+// Encoding mengembalikan encoding untuk topic
+func (c *Codec) Encoding(topic string) string {
+	if enc, ok := c.Topics[topic]; ok {
+		return enc
+	}
+	if c.Default == "" {
+		return EncodingJSON
+	}
+	return c.Default
+}
+
+// Encode mengubah event menjadi value pesan untuk topic beserta content type-nya
+func (c *Codec) Encode(ctx context.Context, topic string, event events.Event) ([]byte, string, error) {
+	if c.Encoding(topic) != EncodingAvro {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return nil, "", fmt.Errorf("marshal kafka payload failed: %w", err)
+		}
+		return value, events.ContentType, nil
+	}
+
+	aggregate, _, _ := strings.Cut(event.Name(), ".")
+	t, ok := avroTypes[aggregate]
+	if !ok {
+		return nil, "", fmt.Errorf("event %s tidak punya schema Avro", event.Name())
+	}
+	id, err := c.register(ctx, topic+"-value", aggregate, t)
+	if err != nil {
+		return nil, "", err
+	}
+	record, err := t.fromEvent(event)
+	if err != nil {
+		return nil, "", fmt.Errorf("konversi event %s ke Avro gagal: %w", event.ID, err)
+	}
+	body, err := avro.Marshal(t.schema, record)
+	if err != nil {
+		return nil, "", fmt.Errorf("encode Avro event %s gagal: %w", event.ID, err)
+	}
+
+	value := make([]byte, 5, 5+len(body))
+	value[0] = avroMagicByte
+	binary.BigEndian.PutUint32(value[1:], uint32(id))
+	return append(value, body...), ContentTypeAvro, nil
+}
+
+// Decode membaca event dari pesan. Format dipilih dari header content-type;
+// tanpa header, magic byte 0 berarti Avro dan selain itu JSON.
+func (c *Codec) Decode(ctx context.Context, m kafka.Message) (events.Event, error) {
+	contentType, _ := header(m, contentTypeHeader())
+	avroValue := contentType == ContentTypeAvro ||
+		(contentType == "" && len(m.Value) > 0 && m.Value[0] == avroMagicByte)
+	if !avroValue {
+		event, err := events.Decode(m.Value)
+		if err != nil {
+			return events.Event{}, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+		}
+		return event, nil
+	}
+
+	if len(m.Value) < 5 || m.Value[0] != avroMagicByte {
+		return events.Event{}, fmt.Errorf("%w: wire format Avro tidak valid", ErrInvalidEvent)
+	}
+	id := int(binary.BigEndian.Uint32(m.Value[1:5]))
+	schema, t, err := c.writerSchema(ctx, id)
+	if err != nil {
+		return events.Event{}, err
+	}
+	record := t.newRecord()
+	if err := avro.Unmarshal(schema, m.Value[5:], record); err != nil {
+		return events.Event{}, fmt.Errorf("%w: decode Avro gagal: %v", ErrInvalidEvent, err)
+	}
+	event, err := record.toEvent()
+	if err != nil {
+		return events.Event{}, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if event.SpecVersion != events.SpecVersion || event.ID == "" || event.Type == "" {
+		return events.Event{}, fmt.Errorf("%w: bukan CloudEvent %s yang valid", ErrInvalidEvent, events.SpecVersion)
+	}
+	return event, nil
+}
+
+func (c *Codec) register(ctx context.Context, subject, aggregate string, t avroType) (int, error) {
+	key := subject + "\x00" + aggregate
+	c.mu.RLock()
+	id, ok := c.ids[key]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+	if c.Registry == nil {
+		return 0, errors.New("encoding Avro membutuhkan schema registry (SCHEMA_REGISTRY_URL)")
+	}
+
+	id, err := c.Registry.Register(ctx, subject, schemaregistry.Schema{Type: schemaregistry.TypeAvro, Schema: t.schema.String()})
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	c.ids[key] = id
+	c.schemas[id] = t.schema
+	c.types[id] = t
+	c.mu.Unlock()
+	return id, nil
+}
+
+// writerSchema mengambil schema penulis dari registry dan memetakan nama
+// record-nya ke tipe Go. Schema yang tidak ada di registry atau tidak dikenal
+// adalah ErrInvalidEvent; kegagalan menghubungi registry tidak.
+func (c *Codec) writerSchema(ctx context.Context, id int) (avro.Schema, avroType, error) {
+	c.mu.RLock()
+	schema, ok := c.schemas[id]
+	t := c.types[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, t, nil
+	}
+
+	if c.Registry == nil {
+		return nil, avroType{}, errors.New("pesan Avro membutuhkan schema registry (SCHEMA_REGISTRY_URL)")
+	}
+	found, err := c.Registry.SchemaByID(ctx, id)
+	if errors.Is(err, schemaregistry.ErrNotFound) {
+		return nil, avroType{}, fmt.Errorf("%w: schema %d tidak ada di registry", ErrInvalidEvent, id)
+	}
+	if err != nil {
+		return nil, avroType{}, err
+	}
+	if found.Type != "" && found.Type != schemaregistry.TypeAvro {
+		return nil, avroType{}, fmt.Errorf("%w: schema %d bertipe %s, bukan Avro", ErrInvalidEvent, id, found.Type)
+	}
+	// Cache terpisah supaya versi lain dari record bernama sama tidak saling menimpa
+	schema, err = avro.ParseWithCache(found.Schema, "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, avroType{}, fmt.Errorf("%w: schema %d tidak valid: %v", ErrInvalidEvent, id, err)
+	}
+	t, ok = avroTypeByName(schema)
+	if !ok {
+		return nil, avroType{}, fmt.Errorf("%w: record Avro schema %d tidak dikenal", ErrInvalidEvent, id)
+	}
+
+	c.mu.Lock()
+	c.schemas[id] = schema
+	c.types[id] = t
+	c.mu.Unlock()
+	return schema, t, nil
+}
+
+// EncodeEvent meng-encode event untuk topic dengan Encodings
+func EncodeEvent(ctx context.Context, topic string, event events.Event) ([]byte, string, error) {
+	return Encodings.Encode(ctx, topic, event)
+}
+
+// DecodeEvent men-decode pesan dengan Encodings
+func DecodeEvent(ctx context.Context, m kafka.Message) (events.Event, error) {
+	return Encodings.Decode(ctx, m)
+}
+
+func contentTypeHeader() string {
+	key, _ := events.Header()
+	return key
+}
+
+// avroRecord adalah envelope Avro satu aggregate
+type avroRecord interface {
+	toEvent() (events.Event, error)
+}
+
+// avroType menghubungkan schema Avro envelope aggregate dengan tipe Go-nya
+type avroType struct {
+	schema    avro.Schema
+	newRecord func() avroRecord
+	fromEvent func(events.Event) (interface{}, error)
+}
+
+func newAvroType[T any](file string) avroType {
+	raw, err := avroFiles.ReadFile(file)
+	if err != nil {
+		panic(err)
+	}
+	schema, err := avro.ParseWithCache(string(raw), "", &avro.SchemaCache{})
+	if err != nil {
+		panic(fmt.Sprintf("schema Avro %s tidak valid: %v", file, err))
+	}
+	return avroType{
+		schema:    schema,
+		newRecord: func() avroRecord { return &avroEnvelope[T]{} },
+		fromEvent: func(event events.Event) (interface{}, error) {
+			record := &avroEnvelope[T]{}
+			return record, record.fromEvent(event)
+		},
+	}
+}
+
+// avroTypeByName mencari tipe Go dari nama lengkap record schema penulis
+func avroTypeByName(schema avro.Schema) (avroType, bool) {
+	named, ok := schema.(avro.NamedSchema)
+	if !ok {
+		return avroType{}, false
+	}
+	for _, t := range avroTypes {
+		if t.schema.(avro.NamedSchema).FullName() == named.FullName() {
+			return t, true
+		}
+	}
+	return avroType{}, false
+}
+
+// avroEnvelope adalah events.Event dengan Data bertipe, sesuai avro/<aggregate>.avsc
+type avroEnvelope[T any] struct {
+	SpecVersion     string        `avro:"specversion"`
+	ID              string        `avro:"id"`
+	Source          string        `avro:"source"`
+	Type            string        `avro:"type"`
+	Subject         string        `avro:"subject"`
+	Time            time.Time     `avro:"time"`
+	DataContentType string        `avro:"datacontenttype"`
+	DataSchema      string        `avro:"dataschema"`
+	CorrelationID   string        `avro:"correlationid"`
+	CausationID     string        `avro:"causationid"`
+	Data            avroChange[T] `avro:"data"`
+}
+
+// avroChange adalah events.Change dengan before/after bertipe
+type avroChange[T any] struct {
+	Before  *T       `avro:"before" json:"before"`
+	After   *T       `avro:"after" json:"after"`
+	Changed []string `avro:"changed" json:"changed,omitempty"`
+}
+
+func (r *avroEnvelope[T]) fromEvent(e events.Event) error {
+	*r = avroEnvelope[T]{
+		SpecVersion:     e.SpecVersion,
+		ID:              e.ID,
+		Source:          e.Source,
+		Type:            e.Type,
+		Subject:         e.Subject,
+		Time:            e.Time,
+		DataContentType: e.DataContentType,
+		DataSchema:      e.DataSchema,
+		CorrelationID:   e.CorrelationID,
+		CausationID:     e.CausationID,
+	}
+	if len(e.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(e.Data, &r.Data)
+}
+
+func (r *avroEnvelope[T]) toEvent() (events.Event, error) {
+	data, err := json.Marshal(r.Data)
+	if err != nil {
+		return events.Event{}, err
+	}
+	return events.Event{
+		SpecVersion:     r.SpecVersion,
+		ID:              r.ID,
+		Source:          r.Source,
+		Type:            r.Type,
+		Subject:         r.Subject,
+		Time:            r.Time.UTC(),
+		DataContentType: r.DataContentType,
+		DataSchema:      r.DataSchema,
+		CorrelationID:   r.CorrelationID,
+		CausationID:     r.CausationID,
+		Data:            data,
+	}, nil
+}
+
+// avroUser dan avroRepository mencerminkan bentuk JSON entity di Data event
+type avroUser struct {
+	ID        int64     `avro:"id" json:"id"`
+	Name      string    `avro:"name" json:"name"`
+	Email     string    `avro:"email" json:"email"`
+	CreatedAt time.Time `avro:"created_at" json:"created_at"`
+	UpdatedAt time.Time `avro:"updated_at" json:"updated_at"`
+}
+
+type avroRepository struct {
+	ID          int64     `avro:"id" json:"id"`
+	Name        string    `avro:"name" json:"name"`
+	UserID      int64     `avro:"user_id" json:"user_id"`
+	User        avroUser  `avro:"user" json:"user"`
+	URL         string    `avro:"url" json:"url"`
+	AIEnabled   bool      `avro:"ai_enabled" json:"ai_enabled"`
+	CreatedAt   time.Time `avro:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `avro:"updated_at" json:"updated_at"`
+	Description string    `avro:"description" json:"description"`
+}
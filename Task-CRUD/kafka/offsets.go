@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type partitionKey struct {
+	topic     string
+	partition int
+}
+
+// OffsetTracker mencatat pesan yang sedang diproses per partisi. Offset hanya
+// boleh di-commit sampai pesan terakhir yang semua pendahulunya sudah sukses,
+// karena worker dalam satu partisi bisa selesai tidak berurutan (beda key).
+type OffsetTracker struct {
+	mu         sync.Mutex
+	partitions map[partitionKey]*partitionOffsets
+}
+
+type partitionOffsets struct {
+	entries   []*offsetEntry         // urut sesuai offset
+	byOffset  map[int64]*offsetEntry // untuk menandai selesai
+	committed int64                  // offset terakhir yang siap di-commit, -1 jika belum ada
+	dirty     bool                   // committed berubah sejak commit terakhir
+}
+
+type offsetEntry struct {
+	offset int64
+	done   bool
+}
+
+// NewOffsetTracker membuat tracker kosong (satu per lane consumer)
+func NewOffsetTracker() *OffsetTracker {
+	return &OffsetTracker{partitions: make(map[partitionKey]*partitionOffsets)}
+}
+
+// Track mencatat pesan yang baru di-fetch. Offset yang mundur berarti partisi
+// dikirim ulang (rebalance); catatan lama dibuang karena akan diterima lagi.
+func (t *OffsetTracker) Track(m kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := partitionKey{m.Topic, m.Partition}
+	p, ok := t.partitions[key]
+	if !ok || (len(p.entries) > 0 && m.Offset <= p.entries[len(p.entries)-1].offset) {
+		p = &partitionOffsets{byOffset: make(map[int64]*offsetEntry), committed: -1}
+		t.partitions[key] = p
+	}
+	e := &offsetEntry{offset: m.Offset}
+	p.entries = append(p.entries, e)
+	p.byOffset[m.Offset] = e
+}
+
+// Done menandai pesan selesai lalu memajukan batas commit partisinya
+func (t *OffsetTracker) Done(m kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[partitionKey{m.Topic, m.Partition}]
+	if !ok {
+		return
+	}
+	e, ok := p.byOffset[m.Offset]
+	if !ok {
+		return
+	}
+	e.done = true
+
+	for len(p.entries) > 0 && p.entries[0].done {
+		p.committed = p.entries[0].offset
+		p.dirty = true
+		delete(p.byOffset, p.entries[0].offset)
+		p.entries = p.entries[1:]
+	}
+}
+
+// Ready mengembalikan pesan (topic, partition, offset) yang siap di-commit sejak pemanggilan sebelumnya
+func (t *OffsetTracker) Ready() []kafka.Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var msgs []kafka.Message
+	for key, p := range t.partitions {
+		if !p.dirty {
+			continue
+		}
+		p.dirty = false
+		msgs = append(msgs, kafka.Message{Topic: key.topic, Partition: key.partition, Offset: p.committed})
+	}
+	return msgs
+}
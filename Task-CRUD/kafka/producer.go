@@ -4,26 +4,105 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"Task-CRUD/internal/events"
 
 	"github.com/segmentio/kafka-go"
 )
 
-func StartConsumer(cfg KafkaConfig) {
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: cfg.Brokers,
-		Topic:   cfg.Topic,
-		GroupID: "task-crud-group",
-	})
-
-	go func() {
-		fmt.Println("🟢 Kafka consumer listening...")
-		for {
-			m, err := reader.ReadMessage(context.Background())
-			if err != nil {
-				log.Printf("❌ Error reading Kafka message: %v", err)
-				continue
+// asyncPublishTimeout membatasi Publish async yang tidak lagi terikat context pemanggil
+const asyncPublishTimeout = 30 * time.Second
+
+// Publisher adalah EventPublisher Kafka. Writer bisa diganti atau dimatikan
+// saat broker hilang dan kembali; selama nonaktif Publish mengembalikan
+// events.ErrPublisherUnavailable.
+type Publisher struct {
+	writer atomic.Pointer[kafka.Writer]
+}
+
+// NewPublisher membuat Publisher; writer nil berarti publisher nonaktif
+func NewPublisher(writer *kafka.Writer) *Publisher {
+	p := &Publisher{}
+	p.writer.Store(writer)
+	return p
+}
+
+// Use mengaktifkan writer, atau menonaktifkan publisher jika writer nil
+func (p *Publisher) Use(writer *kafka.Writer) {
+	p.writer.Store(writer)
+}
+
+// Writer mengembalikan writer aktif, nil jika publisher nonaktif
+func (p *Publisher) Writer() *kafka.Writer {
+	if p == nil {
+		return nil
+	}
+	return p.writer.Load()
+}
+
+// Publish mengirim event ke topic hasil events.Topic. Key default adalah ID
+// aggregate, sehingga event satu entity selalu masuk partisi yang sama.
+func (p *Publisher) Publish(ctx context.Context, event events.Event, opts ...events.PublishOption) error {
+	writer := p.Writer()
+	if writer == nil {
+		return events.ErrPublisherUnavailable
+	}
+	o := events.ApplyPublishOptions(event, opts...)
+
+	topic := events.Topic(event.Name())
+	value, contentType, err := EncodeEvent(ctx, topic, event)
+	if err != nil {
+		return err
+	}
+	msg := kafka.Message{
+		Topic:   topic,
+		Key:     []byte(o.Key),
+		Value:   value,
+		Headers: append(EventHeaders(event.Type, contentType), extraHeaders(o.Headers)...),
+	}
+
+	if o.Async {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), asyncPublishTimeout)
+			defer cancel()
+			if err := writer.WriteMessages(ctx, msg); err != nil {
+				log.Printf("❌ Kafka send failed (async) %s: %v", msg.Topic, err)
 			}
-			fmt.Printf("📥 Kafka received: %s\n", string(m.Value))
-		}
-	}()
+		}()
+		return nil
+	}
+	if err := writer.WriteMessages(ctx, msg); err != nil {
+		log.Printf("❌ Kafka send failed %s: %v", msg.Topic, err)
+		return err
+	}
+	fmt.Println("📤 Kafka event terkirim:", msg.Topic)
+	return nil
+}
+
+// EventHeaders mengembalikan header Kafka untuk CloudEvent: content type
+// (JSON atau Avro, lihat Codec) dan tipe event
+func EventHeaders(eventType, contentType string) []kafka.Header {
+	headers := []kafka.Header{{Key: contentTypeHeader(), Value: []byte(contentType)}}
+	if eventType != "" {
+		headers = append(headers, kafka.Header{Key: events.TypeHeader, Value: []byte(eventType)})
+	}
+	return headers
+}
+
+// extraHeaders mengubah header opsi Publish menjadi header Kafka, terurut per key
+func extraHeaders(headers map[string]string) []kafka.Header {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]kafka.Header, len(keys))
+	for i, k := range keys {
+		out[i] = kafka.Header{Key: k, Value: []byte(headers[k])}
+	}
+	return out
 }
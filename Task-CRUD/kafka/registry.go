@@ -0,0 +1,76 @@
+package kafka
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"Task-CRUD/internal/events"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Message adalah pesan yang diterima handler: CloudEvent yang sudah di-decode
+// beserta pesan Kafka aslinya (topic, partition, offset, header)
+type Message struct {
+	Event events.Event
+	Raw   kafka.Message
+}
+
+// Handler memproses satu event. Error membuat pesan dipindah ke topic retry
+// (lalu DLQ), jadi handler harus aman dipanggil lebih dari sekali.
+type Handler func(ctx context.Context, msg Message) error
+
+// Registry memetakan nama event (mis. "repository.created") ke handler-nya.
+// Satu nama boleh punya beberapa handler; semuanya dijalankan berurutan.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string][]Handler)}
+}
+
+// Register menambahkan handler untuk satu atau beberapa nama event
+func (r *Registry) Register(handler Handler, names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		r.handlers[name] = append(r.handlers[name], handler)
+	}
+}
+
+// Names mengembalikan semua nama event yang punya handler, terurut
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.handlers))
+	for name := range r.handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Topics mengembalikan topic Kafka untuk semua event yang punya handler, tanpa
+// duplikat (pada mode per aggregate beberapa event berbagi satu topic)
+func (r *Registry) Topics() []string {
+	seen := make(map[string]bool)
+	var topics []string
+	for _, name := range r.Names() {
+		topic := events.Topic(name)
+		if !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+func (r *Registry) lookup(name string) []Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.handlers[name]
+}
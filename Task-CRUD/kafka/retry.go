@@ -0,0 +1,133 @@
+package kafka
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Header yang ditambahkan saat pesan gagal diproses dan dipindah ke topic retry/DLQ
+const (
+	HeaderError             = "x-error"
+	HeaderAttempts          = "x-attempts"
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+	HeaderFailedAt          = "x-failed-at"
+	HeaderRetryAt           = "x-retry-at" // unix milidetik; pesan tidak diproses sebelum waktu ini
+	HeaderReplayedFrom      = "x-replayed-from"
+
+	maxErrorHeaderLen = 1024
+)
+
+// DefaultRetryDelays adalah jeda tiap tingkat retry topic sebelum pesan masuk DLQ
+var DefaultRetryDelays = []time.Duration{10 * time.Second, time.Minute, 10 * time.Minute}
+
+// RetryTopic mengembalikan nama topic retry untuk topic asal, mis. "repository_created.retry.1m"
+func RetryTopic(topic string, delay time.Duration) string {
+	return topic + ".retry." + formatDelay(delay)
+}
+
+// DeadLetterTopic mengembalikan nama topic DLQ untuk topic asal, mis. "repository_created.dlq"
+func DeadLetterTopic(topic string) string {
+	return topic + ".dlq"
+}
+
+func formatDelay(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return fmt.Sprintf("%dms", d/time.Millisecond)
+}
+
+// header mengembalikan nilai header pertama dengan nama tersebut
+func header(m kafka.Message, key string) (string, bool) {
+	for _, h := range m.Headers {
+		if h.Key == key {
+			return string(h.Value), true
+		}
+	}
+	return "", false
+}
+
+// originalTopic mengembalikan topic tempat pesan pertama kali diterbitkan
+func originalTopic(m kafka.Message) string {
+	if t, ok := header(m, HeaderOriginalTopic); ok && t != "" {
+		return t
+	}
+	return m.Topic
+}
+
+// attempts mengembalikan jumlah percobaan yang sudah gagal sebelum pesan ini
+func attempts(m kafka.Message) int {
+	v, _ := header(m, HeaderAttempts)
+	n, _ := strconv.Atoi(v)
+	return n
+}
+
+// retryAt mengembalikan waktu paling awal pesan retry boleh diproses
+func retryAt(m kafka.Message) time.Time {
+	v, ok := header(m, HeaderRetryAt)
+	if !ok {
+		return time.Time{}
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// withoutFailureHeaders menyalin header pesan tanpa header kegagalan
+// (header asal seperti content-type dan outbox-id tetap dibawa)
+func withoutFailureHeaders(headers []kafka.Header, keepOrigin bool) []kafka.Header {
+	out := make([]kafka.Header, 0, len(headers)+7)
+	for _, h := range headers {
+		switch h.Key {
+		case HeaderError, HeaderAttempts, HeaderFailedAt, HeaderRetryAt, HeaderReplayedFrom:
+			continue
+		case HeaderOriginalTopic, HeaderOriginalPartition, HeaderOriginalOffset:
+			if !keepOrigin {
+				continue
+			}
+		}
+		out = append(out, h)
+	}
+	return out
+}
+
+// FailedMessage membangun pesan untuk topic retry/DLQ: key dan value tidak
+// berubah, ditambah error, jumlah percobaan, dan posisi pesan asal
+func FailedMessage(m kafka.Message, target string, cause error, delay time.Duration, now time.Time) kafka.Message {
+	_, hasOrigin := header(m, HeaderOriginalTopic)
+	headers := withoutFailureHeaders(m.Headers, hasOrigin)
+	if !hasOrigin {
+		headers = append(headers,
+			kafka.Header{Key: HeaderOriginalTopic, Value: []byte(m.Topic)},
+			kafka.Header{Key: HeaderOriginalPartition, Value: []byte(strconv.Itoa(m.Partition))},
+			kafka.Header{Key: HeaderOriginalOffset, Value: []byte(strconv.FormatInt(m.Offset, 10))},
+		)
+	}
+
+	msg := cause.Error()
+	if len(msg) > maxErrorHeaderLen {
+		msg = msg[:maxErrorHeaderLen]
+	}
+	headers = append(headers,
+		kafka.Header{Key: HeaderError, Value: []byte(msg)},
+		kafka.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(attempts(m) + 1))},
+		kafka.Header{Key: HeaderFailedAt, Value: []byte(now.UTC().Format(time.RFC3339))},
+	)
+	if delay > 0 {
+		headers = append(headers, kafka.Header{Key: HeaderRetryAt, Value: []byte(strconv.FormatInt(now.Add(delay).UnixMilli(), 10))})
+	}
+
+	return kafka.Message{Topic: target, Key: m.Key, Value: m.Value, Headers: headers}
+}
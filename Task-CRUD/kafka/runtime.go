@@ -0,0 +1,525 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// ConsumerConfig mengatur Consumer. Nilai nol memakai default.
+type ConsumerConfig struct {
+	Brokers []string
+	GroupID string
+	Topics  []string // default: topic dari semua event di Registry
+
+	WorkersPerPartition int           // default 4
+	QueueSize           int           // antrean per worker (default 16)
+	CommitInterval      time.Duration // default 1s
+	MinBackoff          time.Duration // jeda saat fetch/publish ke retry gagal (default 500ms)
+	MaxBackoff          time.Duration // default 30s
+	ShutdownTimeout     time.Duration // batas menunggu handler yang sedang jalan (default 10s)
+
+	// RetryDelays adalah jeda tiap tingkat retry topic. nil memakai
+	// DefaultRetryDelays; slice kosong berarti pesan gagal langsung ke DLQ.
+	RetryDelays []time.Duration
+
+	Dialer *kafka.Dialer // opsional (TLS/SASL)
+	Writer *kafka.Writer // untuk topic retry/DLQ; default dibuat dari Brokers
+
+	// Dedup mencatat event ID yang sudah diproses per GroupID sehingga event
+	// yang dikirim ulang Kafka dilewati. Opsional.
+	Dedup DedupStore
+	// Transactor, jika diisi, membungkus tanda dedup dan handler dalam satu
+	// transaksi database: handler yang menulis lewat tx.DB/tx.SQL ikut commit
+	// atau rollback bersama tandanya. Hanya bermakna jika Dedup memakai Postgres.
+	Transactor Transactor
+	// DedupRetention adalah umur tanda dedup sebelum dihapus (default 7 hari)
+	DedupRetention time.Duration
+}
+
+// DedupStore menyimpan event yang sudah diproses (lihat repository/dedup)
+type DedupStore interface {
+	Seen(ctx context.Context, group, eventID string) (bool, error)
+	Mark(ctx context.Context, group, eventID, eventType string) (bool, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Transactor menjalankan fn di dalam satu transaksi database (lihat repository/tx)
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// errDuplicate membatalkan transaksi handler karena event sudah ditandai consumer lain
+var errDuplicate = errors.New("event sudah diproses")
+
+// Consumer membaca event dari Kafka dan menjalankan handler dari Registry.
+//   - Setiap partisi punya pool worker terbatas; pesan dengan key yang sama
+//     selalu ke worker yang sama sehingga urutannya terjaga.
+//   - Handler yang gagal tidak diulang di tempat: pesan dipindah ke topic
+//     retry berikutnya (<topic>.retry.10s, .1m, .10m) lalu ke <topic>.dlq,
+//     sehingga poison message tidak menahan partisi.
+//   - Dengan Dedup, event ID yang sudah diproses group ini dilewati dan
+//     dihitung di Stats().Duplicates.
+//   - Offset hanya di-commit setelah pesan itu dan semua pesan sebelumnya di
+//     partisi selesai (sukses atau sudah dipindah ke retry/DLQ).
+//   - Saat ctx dibatalkan, fetch berhenti, handler yang sedang jalan diberi
+//     waktu ShutdownTimeout, lalu offset terakhir di-commit.
+type Consumer struct {
+	cfg      ConsumerConfig
+	registry *Registry
+	writer   *kafka.Writer
+	ownsW    bool
+
+	lanes    []*lane
+	stats    consumerStats
+	wg       sync.WaitGroup
+	stopping atomic.Bool
+	stopped  chan struct{} // ditutup saat shutdown dimulai, memutus jeda retry
+}
+
+// lane adalah satu reader beserta worker-nya: topic utama, atau satu tingkat retry
+type lane struct {
+	c       *Consumer
+	groupID string
+	topics  []string
+	tier    int           // 0 = topic utama, 1..n = tingkat retry
+	delay   time.Duration // jeda tingkat retry ini
+
+	reader  *kafka.Reader
+	offsets *OffsetTracker
+	pools   map[partitionKey]*partitionPool
+}
+
+type partitionPool struct {
+	queues []chan kafka.Message
+	next   int // round robin untuk pesan tanpa key
+}
+
+func NewConsumer(cfg ConsumerConfig, registry *Registry) *Consumer {
+	if cfg.WorkersPerPartition <= 0 {
+		cfg.WorkersPerPartition = 4
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 16
+	}
+	if cfg.CommitInterval <= 0 {
+		cfg.CommitInterval = time.Second
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = 500 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 30 * time.Second
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 10 * time.Second
+	}
+	if cfg.DedupRetention <= 0 {
+		cfg.DedupRetention = 7 * 24 * time.Hour
+	}
+	if cfg.RetryDelays == nil {
+		cfg.RetryDelays = DefaultRetryDelays
+	}
+	if len(cfg.Topics) == 0 {
+		cfg.Topics = registry.Topics()
+	}
+
+	c := &Consumer{
+		cfg:      cfg,
+		registry: registry,
+		writer:   cfg.Writer,
+		stopped:  make(chan struct{}),
+	}
+	if c.writer == nil {
+		c.writer = &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.Brokers...),
+			Balancer:               &kafka.Hash{},
+			AllowAutoTopicCreation: true,
+		}
+		c.ownsW = true
+	}
+
+	c.lanes = append(c.lanes, c.newLane(cfg.GroupID, cfg.Topics, 0, 0))
+	for i, delay := range cfg.RetryDelays {
+		topics := make([]string, len(cfg.Topics))
+		for j, t := range cfg.Topics {
+			topics[j] = RetryTopic(t, delay)
+		}
+		// Group terpisah supaya assignment partisi retry tidak bercampur dengan topic utama
+		c.lanes = append(c.lanes, c.newLane(cfg.GroupID+".retry."+formatDelay(delay), topics, i+1, delay))
+	}
+	return c
+}
+
+func (c *Consumer) newLane(groupID string, topics []string, tier int, delay time.Duration) *lane {
+	return &lane{
+		c:       c,
+		groupID: groupID,
+		topics:  topics,
+		tier:    tier,
+		delay:   delay,
+		offsets: NewOffsetTracker(),
+		pools:   make(map[partitionKey]*partitionPool),
+	}
+}
+
+// Topics mengembalikan semua topic yang dibaca atau ditulis Consumer:
+// topic utama, topic retry, dan DLQ
+func (c *Consumer) Topics() []string {
+	var topics []string
+	for _, l := range c.lanes {
+		topics = append(topics, l.topics...)
+	}
+	for _, t := range c.cfg.Topics {
+		topics = append(topics, DeadLetterTopic(t))
+	}
+	return topics
+}
+
+// Run memproses pesan sampai ctx dibatalkan. Mengembalikan nil saat berhenti normal.
+func (c *Consumer) Run(ctx context.Context) error {
+	if len(c.cfg.Topics) == 0 {
+		return errors.New("tidak ada topic untuk dikonsumsi")
+	}
+	if c.cfg.GroupID == "" {
+		return errors.New("consumer membutuhkan group ID")
+	}
+
+	// Handler tetap berjalan saat ctx dibatalkan supaya pesan yang sedang diproses
+	// selesai; handlerCtx baru dibatalkan setelah ShutdownTimeout
+	handlerCtx, cancelHandlers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandlers()
+
+	var fetchers sync.WaitGroup
+	for _, l := range c.lanes {
+		l.reader = kafka.NewReader(kafka.ReaderConfig{
+			Brokers:     c.cfg.Brokers,
+			GroupID:     l.groupID,
+			GroupTopics: l.topics,
+			Dialer:      c.cfg.Dialer,
+			MaxWait:     time.Second,
+		})
+		fetchers.Add(1)
+		go func(l *lane) {
+			defer fetchers.Done()
+			l.fetch(ctx, handlerCtx)
+		}(l)
+	}
+
+	commitDone := make(chan struct{})
+	go c.commitLoop(ctx, commitDone)
+	if c.cfg.Dedup != nil {
+		go c.purgeLoop(ctx)
+	}
+
+	log.Printf("🟢 Kafka consumer %s mendengarkan %v (retry: %v)", c.cfg.GroupID, c.cfg.Topics, c.cfg.RetryDelays)
+	fetchers.Wait()
+
+	log.Println("🛑 Menghentikan Kafka consumer...")
+	c.shutdown(cancelHandlers)
+	<-commitDone
+
+	var errList error
+	for _, l := range c.lanes {
+		l.commit()
+		errList = errors.Join(errList, l.reader.Close())
+	}
+	if c.ownsW {
+		errList = errors.Join(errList, c.writer.Close())
+	}
+	return errList
+}
+
+// fetch membaca pesan sampai ctx dibatalkan lalu membagikannya ke worker
+func (l *lane) fetch(ctx, handlerCtx context.Context) {
+	backoff := l.c.cfg.MinBackoff
+	for {
+		m, err := l.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("❌ Gagal membaca pesan Kafka (%s): %v", l.groupID, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, l.c.cfg.MaxBackoff)
+			continue
+		}
+		backoff = l.c.cfg.MinBackoff
+
+		l.offsets.Track(m)
+		if !l.dispatch(ctx, handlerCtx, m) {
+			return
+		}
+	}
+}
+
+// dispatch mengirim pesan ke worker sesuai key; false jika ctx dibatalkan saat antrean penuh
+func (l *lane) dispatch(ctx, handlerCtx context.Context, m kafka.Message) bool {
+	key := partitionKey{m.Topic, m.Partition}
+	pool, ok := l.pools[key]
+	if !ok {
+		pool = &partitionPool{queues: make([]chan kafka.Message, l.c.cfg.WorkersPerPartition)}
+		for i := range pool.queues {
+			pool.queues[i] = make(chan kafka.Message, l.c.cfg.QueueSize)
+			l.c.wg.Add(1)
+			go l.work(handlerCtx, pool.queues[i])
+		}
+		l.pools[key] = pool
+	}
+
+	var idx int
+	if len(m.Key) == 0 {
+		idx = pool.next % len(pool.queues)
+		pool.next++
+	} else {
+		h := fnv.New32a()
+		h.Write(m.Key)
+		idx = int(h.Sum32() % uint32(len(pool.queues)))
+	}
+
+	select {
+	case pool.queues[idx] <- m:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (l *lane) work(ctx context.Context, queue <-chan kafka.Message) {
+	defer l.c.wg.Done()
+	for m := range queue {
+		// Pesan yang belum mulai saat shutdown dilewati; akan dikirim ulang setelah restart
+		if l.c.stopping.Load() {
+			continue
+		}
+		if l.process(ctx, m) {
+			l.offsets.Done(m)
+		}
+	}
+}
+
+// process menjalankan handler untuk satu pesan. true berarti pesan selesai
+// (sukses, tidak punya handler, atau sudah dipindah ke retry/DLQ); false
+// berarti berhenti karena shutdown sehingga offset-nya tidak di-commit.
+func (l *lane) process(ctx context.Context, m kafka.Message) bool {
+	// Pesan retry baru boleh diproses setelah jedanya lewat
+	if l.delay > 0 {
+		if wait := time.Until(retryAt(m)); wait > 0 {
+			select {
+			case <-l.c.stopped:
+				return false
+			case <-ctx.Done():
+				return false
+			case <-time.After(wait):
+			}
+		}
+	}
+
+	event, err := DecodeEvent(ctx, m)
+	if errors.Is(err, ErrInvalidEvent) {
+		// Pesan yang bukan CloudEvent tidak akan pernah bisa diproses; langsung ke DLQ
+		log.Printf("⚠️ Pesan %s/%d@%d tidak valid, dipindah ke DLQ: %v", m.Topic, m.Partition, m.Offset, err)
+		return l.c.forward(ctx, m, DeadLetterTopic(originalTopic(m)), err, 0)
+	}
+	// Gagal decode sementara (mis. schema registry tidak bisa dihubungi) diperlakukan seperti handler gagal
+	if err == nil {
+		handlers := l.c.registry.lookup(event.Name())
+		if len(handlers) == 0 {
+			l.c.stats.unhandled.Add(1)
+			return true
+		}
+
+		err = l.c.handle(ctx, handlers, Message{Event: event, Raw: m})
+		if err == nil {
+			l.c.stats.processed.Add(1)
+			return true
+		}
+		if errors.Is(err, errDuplicate) {
+			l.c.stats.duplicates.Add(1)
+			log.Printf("♻️ Event %s (%s) sudah diproses, dilewati", event.ID, event.Name())
+			return true
+		}
+	}
+
+	// Tingkat retry berikutnya, atau DLQ jika semua tingkat sudah dicoba
+	target, delay := DeadLetterTopic(originalTopic(m)), time.Duration(0)
+	if l.tier < len(l.c.cfg.RetryDelays) {
+		delay = l.c.cfg.RetryDelays[l.tier]
+		target = RetryTopic(originalTopic(m), delay)
+	}
+	log.Printf("⚠️ Handler %s gagal (percobaan %d, offset %s/%d@%d), dipindah ke %s: %v",
+		event.Name(), attempts(m)+1, m.Topic, m.Partition, m.Offset, target, err)
+	return l.c.forward(ctx, m, target, err, delay)
+}
+
+// handle menjalankan handler dengan dedup. Dengan Transactor, tanda dedup
+// ditulis lebih dulu di transaksi yang sama dengan handler; consumer lain yang
+// memproses event yang sama menunggu lock barisnya lalu rollback dengan
+// errDuplicate. Tanpa Transactor, tanda ditulis setelah handler sukses.
+func (c *Consumer) handle(ctx context.Context, handlers []Handler, msg Message) error {
+	dedup, group := c.cfg.Dedup, c.cfg.GroupID
+	if dedup == nil {
+		return runHandlers(ctx, handlers, msg)
+	}
+
+	seen, err := dedup.Seen(ctx, group, msg.Event.ID)
+	if err != nil {
+		return fmt.Errorf("gagal cek dedup: %w", err)
+	}
+	if seen {
+		return errDuplicate
+	}
+
+	if c.cfg.Transactor != nil {
+		return c.cfg.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			fresh, err := dedup.Mark(ctx, group, msg.Event.ID, msg.Event.Type)
+			if err != nil {
+				return fmt.Errorf("gagal menandai event: %w", err)
+			}
+			if !fresh {
+				return errDuplicate
+			}
+			return runHandlers(ctx, handlers, msg)
+		})
+	}
+
+	if err := runHandlers(ctx, handlers, msg); err != nil {
+		return err
+	}
+	// Handler sudah sukses; gagal menandai hanya berarti event mungkin diproses ulang
+	if _, err := dedup.Mark(ctx, group, msg.Event.ID, msg.Event.Type); err != nil {
+		log.Printf("⚠️ Gagal menandai event %s sebagai sudah diproses: %v", msg.Event.ID, err)
+	}
+	return nil
+}
+
+// Stats mengembalikan penghitung Consumer saat ini
+func (c *Consumer) Stats() ConsumerStats {
+	return c.stats.snapshot()
+}
+func runHandlers(ctx context.Context, handlers []Handler, msg Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	for _, h := range handlers {
+		if err := h(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forward menerbitkan pesan gagal ke topic retry/DLQ. Penulisan diulang dengan
+// backoff sampai berhasil, karena offset pesan asal baru boleh di-commit setelahnya.
+func (c *Consumer) forward(ctx context.Context, m kafka.Message, target string, cause error, delay time.Duration) bool {
+	msg := FailedMessage(m, target, cause, delay, time.Now())
+	backoff := c.cfg.MinBackoff
+	for {
+		err := c.writer.WriteMessages(ctx, msg)
+		if err == nil {
+			if delay > 0 {
+				c.stats.retried.Add(1)
+			} else {
+				c.stats.deadLettered.Add(1)
+			}
+			return true
+		}
+		log.Printf("❌ Gagal menulis pesan ke %s: %v", target, err)
+
+		select {
+		case <-c.stopped:
+			return false
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, c.cfg.MaxBackoff)
+	}
+}
+
+// shutdown menghentikan worker: antrean ditutup, pesan yang belum mulai
+// dilewati, dan handler yang sedang jalan ditunggu paling lama ShutdownTimeout
+func (c *Consumer) shutdown(cancelHandlers context.CancelFunc) {
+	c.stopping.Store(true)
+	close(c.stopped)
+	for _, l := range c.lanes {
+		for _, pool := range l.pools {
+			for _, q := range pool.queues {
+				close(q)
+			}
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(c.cfg.ShutdownTimeout):
+		log.Println("⚠️ Handler tidak selesai sebelum batas waktu shutdown, dibatalkan")
+		cancelHandlers()
+		<-done
+	}
+}
+
+func (c *Consumer) commitLoop(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(c.cfg.CommitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, l := range c.lanes {
+				l.commit()
+			}
+		}
+	}
+}
+
+// commit menyimpan offset yang sudah aman per partisi
+func (l *lane) commit() {
+	msgs := l.offsets.Ready()
+	if len(msgs) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := l.reader.CommitMessages(ctx, msgs...); err != nil {
+		log.Printf("⚠️ Gagal commit offset Kafka (%s): %v", l.groupID, err)
+	}
+}
+
+// purgeLoop menghapus tanda dedup yang lebih tua dari DedupRetention setiap jam
+func (c *Consumer) purgeLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := c.cfg.Dedup.Purge(ctx, time.Now().Add(-c.cfg.DedupRetention))
+			if err != nil {
+				log.Printf("⚠️ Gagal membersihkan tanda dedup: %v", err)
+			} else if deleted > 0 {
+				log.Printf("🧹 %d tanda dedup dihapus", deleted)
+			}
+		}
+	}
+}
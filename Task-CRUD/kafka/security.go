@@ -0,0 +1,71 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// Security mengatur autentikasi SASL dan TLS ke broker. Nilai nol berarti
+// koneksi plaintext tanpa autentikasi.
+type Security struct {
+	Mechanism string // "" | plain | scram-sha-256 | scram-sha-512
+	Username  string
+	Password  string
+	TLS       *tls.Config
+}
+
+func (s Security) mechanism() (sasl.Mechanism, error) {
+	switch s.Mechanism {
+	case "":
+		return nil, nil
+	case "plain":
+		return plain.Mechanism{Username: s.Username, Password: s.Password}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, s.Username, s.Password)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, s.Username, s.Password)
+	}
+	return nil, fmt.Errorf("mekanisme SASL tidak dikenal: %s", s.Mechanism)
+}
+
+// Dialer membuat dialer untuk reader dan koneksi admin
+func (s Security) Dialer() (*kafka.Dialer, error) {
+	mechanism, err := s.mechanism()
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Dialer{
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		SASLMechanism: mechanism,
+		TLS:           s.TLS,
+	}, nil
+}
+
+// Transport membuat transport untuk Writer dan Client
+func (s Security) Transport() (*kafka.Transport, error) {
+	mechanism, err := s.mechanism()
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Transport{
+		DialTimeout: 10 * time.Second,
+		SASL:        mechanism,
+		TLS:         s.TLS,
+	}, nil
+}
+
+// Client membuat client admin (metadata, pembuatan topic) untuk broker
+func (s Security) Client(brokers []string) (*kafka.Client, error) {
+	transport, err := s.Transport()
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Client{Addr: kafka.TCP(brokers...), Transport: transport}, nil
+}
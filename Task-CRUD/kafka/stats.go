@@ -0,0 +1,32 @@
+package kafka
+
+import (
+	"sync/atomic"
+)
+
+// ConsumerStats adalah penghitung kumulatif Consumer sejak dijalankan
+type ConsumerStats struct {
+	Processed    int64 `json:"processed"`     // handler sukses
+	Duplicates   int64 `json:"duplicates"`    // event yang sudah pernah diproses, dilewati
+	Retried      int64 `json:"retried"`       // dipindah ke topic retry
+	DeadLettered int64 `json:"dead_lettered"` // dipindah ke DLQ
+	Unhandled    int64 `json:"unhandled"`     // tidak ada handler untuk event ini
+}
+
+type consumerStats struct {
+	processed    atomic.Int64
+	duplicates   atomic.Int64
+	retried      atomic.Int64
+	deadLettered atomic.Int64
+	unhandled    atomic.Int64
+}
+
+func (s *consumerStats) snapshot() ConsumerStats {
+	return ConsumerStats{
+		Processed:    s.processed.Load(),
+		Duplicates:   s.duplicates.Load(),
+		Retried:      s.retried.Load(),
+		DeadLettered: s.deadLettered.Load(),
+		Unhandled:    s.unhandled.Load(),
+	}
+}
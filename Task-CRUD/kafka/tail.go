@@ -0,0 +1,210 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// TailConfig mengatur Tail
+type TailConfig struct {
+	Brokers         []string
+	Topics          []string
+	Dialer          *kafka.Dialer
+	RefreshInterval time.Duration // pengecekan partisi/topic baru (default 1m)
+}
+
+// TailSink menerima pesan dari Tail. Assign dipanggil sekali per partisi
+// sebelum pesan pertamanya, dengan offset pesan pertama yang akan dikirim.
+// Publish dipanggil dari satu goroutine per partisi.
+type TailSink interface {
+	Assign(topic string, partition int, next int64)
+	Publish(m kafka.Message)
+}
+
+// Tail membaca semua partisi topic tanpa consumer group, mulai dari pesan
+// terbaru. Setiap replica menerima semua pesan, dipakai untuk fan-out ke klien
+// stream (SSE/WebSocket). Partisi yang muncul setelah Tail berjalan dibaca dari awal.
+type Tail struct {
+	cfg TailConfig
+}
+
+func NewTail(cfg TailConfig) *Tail {
+	if cfg.Dialer == nil {
+		cfg.Dialer = &kafka.Dialer{Timeout: 10 * time.Second}
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = time.Minute
+	}
+	return &Tail{cfg: cfg}
+}
+
+// Run membaca sampai ctx dibatalkan. Selama Kafka tidak tersedia, pencarian
+// partisi dicoba lagi setiap beberapa detik.
+func (t *Tail) Run(ctx context.Context, sink TailSink) error {
+	if len(t.cfg.Brokers) == 0 {
+		return errors.New("broker Kafka belum dikonfigurasi")
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	running := make(map[partitionKey]bool)
+	initial := true
+	for {
+		wait := t.cfg.RefreshInterval
+		partitions, err := t.partitions(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Printf("⚠️ Stream Kafka: gagal membaca partisi: %v", err)
+			wait = 5 * time.Second
+		}
+		for _, p := range partitions {
+			key := partitionKey{p.Topic, p.ID}
+			if running[key] {
+				continue
+			}
+			next, err := t.startOffset(ctx, p.Topic, p.ID, initial)
+			if err != nil {
+				log.Printf("⚠️ Stream Kafka: gagal membaca offset %s/%d: %v", p.Topic, p.ID, err)
+				wait = 5 * time.Second
+				continue
+			}
+			running[key] = true
+			sink.Assign(p.Topic, p.ID, next)
+
+			wg.Add(1)
+			go func(topic string, partition int) {
+				defer wg.Done()
+				t.read(ctx, topic, partition, next, sink)
+			}(p.Topic, p.ID)
+		}
+		if err == nil {
+			initial = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// ReadRange membaca pesan satu partisi dengan offset from sampai to (inklusif)
+// secara berurutan. Offset yang sudah terhapus oleh retensi dilewati.
+func (t *Tail) ReadRange(ctx context.Context, topic string, partition int, from, to int64, fn func(kafka.Message) error) error {
+	leader, err := t.cfg.Dialer.DialLeader(ctx, "tcp", t.cfg.Brokers[0], topic, partition)
+	if err != nil {
+		return fmt.Errorf("gagal terhubung ke leader %s/%d: %w", topic, partition, err)
+	}
+	first, err := leader.ReadFirstOffset()
+	leader.Close()
+	if err != nil {
+		return fmt.Errorf("gagal membaca offset %s/%d: %w", topic, partition, err)
+	}
+	from = max(from, first)
+	if from > to {
+		return nil
+	}
+
+	reader := t.reader(topic, partition)
+	defer reader.Close()
+	if err := reader.SetOffset(from); err != nil {
+		return err
+	}
+	for {
+		m, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("gagal membaca %s/%d: %w", topic, partition, err)
+		}
+		if m.Offset > to {
+			return nil
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+		if m.Offset == to {
+			return nil
+		}
+	}
+}
+
+func (t *Tail) partitions(ctx context.Context) ([]kafka.Partition, error) {
+	conn, err := t.cfg.Dialer.DialContext(ctx, "tcp", t.cfg.Brokers[0])
+	if err != nil {
+		return nil, fmt.Errorf("gagal terhubung ke Kafka: %w", err)
+	}
+	defer conn.Close()
+
+	// Dibaca per topic supaya topic yang belum dibuat tidak menggagalkan yang lain
+	var out []kafka.Partition
+	for _, topic := range t.cfg.Topics {
+		partitions, err := conn.ReadPartitions(topic)
+		if errors.Is(err, kafka.UnknownTopicOrPartition) {
+			continue
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, partitions...)
+	}
+	return out, nil
+}
+
+// startOffset: partisi yang ada saat Tail mulai dibaca dari akhir, partisi baru dari awal
+func (t *Tail) startOffset(ctx context.Context, topic string, partition int, latest bool) (int64, error) {
+	leader, err := t.cfg.Dialer.DialLeader(ctx, "tcp", t.cfg.Brokers[0], topic, partition)
+	if err != nil {
+		return 0, err
+	}
+	defer leader.Close()
+	if latest {
+		return leader.ReadLastOffset()
+	}
+	return leader.ReadFirstOffset()
+}
+
+func (t *Tail) read(ctx context.Context, topic string, partition int, next int64, sink TailSink) {
+	reader := t.reader(topic, partition)
+	defer reader.Close()
+	if err := reader.SetOffset(next); err != nil {
+		log.Printf("⚠️ Stream Kafka: gagal mengatur offset %s/%d: %v", topic, partition, err)
+		return
+	}
+	for {
+		m, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("⚠️ Stream Kafka: gagal membaca %s/%d: %v", topic, partition, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		sink.Publish(m)
+	}
+}
+
+func (t *Tail) reader(topic string, partition int) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   t.cfg.Brokers,
+		Topic:     topic,
+		Partition: partition,
+		Dialer:    t.cfg.Dialer,
+		MinBytes:  1,
+		MaxBytes:  10e6,
+		MaxWait:   500 * time.Millisecond,
+	})
+}
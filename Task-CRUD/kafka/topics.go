@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// TopicSpec adalah topic yang harus ada beserta pengaturannya
+type TopicSpec struct {
+	Name              string
+	Partitions        int
+	ReplicationFactor int           // <= 0 memakai default broker
+	Retention         time.Duration // 0 memakai default broker
+}
+
+// EnsureTopics membuat topic yang belum ada sesuai spec.
+// Topic yang sudah ada tidak diubah: jumlah partisi yang berbeda hanya dicatat
+// di log, karena menambah partisi menggeser pemetaan key -> partisi.
+func EnsureTopics(ctx context.Context, client *kafka.Client, specs ...TopicSpec) error {
+	if len(specs) == 0 {
+		return nil
+	}
+	names := make([]string, len(specs))
+	for i, s := range specs {
+		names[i] = s.Name
+	}
+
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: names})
+	if err != nil {
+		return fmt.Errorf("gagal membaca metadata topic: %w", err)
+	}
+	existing := make(map[string]int)
+	for _, t := range meta.Topics {
+		if t.Error == nil {
+			existing[t.Name] = len(t.Partitions)
+		}
+	}
+
+	var missing []kafka.TopicConfig
+	for _, s := range specs {
+		partitions, ok := existing[s.Name]
+		if !ok {
+			missing = append(missing, topicConfig(s))
+			continue
+		}
+		if partitions != s.Partitions {
+			log.Printf("⚠️ Topic %s punya %d partisi, konfigurasi meminta %d (tidak diubah otomatis)", s.Name, partitions, s.Partitions)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	resp, err := client.CreateTopics(ctx, &kafka.CreateTopicsRequest{Topics: missing})
+	if err != nil {
+		return fmt.Errorf("gagal membuat topic: %w", err)
+	}
+	var errList error
+	for _, t := range missing {
+		err := resp.Errors[t.Topic]
+		switch {
+		case err == nil:
+			log.Printf("🆕 Topic %s dibuat dengan %d partisi (replikasi %d)", t.Topic, t.NumPartitions, t.ReplicationFactor)
+		case errors.Is(err, kafka.TopicAlreadyExists):
+			// Dibuat replica lain di antara Metadata dan CreateTopics
+		default:
+			errList = errors.Join(errList, fmt.Errorf("topic %s: %w", t.Topic, err))
+		}
+	}
+	return errList
+}
+
+func topicConfig(s TopicSpec) kafka.TopicConfig {
+	t := kafka.TopicConfig{
+		Topic:             s.Name,
+		NumPartitions:     s.Partitions,
+		ReplicationFactor: s.ReplicationFactor,
+	}
+	if t.ReplicationFactor <= 0 {
+		t.ReplicationFactor = -1 // default.replication.factor broker
+	}
+	if s.Retention > 0 {
+		t.ConfigEntries = append(t.ConfigEntries, kafka.ConfigEntry{
+			ConfigName:  "retention.ms",
+			ConfigValue: strconv.FormatInt(s.Retention.Milliseconds(), 10),
+		})
+	}
+	return t
+}
@@ -0,0 +1,86 @@
+package kafka
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/compress"
+)
+
+// WriterConfig mengatur Writer. Nilai nol memakai default kafka-go, kecuali
+// BatchTimeout: default kafka-go 1 detik membuat setiap WriteMessages sinkron
+// menunggu selama itu, jadi di sini default-nya 10ms.
+type WriterConfig struct {
+	BatchSize    int
+	BatchTimeout time.Duration
+	RequiredAcks string // all | one | none (default all)
+	Compression  string // none | gzip | snappy | lz4 | zstd
+	MaxAttempts  int
+	// Async membuat WriteMessages langsung kembali; hasil pengiriman dilaporkan
+	// ke Completion. Jangan dipakai untuk penulis yang perlu tahu pesan sudah
+	// tersimpan (mis. outbox relay).
+	Async      bool
+	Completion func(messages []kafka.Message, err error)
+}
+
+// NewWriter membuat Writer untuk banyak topic (topic diisi per pesan) dengan
+// balancer Hash, sehingga pesan dengan key sama selalu ke partisi yang sama
+func NewWriter(brokers []string, cfg WriterConfig, sec Security) (*kafka.Writer, error) {
+	transport, err := sec.Transport()
+	if err != nil {
+		return nil, err
+	}
+	acks, err := requiredAcks(cfg.RequiredAcks)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := compression(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BatchTimeout <= 0 {
+		cfg.BatchTimeout = 10 * time.Millisecond
+	}
+
+	return &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{},
+		Transport:    transport,
+		BatchSize:    cfg.BatchSize,
+		BatchTimeout: cfg.BatchTimeout,
+		RequiredAcks: acks,
+		Compression:  codec,
+		MaxAttempts:  cfg.MaxAttempts,
+		Async:        cfg.Async,
+		Completion:   cfg.Completion,
+	}, nil
+}
+
+func requiredAcks(value string) (kafka.RequiredAcks, error) {
+	switch value {
+	case "", "all":
+		return kafka.RequireAll, nil
+	case "one":
+		return kafka.RequireOne, nil
+	case "none":
+		return kafka.RequireNone, nil
+	}
+	return 0, fmt.Errorf("required acks tidak dikenal: %s (pilihan: all|one|none)", value)
+}
+
+func compression(value string) (kafka.Compression, error) {
+	switch value {
+	case "", "none":
+		return 0, nil
+	case "gzip":
+		return compress.Gzip, nil
+	case "snappy":
+		return compress.Snappy, nil
+	case "lz4":
+		return compress.Lz4, nil
+	case "zstd":
+		return compress.Zstd, nil
+	}
+	return 0, fmt.Errorf("kompresi tidak dikenal: %s (pilihan: none|gzip|snappy|lz4|zstd)", value)
+}
@@ -0,0 +1,167 @@
+package test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Task-CRUD/internal/entity"
+	"Task-CRUD/internal/events"
+	"Task-CRUD/internal/schemaregistry"
+	appKafka "Task-CRUD/kafka"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// newTestRegistry menjalankan stub registry di httptest dan mengembalikan klien REST-nya
+func newTestRegistry(t *testing.T) (*schemaregistry.Memory, *httptest.Server) {
+	t.Helper()
+	memory := schemaregistry.NewMemory()
+	server := httptest.NewServer(memory)
+	t.Cleanup(server.Close)
+	return memory, server
+}
+
+func newTestRepositoryEvent(t *testing.T) events.Event {
+	t.Helper()
+	// Avro menyimpan waktu dalam mikrodetik
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	before := entity.Repository{ID: 7, Name: "crud", UserID: 3, URL: "https://example.com/crud", CreatedAt: now, UpdatedAt: now,
+		User: entity.User{ID: 3, Name: "Budi", Email: "budi@example.com", CreatedAt: now, UpdatedAt: now}}
+	after := before
+	after.AIEnabled = true
+	after.Description = "diperbarui"
+
+	ctx := events.WithCorrelationID(context.Background(), "corr-1")
+	event, err := events.New(ctx, events.RepositoryUpdated, "repository/7", before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event.Time = event.Time.Truncate(time.Microsecond)
+	return event
+}
+
+func messageOf(topic string, value []byte, contentType string) kafka.Message {
+	return kafka.Message{Topic: topic, Value: value, Headers: appKafka.EventHeaders("", contentType)}
+}
+
+func assertSameEvent(t *testing.T, got, want events.Event) {
+	t.Helper()
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Fatalf("event berbeda setelah decode:\n got %s\nwant %s", gotJSON, wantJSON)
+	}
+}
+
+func TestAvroEncodingRoundTrip(t *testing.T) {
+	memory, server := newTestRegistry(t)
+	registry := schemaregistry.NewClient(schemaregistry.ClientConfig{URL: server.URL})
+	codec := appKafka.NewCodec(appKafka.EncodingJSON, map[string]string{"repository_updated": appKafka.EncodingAvro}, registry)
+	event := newTestRepositoryEvent(t)
+
+	value, contentType, err := codec.Encode(context.Background(), "repository_updated", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != appKafka.ContentTypeAvro {
+		t.Fatalf("content type = %s, want %s", contentType, appKafka.ContentTypeAvro)
+	}
+	ids := memory.Subjects("repository_updated-value")
+	if len(ids) != 1 {
+		t.Fatalf("subject repository_updated-value punya %d versi, want 1", len(ids))
+	}
+	// Wire format Confluent: magic byte 0 + schema ID big-endian
+	if value[0] != 0 || int(binary.BigEndian.Uint32(value[1:5])) != ids[0] {
+		t.Fatalf("wire format salah: % x", value[:5])
+	}
+
+	decoded, err := codec.Decode(context.Background(), messageOf("repository_updated", value, contentType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameEvent(t, decoded, event)
+
+	// Tanpa header content-type format dikenali dari magic byte
+	decoded, err = codec.Decode(context.Background(), kafka.Message{Value: value})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameEvent(t, decoded, event)
+}
+
+func TestEncodingSelectedPerTopic(t *testing.T) {
+	_, server := newTestRegistry(t)
+	registry := schemaregistry.NewClient(schemaregistry.ClientConfig{URL: server.URL})
+	codec := appKafka.NewCodec(appKafka.EncodingAvro, map[string]string{"user-topic": appKafka.EncodingJSON}, registry)
+	event := newTestRepositoryEvent(t)
+
+	value, contentType, err := codec.Encode(context.Background(), "user-topic", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != events.ContentType || value[0] != '{' {
+		t.Fatalf("topic JSON menghasilkan %s: %q", contentType, value)
+	}
+	decoded, err := codec.Decode(context.Background(), messageOf("user-topic", value, contentType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameEvent(t, decoded, event)
+
+	if _, contentType, err = codec.Encode(context.Background(), "repository-topic", event); err != nil || contentType != appKafka.ContentTypeAvro {
+		t.Fatalf("topic default harus Avro, dapat %s (err %v)", contentType, err)
+	}
+}
+
+func TestSchemaRegistryLookupsAreCached(t *testing.T) {
+	memory, server := newTestRegistry(t)
+	producer := appKafka.NewCodec(appKafka.EncodingAvro, nil, schemaregistry.NewClient(schemaregistry.ClientConfig{URL: server.URL}))
+	consumer := appKafka.NewCodec(appKafka.EncodingJSON, nil, schemaregistry.NewClient(schemaregistry.ClientConfig{URL: server.URL}))
+	event := newTestRepositoryEvent(t)
+
+	for i := 0; i < 5; i++ {
+		value, contentType, err := producer.Encode(context.Background(), "repository-topic", event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Consumer dengan encoding JSON tetap bisa membaca pesan Avro
+		if _, err := consumer.Decode(context.Background(), messageOf("repository-topic", value, contentType)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Satu register oleh producer dan satu lookup ID oleh consumer
+	if n := memory.Requests(); n != 2 {
+		t.Fatalf("registry menerima %d request, want 2", n)
+	}
+}
+
+func TestAvroDecodeErrors(t *testing.T) {
+	_, server := newTestRegistry(t)
+	codec := appKafka.NewCodec(appKafka.EncodingAvro, nil, schemaregistry.NewClient(schemaregistry.ClientConfig{URL: server.URL}))
+
+	// Schema ID yang tidak ada di registry tidak akan pernah bisa di-decode
+	unknown := []byte{0, 0, 0, 0, 42, 1, 2, 3}
+	if _, err := codec.Decode(context.Background(), messageOf("t", unknown, appKafka.ContentTypeAvro)); !errors.Is(err, appKafka.ErrInvalidEvent) {
+		t.Fatalf("schema tidak dikenal: err = %v, want ErrInvalidEvent", err)
+	}
+	if _, err := codec.Decode(context.Background(), messageOf("t", []byte("bukan event"), events.ContentType)); !errors.Is(err, appKafka.ErrInvalidEvent) {
+		t.Fatalf("JSON rusak: err = %v, want ErrInvalidEvent", err)
+	}
+
+	// Registry yang tidak bisa dihubungi adalah kegagalan sementara
+	value, contentType, err := codec.Encode(context.Background(), "repository-topic", newTestRepositoryEvent(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	offline := appKafka.NewCodec(appKafka.EncodingAvro, nil, schemaregistry.NewClient(schemaregistry.ClientConfig{URL: server.URL, Timeout: time.Second}))
+	_, err = offline.Decode(context.Background(), messageOf("repository-topic", value, contentType))
+	if err == nil || errors.Is(err, appKafka.ErrInvalidEvent) {
+		t.Fatalf("registry mati: err = %v, want error sementara", err)
+	}
+}
@@ -0,0 +1,342 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"Task-CRUD/internal/cbreaker"
+	"Task-CRUD/internal/entity"
+	"Task-CRUD/internal/events"
+	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/usecase"
+	appKafka "Task-CRUD/kafka"
+
+	"github.com/sony/gobreaker"
+)
+
+// Semua implementasi harus memenuhi kontrak yang sama
+var (
+	_ interfaces.EventPublisher = (*events.MemoryPublisher)(nil)
+	_ interfaces.EventPublisher = events.NopPublisher{}
+	_ interfaces.EventPublisher = (*appKafka.Publisher)(nil)
+)
+
+// memoryRepoRepository adalah repository in-memory untuk test usecase tanpa database
+type memoryRepoRepository struct {
+	mu     sync.Mutex
+	nextID uint
+	repos  map[uint]entity.Repository
+}
+
+func newMemoryRepoRepository() *memoryRepoRepository {
+	return &memoryRepoRepository{repos: make(map[uint]entity.Repository)}
+}
+
+func (r *memoryRepoRepository) GetAllRepositories(ctx context.Context) ([]entity.Repository, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []entity.Repository
+	for id := uint(1); id <= r.nextID; id++ {
+		if repo, ok := r.repos[id]; ok {
+			out = append(out, repo)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryRepoRepository) GetRepositoryByID(ctx context.Context, id uint) (*entity.Repository, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	repo, ok := r.repos[id]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+	return &repo, nil
+}
+
+func (r *memoryRepoRepository) CreateRepository(ctx context.Context, repo *entity.Repository) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	repo.ID = r.nextID
+	r.repos[repo.ID] = *repo
+	return nil
+}
+
+func (r *memoryRepoRepository) UpdateRepository(ctx context.Context, id uint, updated *entity.Repository) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.repos[id]; !ok {
+		return entity.ErrNotFound
+	}
+	updated.ID = id
+	r.repos[id] = *updated
+	return nil
+}
+
+func (r *memoryRepoRepository) DeleteRepository(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.repos[id]; !ok {
+		return entity.ErrNotFound
+	}
+	delete(r.repos, id)
+	return nil
+}
+
+// memoryUserRepository adalah repository user in-memory
+type memoryUserRepository struct {
+	mu     sync.Mutex
+	nextID uint
+	users  map[uint]entity.User
+}
+
+func newMemoryUserRepository() *memoryUserRepository {
+	return &memoryUserRepository{users: make(map[uint]entity.User)}
+}
+
+func (r *memoryUserRepository) CreateUser(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	user.ID = r.nextID
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) GetUserByID(ctx context.Context, id uint) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *memoryUserRepository) GetAllUsers(ctx context.Context) ([]entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []entity.User
+	for id := uint(1); id <= r.nextID; id++ {
+		if user, ok := r.users[id]; ok {
+			out = append(out, user)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryUserRepository) UpdateUser(ctx context.Context, id uint, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[id]; !ok {
+		return entity.ErrNotFound
+	}
+	user.ID = id
+	r.users[id] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) DeleteUser(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[id]; !ok {
+		return entity.ErrNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func initTestBreaker() {
+	if cbreaker.Breaker == nil {
+		cbreaker.Breaker = cbreaker.NewDefaultBreaker("TestBreaker")
+	}
+}
+
+func TestRepoUseCasePublishesCRUDEvents(t *testing.T) {
+	initTestBreaker()
+	ctx := context.Background()
+	publisher := events.NewMemoryPublisher()
+	uc := usecase.NewRepoUseCaseWithEvents(newMemoryRepoRepository(), nil, publisher)
+
+	repo := &entity.Repository{Name: "task-crud", URL: "https://example.com/task-crud", UserID: 3}
+	if err := uc.CreateRepo(ctx, repo); err != nil {
+		t.Fatal(err)
+	}
+	updated := &entity.Repository{Name: "task-crud-v2", URL: repo.URL, UserID: 3}
+	if err := uc.UpdateRepo(ctx, repo.ID, updated); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.DeleteRepo(ctx, repo.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{events.RepositoryCreated, events.RepositoryUpdated, events.RepositoryDeleted}
+	if got := publisher.Names(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("event = %v, want %v", got, want)
+	}
+	for _, p := range publisher.Published() {
+		if p.Event.Subject != "repository/1" || p.Options.Key != "1" {
+			t.Errorf("%s: subject %q key %q, want repository/1 dan 1", p.Event.Name(), p.Event.Subject, p.Options.Key)
+		}
+		if p.Options.Async {
+			t.Errorf("%s: event CRUD harus dikirim sinkron", p.Event.Name())
+		}
+	}
+
+	var data events.Change
+	if err := json.Unmarshal(publisher.Published()[1].Event.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(data.Changed, []string{"name"}) {
+		t.Errorf("changed = %v, want [name]", data.Changed)
+	}
+}
+
+func TestRepoUseCaseSkipsEventsOnFailure(t *testing.T) {
+	initTestBreaker()
+	publisher := events.NewMemoryPublisher()
+	uc := usecase.NewRepoUseCaseWithEvents(newMemoryRepoRepository(), nil, publisher)
+
+	// Validasi gagal dan repository yang tidak ada tidak boleh menghasilkan event
+	if err := uc.CreateRepo(context.Background(), &entity.Repository{Name: "tanpa-url", UserID: 1}); err == nil {
+		t.Fatal("repository tanpa URL harus ditolak")
+	}
+	if err := uc.DeleteRepo(context.Background(), 99); err == nil {
+		t.Fatal("hapus repository yang tidak ada harus gagal")
+	}
+	if got := publisher.Names(); len(got) != 0 {
+		t.Errorf("event = %v, want kosong", got)
+	}
+}
+
+func TestRepoUseCaseNotFoundKeepsBreakerClosed(t *testing.T) {
+	initTestBreaker()
+	uc := usecase.NewRepoUseCaseWithEvents(newMemoryRepoRepository(), nil, events.NewMemoryPublisher())
+
+	// Request ke ID yang tidak ada adalah 404, bukan kegagalan database
+	for i := 0; i < 10; i++ {
+		if err := uc.UpdateRepo(context.Background(), 99, &entity.Repository{Name: "repo", URL: "https://example.com/x", UserID: 1}); !errors.Is(err, entity.ErrNotFound) {
+			t.Fatalf("update: err = %v, want ErrNotFound", err)
+		}
+		if err := uc.DeleteRepo(context.Background(), 99); !errors.Is(err, entity.ErrNotFound) {
+			t.Fatalf("delete: err = %v, want ErrNotFound", err)
+		}
+	}
+	if state := cbreaker.Breaker.State(); state != gobreaker.StateClosed {
+		t.Fatalf("breaker = %v, want closed", state)
+	}
+}
+
+func TestRepoUseCasePublishErrors(t *testing.T) {
+	initTestBreaker()
+	ctx := context.Background()
+	publisher := events.NewMemoryPublisher()
+	uc := usecase.NewRepoUseCaseWithEvents(newMemoryRepoRepository(), nil, publisher)
+
+	// Broker tidak tersedia: CRUD tetap berhasil, event dilewati
+	publisher.FailWith(events.ErrPublisherUnavailable)
+	if err := uc.CreateRepo(ctx, &entity.Repository{Name: "repo", URL: "https://example.com/a", UserID: 1}); err != nil {
+		t.Fatalf("CRUD harus tetap jalan saat publisher tidak tersedia: %v", err)
+	}
+
+	// Error broker lain setelah commit: data tetap tersimpan, kegagalan dicatat di log
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	publisher.FailWith(errors.New("broker menolak pesan"))
+	repo := &entity.Repository{Name: "repo", URL: "https://example.com/b", UserID: 1}
+	if err := uc.CreateRepo(ctx, repo); err != nil {
+		t.Fatalf("CRUD yang sudah commit tidak boleh gagal karena broker: %v", err)
+	}
+	if got, err := uc.GetRepositoryByID(ctx, repo.ID); err != nil || got.URL != repo.URL {
+		t.Fatalf("repository = %+v, %v; want tersimpan", got, err)
+	}
+	if !strings.Contains(logs.String(), "broker menolak pesan") {
+		t.Errorf("log = %q, want kegagalan broker tercatat", logs.String())
+	}
+
+	// Replay membutuhkan publisher
+	publisher.FailWith(nil)
+	if n, err := uc.ReplayRepoEvents(ctx, "repository_created"); err != nil || n != 2 {
+		t.Fatalf("replay = %d, %v; want 2, nil", n, err)
+	}
+	noPublisher := usecase.NewRepoUseCaseWithEvents(newMemoryRepoRepository(), nil, nil)
+	if _, err := noPublisher.ReplayRepoEvents(ctx, "repository_created"); !errors.Is(err, events.ErrPublisherUnavailable) {
+		t.Fatalf("err = %v, want ErrPublisherUnavailable", err)
+	}
+}
+
+func TestUserUseCasePublishesCRUDEvents(t *testing.T) {
+	initTestBreaker()
+	ctx := context.Background()
+	publisher := events.NewMemoryPublisher()
+	uc := usecase.NewUserUseCaseWithEvents(newMemoryUserRepository(), nil, usecase.NewEventPipeline(publisher))
+
+	user := &entity.User{Name: "Budi", Email: "budi@example.com"}
+	if err := uc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.UpdateUser(ctx, user.ID, &entity.User{Name: "Budi S", Email: "budi@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{events.UserCreated, events.UserUpdated, events.UserDeleted}
+	if got := publisher.Names(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("event = %v, want %v", got, want)
+	}
+	for _, p := range publisher.Published() {
+		if p.Event.Subject != "user/1" || p.Options.Key != "1" {
+			t.Errorf("%s: subject %q key %q, want user/1 dan 1", p.Event.Name(), p.Event.Subject, p.Options.Key)
+		}
+	}
+}
+
+func TestNopPublisherDropsEvents(t *testing.T) {
+	initTestBreaker()
+	uc := usecase.NewUserUseCaseWithEvents(newMemoryUserRepository(), nil, usecase.NewEventPipeline(events.NopPublisher{}))
+	if err := uc.CreateUser(context.Background(), &entity.User{Name: "Sari", Email: "sari@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublishOptions(t *testing.T) {
+	e, err := events.New(context.Background(), events.RepositoryCreated, "repository/12", nil, &entity.Repository{ID: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := events.ApplyPublishOptions(e)
+	if o.Key != "12" || o.Async || o.Headers != nil {
+		t.Errorf("opsi default = %+v, want key 12, sinkron, tanpa header", o)
+	}
+
+	o = events.ApplyPublishOptions(e, events.WithKey("tenant-1"), events.WithHeader("x-source", "import"), events.Async())
+	if o.Key != "tenant-1" || !o.Async || o.Headers["x-source"] != "import" {
+		t.Errorf("opsi = %+v", o)
+	}
+	if o = events.ApplyPublishOptions(e, events.Async(), events.Sync()); o.Async {
+		t.Error("opsi terakhir harus menang")
+	}
+}
+
+func TestKafkaPublisherWithoutWriter(t *testing.T) {
+	e, err := events.New(context.Background(), events.UserDeleted, "user/1", &entity.User{ID: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := appKafka.NewPublisher(nil).Publish(context.Background(), e); !errors.Is(err, events.ErrPublisherUnavailable) {
+		t.Fatalf("err = %v, want ErrPublisherUnavailable", err)
+	}
+}
@@ -0,0 +1,184 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"Task-CRUD/internal/entity"
+	"Task-CRUD/internal/events"
+)
+
+// Snapshot schema yang sudah dirilis. Schema di internal/events/schemas boleh
+// berkembang, tapi harus tetap kompatibel dengan snapshot ini. Setelah perubahan
+// yang kompatibel, perbarui snapshot dengan UPDATE_EVENT_SCHEMAS=1 go test ./test/...
+const schemaSnapshotDir = "testdata/event_schemas"
+
+func TestEventSchemasStayCompatible(t *testing.T) {
+	update := os.Getenv("UPDATE_EVENT_SCHEMAS") == "1"
+
+	for _, s := range events.Schemas() {
+		file := filepath.Join(schemaSnapshotDir, s.Name+".v"+strconv.Itoa(s.Version)+".json")
+		snapshot, err := os.ReadFile(file)
+		if os.IsNotExist(err) || update {
+			if !update {
+				t.Errorf("%s v%d belum punya snapshot di %s (jalankan dengan UPDATE_EVENT_SCHEMAS=1)", s.Name, s.Version, file)
+				continue
+			}
+			if err := os.WriteFile(file, s.Body, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		problems, err := events.CheckCompatibility(snapshot, s.Body)
+		if err != nil {
+			t.Fatalf("%s v%d: %v", s.Name, s.Version, err)
+		}
+		for _, p := range problems {
+			t.Errorf("%s v%d tidak kompatibel: %s", s.Name, s.Version, p)
+		}
+
+		// Versi baru juga harus kompatibel dengan versi sebelumnya
+		if prev, ok := events.LookupVersion(s.Name, s.Version-1); ok {
+			problems, _ := events.CheckCompatibility(prev.Body, s.Body)
+			for _, p := range problems {
+				t.Errorf("%s v%d tidak kompatibel dengan v%d: %s", s.Name, s.Version, prev.Version, p)
+			}
+		}
+	}
+}
+
+func TestCheckCompatibilityDetectsBreakingChanges(t *testing.T) {
+	base := `{"type":"object","properties":{"id":{"type":"integer"},"name":{"type":"string"},"state":{"enum":["a","b"]}},"required":["id"]}`
+	cases := []struct {
+		name     string
+		schema   string
+		breaking bool
+	}{
+		{"sama", base, false},
+		{"tambah property opsional", `{"type":"object","properties":{"id":{"type":"integer"},"name":{"type":"string"},"state":{"enum":["a","b","c"]},"x":{"type":"string"}},"required":["id"]}`, false},
+		{"longgarkan tipe", `{"type":"object","properties":{"id":{"type":"number"},"name":{"type":["string","null"]},"state":{"enum":["a","b"]}},"required":["id"]}`, false},
+		{"hapus property", `{"type":"object","properties":{"id":{"type":"integer"},"state":{"enum":["a","b"]}},"required":["id"]}`, true},
+		{"ganti tipe", `{"type":"object","properties":{"id":{"type":"string"},"name":{"type":"string"},"state":{"enum":["a","b"]}},"required":["id"]}`, true},
+		{"required baru", `{"type":"object","properties":{"id":{"type":"integer"},"name":{"type":"string"},"state":{"enum":["a","b"]}},"required":["id","name"]}`, true},
+		{"hapus enum", `{"type":"object","properties":{"id":{"type":"integer"},"name":{"type":"string"},"state":{"enum":["a"]}},"required":["id"]}`, true},
+	}
+	for _, c := range cases {
+		problems, err := events.CheckCompatibility([]byte(base), []byte(c.schema))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if (len(problems) > 0) != c.breaking {
+			t.Errorf("%s: breaking=%v, problems=%v", c.name, c.breaking, problems)
+		}
+	}
+}
+
+// Setiap field JSON entity harus dideskripsikan schema, supaya perubahan entity
+// tidak diam-diam mengubah isi event tanpa memperbarui schema
+func TestEventSchemasCoverEntityFields(t *testing.T) {
+	repo := entity.Repository{ID: 1, Name: "repo", UserID: 2, URL: "https://example.com", User: entity.User{ID: 2, Name: "u", Email: "u@example.com"}}
+
+	names := []string{
+		events.RepositoryCreated, events.RepositoryUpdated, events.RepositoryDeleted,
+		events.UserCreated, events.UserUpdated, events.UserDeleted,
+	}
+	for _, name := range names {
+		schema, ok := events.Lookup(name)
+		if !ok {
+			t.Fatalf("schema %s tidak terdaftar", name)
+		}
+		var body struct {
+			Defs map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"$defs"`
+		}
+		if err := json.Unmarshal(schema.Body, &body); err != nil {
+			t.Fatal(err)
+		}
+		if def, ok := body.Defs["repository"]; ok {
+			assertCovered(t, name+" repository", def.Properties, repo)
+		}
+		assertCovered(t, name+" user", body.Defs["user"].Properties, repo.User)
+	}
+}
+
+func TestUpdatedEventListsChangedFields(t *testing.T) {
+	before := &entity.User{ID: 3, Name: "lama", Email: "a@example.com"}
+	after := &entity.User{ID: 3, Name: "baru", Email: "a@example.com"}
+
+	e, err := events.New(context.Background(), events.UserUpdated, "user/3", before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data events.Change
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Changed) != 1 || data.Changed[0] != "name" {
+		t.Errorf("changed = %v, want [name]", data.Changed)
+	}
+}
+
+func TestNewEventEnvelope(t *testing.T) {
+	ctx := events.WithCorrelationID(context.Background(), "req-1")
+	repo := &entity.Repository{ID: 7, Name: "repo"}
+
+	e, err := events.New(ctx, events.RepositoryDeleted, "repository/7", repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := json.Marshal(e)
+	decoded, err := events.Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.SpecVersion != "1.0" || decoded.Type != "com.taskcrud.repository.deleted" || decoded.Name() != events.RepositoryDeleted {
+		t.Errorf("atribut tidak sesuai: %+v", decoded)
+	}
+	if decoded.DataSchema != "/events/schemas/repository.deleted/v1" || decoded.CorrelationID != "req-1" || decoded.ID == "" {
+		t.Errorf("atribut tidak sesuai: %+v", decoded)
+	}
+	if time.Since(decoded.Time) > time.Minute {
+		t.Errorf("time tidak diisi: %v", decoded.Time)
+	}
+
+	var data struct {
+		Before *entity.Repository `json:"before"`
+		After  *entity.Repository `json:"after"`
+	}
+	if err := json.Unmarshal(decoded.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Before == nil || data.Before.ID != 7 || data.After != nil {
+		t.Errorf("data before/after salah: %s", decoded.Data)
+	}
+
+	if _, err := events.New(ctx, "tidak.ada", "", nil, nil); err == nil {
+		t.Error("tipe yang tidak terdaftar harus ditolak")
+	}
+}
+
+func assertCovered(t *testing.T, name string, properties map[string]json.RawMessage, v interface{}) {
+	t.Helper()
+	typ := reflect.TypeOf(v)
+	for i := 0; i < typ.NumField(); i++ {
+		tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		if _, ok := properties[tag]; !ok {
+			t.Errorf("%s: field %q tidak ada di schema", name, tag)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"Task-CRUD/internal/entity"
+	"Task-CRUD/internal/events"
+	"Task-CRUD/internal/usecase"
+	appKafka "Task-CRUD/kafka"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const streamTopic = "repository-topic"
+
+// memoryStreamSource membaca ulang pesan dari slice, seperti Kafka untuk EventStream
+type memoryStreamSource struct {
+	messages []kafka.Message
+}
+
+func (s *memoryStreamSource) ReadRange(ctx context.Context, topic string, partition int, from, to int64, fn func(kafka.Message) error) error {
+	for _, m := range s.messages {
+		if m.Topic == topic && m.Partition == partition && m.Offset >= from && m.Offset <= to {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// streamMessages membuat pesan repository_created di dua partisi: p0@0, p1@0, p0@1, p1@1, p0@2
+func streamMessages(t *testing.T) []kafka.Message {
+	t.Helper()
+	positions := [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}}
+	messages := make([]kafka.Message, 0, len(positions))
+	for i, pos := range positions {
+		id := uint(i + 1)
+		event, err := events.New(context.Background(), events.RepositoryCreated, fmt.Sprintf("repository/%d", id), nil, entity.Repository{ID: id, UserID: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, contentType, err := appKafka.EncodeEvent(context.Background(), streamTopic, event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := messageOf(streamTopic, value, contentType)
+		m.Partition, m.Offset = pos[0], int64(pos[1])
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+func nextSubjects(t *testing.T, sub *usecase.StreamSubscription, n int) []string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	subjects := make([]string, 0, n)
+	for len(subjects) < n {
+		ev, err := sub.Next(ctx)
+		if err != nil {
+			t.Fatalf("Next setelah %v: %v", subjects, err)
+		}
+		if ev.ID != sub.Cursor() {
+			t.Fatalf("ID event %q berbeda dengan cursor %q", ev.ID, sub.Cursor())
+		}
+		subjects = append(subjects, ev.Event.Subject)
+	}
+	return subjects
+}
+
+func TestEventStreamResumesFromCursor(t *testing.T) {
+	messages := streamMessages(t)
+	rest := []string{"repository/3", "repository/4", "repository/5"}
+
+	tests := []struct {
+		name       string
+		bufferSize int
+		withSource bool
+		wantReset  bool
+		want       []string
+	}{
+		{name: "cursor masih di buffer", bufferSize: 10, want: rest},
+		{name: "cursor lama dibaca ulang dari Kafka", bufferSize: 2, withSource: true, want: rest},
+		{name: "cursor lama tanpa source direset", bufferSize: 2, wantReset: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var source usecase.StreamSource
+			if tt.withSource {
+				source = &memoryStreamSource{messages: messages}
+			}
+			stream := usecase.NewEventStream(source, tt.bufferSize, 100)
+			stream.Assign(streamTopic, 0, 0)
+			stream.Assign(streamTopic, 1, 0)
+
+			// Klien pertama membaca dua event lalu terputus
+			first, err := stream.Subscribe(context.Background(), usecase.StreamFilter{}, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range messages {
+				stream.Publish(m)
+			}
+			nextSubjects(t, first, 2)
+			cursor := first.Cursor()
+			first.Close()
+			if want := streamTopic + ":0:0," + streamTopic + ":1:0"; cursor != want {
+				t.Fatalf("cursor = %q, want %q", cursor, want)
+			}
+
+			// Klien tersambung lagi dengan Last-Event-ID
+			resumed, err := stream.Subscribe(context.Background(), usecase.StreamFilter{}, cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resumed.Close()
+			if resumed.Reset != tt.wantReset {
+				t.Fatalf("Reset = %v, want %v", resumed.Reset, tt.wantReset)
+			}
+			if tt.wantReset {
+				return
+			}
+			got := nextSubjects(t, resumed, len(tt.want))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("event = %v, want %v", got, tt.want)
+			}
+			if want := streamTopic + ":0:2," + streamTopic + ":1:1"; resumed.Cursor() != want {
+				t.Errorf("cursor akhir = %q, want %q", resumed.Cursor(), want)
+			}
+		})
+	}
+}
+
+func TestEventStreamRejectsInvalidCursor(t *testing.T) {
+	stream := usecase.NewEventStream(nil, 10, 100)
+	for _, cursor := range []string{"acak", streamTopic + ":0", ":0:1", streamTopic + ":x:1", streamTopic + ":-1:1", streamTopic + ":0:-2", streamTopic + ":0:1,"} {
+		t.Run(cursor, func(t *testing.T) {
+			if _, err := stream.Subscribe(context.Background(), usecase.StreamFilter{}, cursor); !errors.Is(err, usecase.ErrInvalidStreamCursor) {
+				t.Errorf("err = %v, want ErrInvalidStreamCursor", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	appKafka "Task-CRUD/kafka"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestOffsetTrackerCommitsContiguousPrefix(t *testing.T) {
+	msg := func(partition int, offset int64) kafka.Message {
+		return kafka.Message{Topic: "repository-topic", Partition: partition, Offset: offset}
+	}
+	type step struct {
+		track []kafka.Message
+		done  []kafka.Message
+		want  []kafka.Message // hasil Ready setelah langkah ini
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "selesai berurutan",
+			steps: []step{
+				{track: []kafka.Message{msg(0, 10), msg(0, 11)}, done: []kafka.Message{msg(0, 10)}, want: []kafka.Message{msg(0, 10)}},
+				{done: []kafka.Message{msg(0, 11)}, want: []kafka.Message{msg(0, 11)}},
+			},
+		},
+		{
+			name: "selesai tidak berurutan menunggu pendahulunya",
+			steps: []step{
+				{track: []kafka.Message{msg(0, 10), msg(0, 11), msg(0, 12)}, done: []kafka.Message{msg(0, 12), msg(0, 11)}, want: nil},
+				{done: []kafka.Message{msg(0, 10)}, want: []kafka.Message{msg(0, 12)}},
+				{want: nil}, // tidak ada perubahan sejak Ready sebelumnya
+			},
+		},
+		{
+			name: "partisi dihitung terpisah",
+			steps: []step{
+				{
+					track: []kafka.Message{msg(0, 5), msg(1, 7), msg(0, 6)},
+					done:  []kafka.Message{msg(1, 7), msg(0, 6)},
+					want:  []kafka.Message{msg(1, 7)},
+				},
+				{done: []kafka.Message{msg(0, 5)}, want: []kafka.Message{msg(0, 6)}},
+			},
+		},
+		{
+			name: "offset mundur setelah rebalance membuang catatan lama",
+			steps: []step{
+				{track: []kafka.Message{msg(0, 20), msg(0, 21)}, done: []kafka.Message{msg(0, 21)}, want: nil},
+				{track: []kafka.Message{msg(0, 20)}, done: []kafka.Message{msg(0, 20)}, want: []kafka.Message{msg(0, 20)}},
+			},
+		},
+		{
+			name: "pesan yang tidak dilacak diabaikan",
+			steps: []step{
+				{done: []kafka.Message{msg(0, 1)}, want: nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := appKafka.NewOffsetTracker()
+			for i, s := range tt.steps {
+				for _, m := range s.track {
+					tracker.Track(m)
+				}
+				for _, m := range s.done {
+					tracker.Done(m)
+				}
+				got := tracker.Ready()
+				sort.Slice(got, func(a, b int) bool { return got[a].Partition < got[b].Partition })
+				if !reflect.DeepEqual(got, s.want) {
+					t.Errorf("langkah %d: Ready = %v, want %v", i+1, got, s.want)
+				}
+			}
+		})
+	}
+}
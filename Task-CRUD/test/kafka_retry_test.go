@@ -0,0 +1,91 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"Task-CRUD/internal/events"
+	appKafka "Task-CRUD/kafka"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func headerValues(m kafka.Message, key string) []string {
+	var values []string
+	for _, h := range m.Headers {
+		if h.Key == key {
+			values = append(values, string(h.Value))
+		}
+	}
+	return values
+}
+
+func TestFailedMessageCarriesHeadersAcrossRetryTiers(t *testing.T) {
+	ctx := context.Background()
+	event := newTestRepositoryEvent(t)
+	value, contentType, err := appKafka.EncodeEvent(ctx, "repository-topic", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	contentTypeKey, _ := events.Header()
+
+	// Pesan asal di repository-topic partisi 2 offset 41, lalu dua tier retry dan DLQ
+	msg := kafka.Message{Topic: "repository-topic", Partition: 2, Offset: 41, Key: []byte("7"), Value: value,
+		Headers: append(appKafka.EventHeaders(event.Type, contentType), kafka.Header{Key: "outbox-id", Value: []byte("99")})}
+	hops := []struct {
+		target       string
+		delay        time.Duration
+		cause        string
+		wantAttempts string
+	}{
+		{target: appKafka.RetryTopic("repository-topic", 10*time.Second), delay: 10 * time.Second, cause: "db timeout", wantAttempts: "1"},
+		{target: appKafka.RetryTopic("repository-topic", time.Minute), delay: time.Minute, cause: "db timeout lagi", wantAttempts: "2"},
+		{target: appKafka.DeadLetterTopic("repository-topic"), cause: "menyerah", wantAttempts: "3"},
+	}
+
+	for i, hop := range hops {
+		msg = appKafka.FailedMessage(msg, hop.target, errors.New(hop.cause), hop.delay, now)
+		// Posisi pesan di topic retry berbeda dari posisi asal
+		msg.Partition, msg.Offset = 0, int64(100+i)
+
+		if msg.Topic != hop.target || string(msg.Key) != "7" || string(msg.Value) != string(value) {
+			t.Fatalf("tier %d: topic %q key %q, want %q dan key/value tidak berubah", i+1, msg.Topic, msg.Key, hop.target)
+		}
+		want := map[string][]string{
+			appKafka.HeaderAttempts:          {hop.wantAttempts},
+			appKafka.HeaderError:             {hop.cause},
+			appKafka.HeaderOriginalTopic:     {"repository-topic"},
+			appKafka.HeaderOriginalPartition: {"2"},
+			appKafka.HeaderOriginalOffset:    {"41"},
+			appKafka.HeaderFailedAt:          {now.Format(time.RFC3339)},
+			"outbox-id":                      {"99"},
+			contentTypeKey:                   {contentType},
+		}
+		if hop.delay > 0 {
+			want[appKafka.HeaderRetryAt] = []string{strconv.FormatInt(now.Add(hop.delay).UnixMilli(), 10)}
+		}
+		for key, values := range want {
+			if got := headerValues(msg, key); len(got) != len(values) || (len(got) > 0 && got[0] != values[0]) {
+				t.Errorf("tier %d: header %s = %v, want %v", i+1, key, got, values)
+			}
+		}
+		if hop.delay == 0 && len(headerValues(msg, appKafka.HeaderRetryAt)) != 0 {
+			t.Errorf("tier %d: pesan DLQ tidak boleh membawa %s", i+1, appKafka.HeaderRetryAt)
+		}
+	}
+
+	dl := appKafka.DeadLetterOf(ctx, msg)
+	if dl.Attempts != 3 || dl.Error != "menyerah" || !dl.FailedAt.Equal(now) {
+		t.Errorf("dead letter = %+v, want 3 percobaan dengan error terakhir", dl)
+	}
+	if dl.OriginalTopic != "repository-topic" || dl.OriginalPartition != 2 || dl.OriginalOffset != 41 {
+		t.Errorf("posisi asal = %s/%d@%d, want repository-topic/2@41", dl.OriginalTopic, dl.OriginalPartition, dl.OriginalOffset)
+	}
+	if dl.EventID != event.ID || dl.EventType != event.Type {
+		t.Errorf("event = %s %s, want %s %s", dl.EventID, dl.EventType, event.ID, event.Type)
+	}
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpDelivery "Task-CRUD/delivery/http"
+	"Task-CRUD/internal/entity"
+	interfaces "Task-CRUD/internal/interfaces"
+	"Task-CRUD/internal/usecase"
+)
+
+// memoryWebhookRepository hanya mengimplementasikan bagian yang dipakai dispatcher
+type memoryWebhookRepository struct {
+	interfaces.WebhookRepositoryInterface
+	webhook    entity.Webhook
+	deliveries []entity.WebhookDelivery
+	completed  []entity.WebhookDelivery
+}
+
+func (r *memoryWebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, map[uint]*entity.Webhook, error) {
+	claimed := r.deliveries
+	r.deliveries = nil
+	webhook := r.webhook
+	return claimed, map[uint]*entity.Webhook{webhook.ID: &webhook}, nil
+}
+
+func (r *memoryWebhookRepository) CompleteDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery, fn func([]entity.WebhookDelivery, map[uint]*entity.Webhook) error) error {
+	r.completed = append(r.completed, deliveries...)
+	webhook := r.webhook
+	if err := fn(deliveries, map[uint]*entity.Webhook{webhook.ID: &webhook}); err != nil {
+		return err
+	}
+	r.webhook = webhook
+	return nil
+}
+
+func TestWebhookDispatcherBlocksPrivateTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		allowPrivate bool
+		wantStatus   string
+		wantError    string
+	}{
+		{name: "loopback ditolak", wantStatus: entity.DeliveryPending, wantError: usecase.ErrWebhookTargetBlocked.Error()},
+		{name: "loopback diizinkan untuk pengembangan", allowPrivate: true, wantStatus: entity.DeliverySucceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryWebhookRepository{
+				webhook:    entity.Webhook{ID: 1, URL: server.URL, Secret: "rahasia", Active: true},
+				deliveries: []entity.WebhookDelivery{{ID: 7, WebhookID: 1, EventType: "repository.created", Payload: []byte(`{}`), Status: entity.DeliveryPending}},
+			}
+			d := &usecase.WebhookDispatcher{Webhooks: repo, Timeout: 2 * time.Second, AllowPrivateTargets: tt.allowPrivate}
+
+			if n, err := d.RunOnce(context.Background()); err != nil || n != 1 {
+				t.Fatalf("RunOnce = %d, %v; want 1, nil", n, err)
+			}
+			if len(repo.completed) != 1 {
+				t.Fatalf("completed = %d, want 1", len(repo.completed))
+			}
+			got := repo.completed[0]
+			if got.Status != tt.wantStatus || got.Attempts != 1 {
+				t.Errorf("status %q attempts %d, want %q dan 1", got.Status, got.Attempts, tt.wantStatus)
+			}
+			if !strings.Contains(got.Error, tt.wantError) {
+				t.Errorf("error = %q, want mengandung %q", got.Error, tt.wantError)
+			}
+			if tt.wantError != "" && repo.webhook.Failures != 1 {
+				t.Errorf("failures = %d, want 1", repo.webhook.Failures)
+			}
+		})
+	}
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   int
+	}{
+		{name: "token benar", token: "s3cret", header: "Bearer s3cret", want: http.StatusOK},
+		{name: "token salah", token: "s3cret", header: "Bearer salah", want: http.StatusUnauthorized},
+		{name: "tanpa header", token: "s3cret", want: http.StatusUnauthorized},
+		{name: "bukan bearer", token: "s3cret", header: "Basic s3cret", want: http.StatusUnauthorized},
+		{name: "ADMIN_TOKEN kosong", header: "Bearer ", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/webhooks", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			httpDelivery.AdminAuthMiddleware(tt.token)(ok).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}